@@ -1,14 +1,19 @@
 package main
 
 import (
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/pslijkhuis/kfzf/internal/config"
+	"github.com/pslijkhuis/kfzf/internal/k8s"
 )
 
 // CompletionContext represents the parsed state of a kubectl command line
 type CompletionContext struct {
 	Action        string
 	ResourceType  string
+	ResourceTypes []string // ResourceType split on commas and normalized ("po,svc" -> pods, services)
 	ResourceName  string
 	Namespace     string
 	Context       string
@@ -21,10 +26,16 @@ type CompletionContext struct {
 // ParseCommandLine parses a kubectl command line and returns the completion context
 // This mimics the ZSH completion logic in main.go
 func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
+	return ParseCommandLineFor(cmdline, cursorAtEnd, config.CompletionConfig{}.KubectlCommands())
+}
+
+// ParseCommandLineFor parses a command line like ParseCommandLine, accepting any of
+// commands as the kubectl command (see config.CompletionConfig.KubectlCommands)
+func ParseCommandLineFor(cmdline string, cursorAtEnd bool, commands []string) CompletionContext {
 	words := strings.Fields(cmdline)
 	ctx := CompletionContext{}
 
-	if len(words) == 0 || (words[0] != "kubectl" && words[0] != "k") {
+	if len(words) == 0 || !slices.Contains(commands, words[0]) {
 		return ctx
 	}
 
@@ -41,6 +52,8 @@ func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
 		"-l":              "label",
 		"--selector":      "label",
 		"--field-selector": "field_selector",
+		"-o":              "output",
+		"--output":        "output",
 	}
 
 	// Boolean flags
@@ -55,14 +68,12 @@ func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
 	}
 
 	// Known actions
-	knownActions := map[string]bool{
-		"get": true, "describe": true, "delete": true, "edit": true,
-		"logs": true, "exec": true, "attach": true, "port-forward": true,
-		"apply": true, "create": true, "scale": true, "rollout": true,
-		"label": true, "annotate": true, "top": true, "events": true,
+	knownActions := make(map[string]bool, len(kubectlActions))
+	for _, action := range kubectlActions {
+		knownActions[action] = true
 	}
 
-	i := 1 // Skip "kubectl" or "k"
+	i := 1 // Skip "kubectl" or its alias
 	for i < len(words) {
 		word := words[i]
 		var nextWord string
@@ -195,8 +206,19 @@ func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
 			ctx.CompleteType = "label"
 		case "--field-selector":
 			ctx.CompleteType = "field_selector"
+		case "-o", "--output", "--dry-run", "--sort-by", "--for", "--tail", "--since", "--timeout":
+			ctx.CompleteType = "flag_value"
 		}
 	} else {
+		// Cursor in middle of a flag name or --flag=value
+		if strings.HasPrefix(lastWord, "-") && !strings.Contains(lastWord, "=") && ctx.Action != "" {
+			ctx.CompleteType = "flag"
+			ctx.CompleteQuery = lastWord
+		} else if strings.HasPrefix(lastWord, "--") && strings.Contains(lastWord, "=") {
+			ctx.CompleteType = "flag_value"
+			ctx.CompleteQuery = lastWord[strings.Index(lastWord, "=")+1:]
+		}
+
 		// Cursor in middle of word
 		switch secondLast {
 		case "-n", "--namespace":
@@ -214,6 +236,9 @@ func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
 		case "--field-selector":
 			ctx.CompleteType = "field_selector"
 			ctx.CompleteQuery = lastWord
+		case "-o", "--output", "--dry-run", "--sort-by", "--for", "--tail", "--since", "--timeout":
+			ctx.CompleteType = "flag_value"
+			ctx.CompleteQuery = lastWord
 		}
 	}
 
@@ -222,6 +247,13 @@ func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
 		ctx.ResourceType = "pods"
 	}
 
+	// A comma-separated type list ("get po,svc") completes each type
+	for _, rt := range strings.Split(ctx.ResourceType, ",") {
+		if rt = k8s.NormalizeResourceName(rt); rt != "" && !slices.Contains(ctx.ResourceTypes, rt) {
+			ctx.ResourceTypes = append(ctx.ResourceTypes, rt)
+		}
+	}
+
 	// If not completing a flag value, determine based on position
 	if ctx.CompleteType == "" {
 		if ctx.Action == "" {
@@ -229,7 +261,8 @@ func ParseCommandLine(cmdline string, cursorAtEnd bool) CompletionContext {
 		} else if ctx.ResourceType == "" {
 			ctx.CompleteType = "resource_type"
 			if completingPartial && !strings.HasPrefix(lastWord, "-") {
-				ctx.CompleteQuery = lastWord
+				// In a type list ("po,sv") only the type after the last comma is completed
+				ctx.CompleteQuery = lastWord[strings.LastIndex(lastWord, ",")+1:]
 			}
 		} else if ctx.ResourceName != "" && implicitPods[ctx.Action] {
 			if ctx.Action == "port-forward" {
@@ -928,6 +961,70 @@ func TestCompletion_KAlias(t *testing.T) {
 	}
 }
 
+func TestCompletion_CustomAlias(t *testing.T) {
+	t.Setenv("KFZF_KUBECTL_ALIASES", "kubecolor")
+	commands := config.CompletionConfig{Aliases: []string{"kc", "bad alias"}}.KubectlCommands()
+	if want := []string{"kubectl", "k", "kc", "kubecolor"}; !slices.Equal(commands, want) {
+		t.Fatalf("KubectlCommands() = %v, want %v", commands, want)
+	}
+
+	tests := []struct {
+		name     string
+		cmdline  string
+		wantType string
+	}{
+		{"kc get <tab>", "kc get ", "resource_type"},
+		{"kubecolor get pods <tab>", "kubecolor get pods ", "resource"},
+		{"k logs <tab>", "k logs ", "resource"},
+		{"kx get <tab>", "kx get ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ParseCommandLineFor(tt.cmdline, true, commands)
+			if ctx.CompleteType != tt.wantType {
+				t.Errorf("CompleteType = %q, want %q", ctx.CompleteType, tt.wantType)
+			}
+		})
+	}
+
+	// Without the alias configured kc isn't completed
+	if ctx := ParseCommandLine("kc get ", true); ctx.CompleteType != "" {
+		t.Errorf("CompleteType = %q without alias, want none", ctx.CompleteType)
+	}
+}
+
+func TestCompletion_ResourceTypeList(t *testing.T) {
+	tests := []struct {
+		name      string
+		cmdline   string
+		wantType  string
+		wantTypes []string
+		wantQuery string
+	}{
+		{"get po,svc <tab>", "kubectl get po,svc ", "resource", []string{"pods", "services"}, ""},
+		{"get po,svc ngi", "kubectl get po,svc ngi", "resource", []string{"pods", "services"}, "ngi"},
+		{"get deploy,po,pods <tab>", "kubectl get deploy,po,pods ", "resource", []string{"deployments", "pods"}, ""},
+		{"get po,sv", "kubectl get po,sv", "resource_type", nil, "sv"},
+		{"get po,", "kubectl get po,", "resource_type", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ParseCommandLine(tt.cmdline, true)
+			if ctx.CompleteType != tt.wantType {
+				t.Errorf("CompleteType = %q, want %q", ctx.CompleteType, tt.wantType)
+			}
+			if !slices.Equal(ctx.ResourceTypes, tt.wantTypes) {
+				t.Errorf("ResourceTypes = %v, want %v", ctx.ResourceTypes, tt.wantTypes)
+			}
+			if ctx.CompleteQuery != tt.wantQuery {
+				t.Errorf("CompleteQuery = %q, want %q", ctx.CompleteQuery, tt.wantQuery)
+			}
+		})
+	}
+}
+
 // Tests for events completion (special namespace case)
 func TestCompletion_Events(t *testing.T) {
 	tests := []struct {
@@ -965,6 +1062,120 @@ func TestCompletion_Events(t *testing.T) {
 	}
 }
 
+// Tests for flag name and flag value completion
+func TestCompletion_Flags(t *testing.T) {
+	tests := []struct {
+		name        string
+		cmdline     string
+		cursorAtEnd bool
+		wantType    string
+		wantQuery   string
+	}{
+		{
+			name:        "kubectl get pods -<tab>",
+			cmdline:     "kubectl get pods -",
+			cursorAtEnd: true,
+			wantType:    "flag",
+			wantQuery:   "-",
+		},
+		{
+			name:        "kubectl logs nginx --ta<tab>",
+			cmdline:     "kubectl logs nginx --ta",
+			cursorAtEnd: true,
+			wantType:    "flag",
+			wantQuery:   "--ta",
+		},
+		{
+			name:        "kubectl get pods -o <tab>",
+			cmdline:     "kubectl get pods -o ",
+			cursorAtEnd: true,
+			wantType:    "flag_value",
+			wantQuery:   "",
+		},
+		{
+			name:        "kubectl get pods -o ya<tab>",
+			cmdline:     "kubectl get pods -o ya",
+			cursorAtEnd: true,
+			wantType:    "flag_value",
+			wantQuery:   "ya",
+		},
+		{
+			name:        "kubectl get pods --output=js<tab>",
+			cmdline:     "kubectl get pods --output=js",
+			cursorAtEnd: true,
+			wantType:    "flag_value",
+			wantQuery:   "js",
+		},
+		{
+			name:        "kubectl get pods -o yaml <tab>",
+			cmdline:     "kubectl get pods -o yaml ",
+			cursorAtEnd: true,
+			wantType:    "resource",
+			wantQuery:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ParseCommandLine(tt.cmdline, tt.cursorAtEnd)
+			if ctx.CompleteType != tt.wantType {
+				t.Errorf("CompleteType = %q, want %q", ctx.CompleteType, tt.wantType)
+			}
+			if ctx.CompleteQuery != tt.wantQuery {
+				t.Errorf("CompleteQuery = %q, want %q", ctx.CompleteQuery, tt.wantQuery)
+			}
+		})
+	}
+}
+
+// Tests for the kubectl flag table behind `kfzf flags`
+func TestFlagsForAction(t *testing.T) {
+	names := func(action string) map[string]bool {
+		m := make(map[string]bool)
+		for _, flag := range flagsForAction(action) {
+			m[flag.Name] = true
+		}
+		return m
+	}
+
+	get := names("get")
+	for _, name := range []string{"--namespace", "--context", "--output", "--selector", "--watch", "--all-namespaces"} {
+		if !get[name] {
+			t.Errorf("get should offer %s", name)
+		}
+	}
+	if get["--follow"] {
+		t.Error("get should not offer --follow")
+	}
+
+	logs := names("logs")
+	if !logs["--follow"] || !logs["--container"] {
+		t.Error("logs should offer --follow and --container")
+	}
+	if logs["--filename"] || logs["--output"] {
+		t.Error("logs should not offer --filename or --output")
+	}
+
+	// Flags without an action list apply everywhere
+	if none := names(""); len(none) != 2 || !none["--namespace"] || !none["--context"] {
+		t.Errorf("flags without a verb = %v, want --namespace and --context", none)
+	}
+
+	for _, flag := range []string{"-o", "--output"} {
+		values := flagValues("get", flag)
+		if len(values) < 3 || values[0] != "yaml" || values[1] != "json" || values[2] != "wide" {
+			t.Errorf("flagValues(get, %s) = %v, want yaml, json, wide first", flag, values)
+		}
+	}
+	if values := flagValues("logs", "-o"); values != nil {
+		t.Errorf("flagValues(logs, -o) = %v, want none", values)
+	}
+	// -f means --follow for logs, which takes no value
+	if values := flagValues("logs", "-f"); values != nil {
+		t.Errorf("flagValues(logs, -f) = %v, want none", values)
+	}
+}
+
 // Edge case tests
 func TestCompletion_EdgeCases(t *testing.T) {
 	tests := []struct {
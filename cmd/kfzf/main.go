@@ -4,28 +4,52 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pslijkhuis/kfzf/internal/client"
 	"github.com/pslijkhuis/kfzf/internal/config"
+	"github.com/pslijkhuis/kfzf/internal/k8s"
 	"github.com/pslijkhuis/kfzf/internal/server"
 	"github.com/spf13/cobra"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
-	version = "dev"
-	cfgFile string
-	cfg     *config.Config
+	version  = "dev"
+	cfgFile  string
+	instance string
+	cfg      *config.Config
 
 	//go:embed completion.zsh
 	zshCompletionScript string
 )
 
+// kubectlActions are the kubectl verbs the completion understands.
+// Shared by the verbs command and the command line parser.
+var kubectlActions = []string{
+	"get", "describe", "delete", "edit", "apply", "create",
+	"logs", "exec", "attach", "cp",
+	"port-forward", "scale", "rollout",
+	"label", "annotate", "patch", "top",
+	"run", "expose", "set", "explain",
+	"config", "cluster-info", "api-resources", "api-versions",
+	"diff", "wait", "auth", "debug", "events",
+	"cnpg",
+}
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "kfzf",
@@ -38,6 +62,7 @@ providing instant completions without repeatedly hitting the API server.`,
 	}
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ~/.config/kfzf/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&instance, "instance", os.Getenv("KFZF_INSTANCE"), "Named server instance with its own socket (default: $KFZF_INSTANCE)")
 
 	// Add commands
 	rootCmd.AddCommand(serverCmd())
@@ -46,13 +71,23 @@ providing instant completions without repeatedly hitting the API server.`,
 	rootCmd.AddCommand(portsCmd())
 	rootCmd.AddCommand(labelsCmd())
 	rootCmd.AddCommand(fieldValuesCmd())
+	rootCmd.AddCommand(apiResourcesCmd())
+	rootCmd.AddCommand(verbsCmd())
+	rootCmd.AddCommand(flagsCmd())
 	rootCmd.AddCommand(statusCmd())
 	rootCmd.AddCommand(refreshCmd())
 	rootCmd.AddCommand(watchCmd())
+	rootCmd.AddCommand(streamCmd())
 	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(zshCompletionCmd())
 	rootCmd.AddCommand(systemdCmd())
+	rootCmd.AddCommand(launchdCmd())
 	rootCmd.AddCommand(recentCmd())
+	rootCmd.AddCommand(debugCmd())
+	rootCmd.AddCommand(logLevelCmd())
+	rootCmd.AddCommand(contextsCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(doctorCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -76,12 +111,20 @@ func loadConfig() *config.Config {
 		cfg = config.DefaultConfig()
 	}
 
+	if err := cfg.Server.SetInstance(instance); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	return cfg
 }
 
 func serverCmd() *cobra.Command {
 	var foreground bool
 	var logLevel string
+	var logFormat string
+	var as string
+	var asGroups []string
 
 	cmd := &cobra.Command{
 		Use:   "server",
@@ -90,33 +133,49 @@ func serverCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := loadConfig()
 
-			// Setup logger
-			var level slog.Level
-			switch logLevel {
-			case "debug":
-				level = slog.LevelDebug
-			case "info":
-				level = slog.LevelInfo
-			case "warn":
-				level = slog.LevelWarn
-			case "error":
-				level = slog.LevelError
-			default:
-				level = slog.LevelInfo
+			// Command-line impersonation overrides the config
+			if as != "" || len(asGroups) > 0 {
+				cfg.Server.Impersonate = config.ImpersonateConfig{User: as, Groups: asGroups}
 			}
 
-			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-				Level: level,
-			}))
+			// Setup logger. The level is a LevelVar so `kfzf log-level` can change it at runtime.
+			level, _ := server.ParseLogLevel(logLevel)
+			levelVar := new(slog.LevelVar)
+			levelVar.Set(level)
+
+			// Log to stderr, or to a size-rotated file when configured
+			var logOutput io.Writer = os.Stderr
+			if cfg.Server.LogFile != "" {
+				logFile := &lumberjack.Logger{
+					Filename:   cfg.Server.LogFile,
+					MaxSize:    cfg.Server.LogMaxSize,
+					MaxBackups: cfg.Server.LogBackups(),
+				}
+				defer logFile.Close()
+				logOutput = logFile
+			}
 
-			// Check if server is already running
+			handlerOptions := &slog.HandlerOptions{Level: levelVar}
+			var handler slog.Handler
+			switch logFormat {
+			case "text":
+				handler = slog.NewTextHandler(logOutput, handlerOptions)
+			case "json":
+				handler = slog.NewJSONHandler(logOutput, handlerOptions)
+			default:
+				return fmt.Errorf("invalid log format %q (expected text or json)", logFormat)
+			}
+			logger := slog.New(handler)
+
+			// Check if server is already running. Under socket activation the socket
+			// is owned by systemd and always accepts, so skip the check.
 			c := client.NewClient(cfg)
-			if c.IsServerRunning() {
+			if !server.IsSocketActivated() && c.IsServerRunning() {
 				return fmt.Errorf("server is already running")
 			}
 
 			// Create and start server
-			srv, err := server.NewServer(cfg, logger)
+			srv, err := server.NewServer(cfg, logger, levelVar)
 			if err != nil {
 				return fmt.Errorf("failed to create server: %w", err)
 			}
@@ -144,6 +203,9 @@ func serverCmd() *cobra.Command {
 
 	cmd.Flags().BoolVarP(&foreground, "foreground", "f", false, "Run in foreground")
 	cmd.Flags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	cmd.Flags().StringVar(&logFormat, "log-format", "text", "Log format (text, json)")
+	cmd.Flags().StringVar(&as, "as", "", "Username to impersonate for API requests")
+	cmd.Flags().StringSliceVar(&asGroups, "as-group", nil, "Group to impersonate for API requests (repeatable)")
 
 	return cmd
 }
@@ -152,17 +214,40 @@ func completeCmd() *cobra.Command {
 	var ctx string
 	var namespace string
 	var useFzf bool
+	var limit int
+	var namesOnly bool
+	var dedupe bool
+	var tmpl string
+	var format string
+	var wide bool
+	var noWatch bool
+	var query string
+	var serverColumns bool
+	var color bool
 
 	cmd := &cobra.Command{
-		Use:   "complete <resource-type>",
-		Short: "Get completions for a resource type",
+		Use:   "complete <resource-type>[,<resource-type>...]",
+		Short: "Get completions for one or more resource types",
 		Long: `Get completions for a Kubernetes resource type.
 
 Examples:
   kfzf complete pods
   kfzf complete pods -n kube-system
-  kfzf complete deployments --fzf`,
-		Args: cobra.ExactArgs(1),
+  kfzf complete deployments --fzf
+  kfzf complete pods,services
+  kfzf complete pods --limit 50
+  kfzf complete pods --names-only
+  kfzf complete configmaps --dedupe
+  kfzf complete pods --wide
+  kfzf complete secrets -n prod --no-watch
+  kfzf complete certificates --server-columns
+  kfzf complete pods --query ngx --limit 20
+  kfzf complete pods --template '{{.Name}}\t{{index .Labels "app"}}\t{{.Columns.STATUS}}'
+  kfzf complete deployments --format csv
+
+A --template is executed per resource with .Name, .Namespace, .Labels, .Annotations,
+.Columns (configured column values by column name) and .Object (the cached object).`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := loadConfig()
 			c := client.NewClient(cfg)
@@ -171,10 +256,47 @@ Examples:
 				return fmt.Errorf("server is not running. Start it with: kfzf server")
 			}
 
-			resourceType := args[0]
+			// Several types may be given comma-separated or as separate arguments
+			resourceType := strings.Join(args, ",")
+
+			if serverColumns {
+				if strings.Contains(resourceType, ",") {
+					return fmt.Errorf("--server-columns supports a single resource type")
+				}
+				output, err := c.CompleteServerColumns(ctx, namespace, resourceType, limit, wide)
+				if err != nil {
+					return err
+				}
+				fmt.Print(output)
+				return nil
+			}
+
+			if tmpl != "" || format != "" {
+				if strings.Contains(resourceType, ",") {
+					return fmt.Errorf("--template and --format support a single resource type")
+				}
+				var output string
+				var err error
+				if tmpl != "" {
+					output, err = c.CompleteTemplate(ctx, namespace, resourceType, tmpl, limit, wide, noWatch)
+				} else {
+					output, err = c.CompleteFormat(ctx, namespace, resourceType, format, limit, wide, noWatch)
+				}
+				if errors.Is(err, client.ErrSyncing) {
+					exitSyncing()
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Print(output)
+				return nil
+			}
 
 			if useFzf {
-				result, err := c.CompleteWithFzf(ctx, namespace, resourceType, nil)
+				result, err := c.CompleteWithFzf(ctx, namespace, resourceType, limit, dedupe, wide, noWatch, nil)
+				if errors.Is(err, client.ErrSyncing) {
+					exitSyncing()
+				}
 				if err != nil {
 					return err
 				}
@@ -184,7 +306,10 @@ Examples:
 				return nil
 			}
 
-			output, err := c.Complete(ctx, namespace, resourceType)
+			output, err := c.Complete(ctx, namespace, resourceType, query, limit, namesOnly, dedupe, wide, noWatch, color)
+			if errors.Is(err, client.ErrSyncing) {
+				exitSyncing()
+			}
 			if err != nil {
 				return err
 			}
@@ -197,10 +322,44 @@ Examples:
 	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context (default: current)")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace (default: from context)")
 	cmd.Flags().BoolVar(&useFzf, "fzf", false, "Pipe output through fzf")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum results per resource type (0 = unlimited)")
+	cmd.Flags().BoolVar(&namesOnly, "names-only", false, "Print only resource names, without columns")
+	cmd.Flags().BoolVar(&dedupe, "dedupe", false, "Collapse resources with the same name across namespaces into one row")
+	cmd.Flags().BoolVar(&wide, "wide", false, "Use the wide column set where configured (like kubectl get -o wide)")
+	cmd.Flags().BoolVar(&color, "color", false, "Color the columns for fzf --ansi, if enabled with server.color")
+	cmd.Flags().BoolVar(&noWatch, "no-watch", false, "List the resource type once instead of watching and caching it")
+	cmd.Flags().BoolVar(&serverColumns, "server-columns", false, "Use the columns rendered by the API server, exactly as kubectl get (lists on every call)")
+	cmd.Flags().StringVarP(&query, "query", "q", "", "Only return names matching this fzf-style subsequence, best first (default limit: 100)")
+	cmd.Flags().StringVar(&tmpl, "template", "", "Go template executed per resource instead of the configured columns")
+	cmd.Flags().StringVar(&format, "format", "", "Machine-readable output with a header row: csv or tsv (default: padded columns)")
+	// Custom output replaces the display that these flags adjust
+	cmd.MarkFlagsMutuallyExclusive("template", "format")
+	for _, flag := range []string{"fzf", "names-only", "dedupe"} {
+		cmd.MarkFlagsMutuallyExclusive("template", flag)
+		cmd.MarkFlagsMutuallyExclusive("format", flag)
+	}
+	// A query is only sent with the plain listing
+	for _, flag := range []string{"fzf", "template", "format"} {
+		cmd.MarkFlagsMutuallyExclusive("query", flag)
+	}
+	// Server-rendered columns replace the configured ones and bypass the watch cache
+	for _, flag := range []string{"fzf", "template", "format", "query", "names-only", "dedupe", "no-watch", "color"} {
+		cmd.MarkFlagsMutuallyExclusive("server-columns", flag)
+	}
 
 	return cmd
 }
 
+// exitCodeSyncing is the exit status of `kfzf complete` when the resources are still
+// syncing, so the shell integration can say so instead of showing no matches
+const exitCodeSyncing = 3
+
+// exitSyncing reports that completions aren't loaded yet and exits with exitCodeSyncing
+func exitSyncing() {
+	fmt.Fprintln(os.Stderr, "kfzf: resources are still syncing, try again in a moment")
+	os.Exit(exitCodeSyncing)
+}
+
 func containersCmd() *cobra.Command {
 	var ctx string
 	var namespace string
@@ -208,7 +367,9 @@ func containersCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "containers <pod-name>",
 		Short: "Get container names for a pod",
-		Long: `Get container names for a pod from cache.
+		Long: `Get container names for a pod from cache. With server.containerStates, each
+container's current state (running, waiting reason, or termination reason) is added
+when the pod reports it.
 
 Examples:
   kfzf containers my-pod
@@ -247,13 +408,17 @@ func portsCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "ports <resource-name>",
-		Short: "Get ports for a pod or service",
-		Long: `Get ports for a pod or service from cache.
+		Short: "Get ports for a pod, service or workload",
+		Long: `Get ports for a pod, service or workload from cache.
+
+For deployments, statefulsets, daemonsets and replicasets, the distinct
+container ports of all pods matched by the workload's selector are listed.
 
 Examples:
   kfzf ports my-pod
   kfzf ports my-pod -n kube-system
-  kfzf ports my-service -t services -n kube-system`,
+  kfzf ports my-service -t services -n kube-system
+  kfzf ports my-app -t deployments`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := loadConfig()
@@ -277,7 +442,7 @@ Examples:
 
 	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context (default: current)")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace")
-	cmd.Flags().StringVarP(&resourceType, "type", "t", "pods", "Resource type (pods or services)")
+	cmd.Flags().StringVarP(&resourceType, "type", "t", "pods", "Resource type (pods, services, or a workload such as deployments)")
 
 	return cmd
 }
@@ -285,6 +450,8 @@ Examples:
 func labelsCmd() *cobra.Command {
 	var ctx string
 	var namespace string
+	var counts bool
+	var sortByCount bool
 
 	cmd := &cobra.Command{
 		Use:   "labels <resource-type>",
@@ -294,7 +461,8 @@ func labelsCmd() *cobra.Command {
 Examples:
   kfzf labels pods
   kfzf labels pods -n kube-system
-  kfzf labels nodes`,
+  kfzf labels nodes
+  kfzf labels pods --counts --sort-count`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := loadConfig()
@@ -306,7 +474,7 @@ Examples:
 
 			resourceType := args[0]
 
-			output, err := c.Labels(ctx, namespace, resourceType)
+			output, err := c.Labels(ctx, namespace, resourceType, counts || sortByCount, sortByCount)
 			if err != nil {
 				return err
 			}
@@ -318,6 +486,8 @@ Examples:
 
 	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context (default: current)")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace")
+	cmd.Flags().BoolVar(&counts, "counts", false, "Show the number of resources carrying each label")
+	cmd.Flags().BoolVar(&sortByCount, "sort-count", false, "Sort by count, most common first (implies --counts)")
 
 	return cmd
 }
@@ -366,6 +536,183 @@ Examples:
 	return cmd
 }
 
+func apiResourcesCmd() *cobra.Command {
+	var ctx string
+	var names bool
+
+	cmd := &cobra.Command{
+		Use:   "api-resources",
+		Short: "List resource types discovered by the server",
+		Long: `List the resource types available in a context, including CRDs,
+from the server's discovery cache.
+
+Examples:
+  kfzf api-resources
+  kfzf api-resources --context prod
+  kfzf api-resources --names`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running. Start it with: kfzf server")
+			}
+
+			if names {
+				output, err := c.ResourceTypes(ctx)
+				if err != nil {
+					return err
+				}
+				fmt.Print(output)
+				return nil
+			}
+
+			resources, err := c.APIResources(ctx)
+			if err != nil {
+				return err
+			}
+
+			// Server-rendered columns are only known once complete --server-columns fetched them
+			withColumns := slices.ContainsFunc(resources, func(r server.APIResource) bool { return len(r.Columns) > 0 })
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if withColumns {
+				_, _ = fmt.Fprintln(w, "NAME\tSHORTNAMES\tNAMESPACED\tCOLUMNS")
+			} else {
+				_, _ = fmt.Fprintln(w, "NAME\tSHORTNAMES\tNAMESPACED")
+			}
+			for _, r := range resources {
+				if withColumns {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", r.Name, strings.Join(r.ShortNames, ","), r.Namespaced, strings.Join(r.Columns, ","))
+				} else {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%t\n", r.Name, strings.Join(r.ShortNames, ","), r.Namespaced)
+				}
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context (default: current)")
+	cmd.Flags().BoolVar(&names, "names", false, "Print only names with short names as hints (for shell completion)")
+
+	return cmd
+}
+
+func verbsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verbs",
+		Short: "List kubectl verbs supported by completion",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, action := range kubectlActions {
+				fmt.Println(action)
+			}
+		},
+	}
+}
+
+// kubectlFlag is a kubectl flag offered by flag completion
+type kubectlFlag struct {
+	Name        string
+	Short       string
+	Description string
+	// Values are the completions for the flag's value, if it has a fixed set
+	Values []string
+	// Actions limits the flag to these verbs (nil means every verb)
+	Actions []string
+}
+
+// kubectlFlags are the common kubectl flags the completion offers, per verb
+var kubectlFlags = []kubectlFlag{
+	{Name: "--namespace", Short: "-n", Description: "Namespace scope"},
+	{Name: "--context", Description: "Kubeconfig context"},
+	{
+		Name: "--output", Short: "-o", Description: "Output format",
+		Values:  []string{"yaml", "json", "wide", "name", "jsonpath=", "custom-columns=", "go-template="},
+		Actions: []string{"get", "create", "apply", "edit", "run", "expose", "label", "annotate", "patch", "scale", "set", "events", "wait"},
+	},
+	{Name: "--selector", Short: "-l", Description: "Label selector", Actions: []string{"get", "describe", "delete", "logs", "label", "annotate", "top", "set", "wait", "rollout"}},
+	{Name: "--field-selector", Description: "Field selector", Actions: []string{"get", "delete", "events"}},
+	{Name: "--all-namespaces", Short: "-A", Description: "All namespaces", Actions: []string{"get", "describe", "delete", "top", "events", "wait"}},
+	{Name: "--watch", Short: "-w", Description: "Watch for changes", Actions: []string{"get", "events", "rollout"}},
+	{Name: "--show-labels", Description: "Show labels as the last column", Actions: []string{"get"}},
+	{Name: "--sort-by", Description: "Sort by a JSONPath field", Values: []string{".metadata.name", ".metadata.creationTimestamp"}, Actions: []string{"get", "events"}},
+	{Name: "--filename", Short: "-f", Description: "File or directory", Actions: []string{"get", "describe", "delete", "apply", "create", "diff", "edit", "label", "annotate", "patch", "scale", "wait"}},
+	{Name: "--container", Short: "-c", Description: "Container name", Actions: []string{"logs", "exec", "attach", "cp", "debug"}},
+	{Name: "--follow", Short: "-f", Description: "Stream logs", Actions: []string{"logs"}},
+	{Name: "--previous", Short: "-p", Description: "Logs of the previous container instance", Actions: []string{"logs"}},
+	{Name: "--tail", Description: "Number of recent lines", Values: []string{"10", "100", "1000"}, Actions: []string{"logs"}},
+	{Name: "--since", Description: "Only logs newer than a duration", Values: []string{"5m", "1h", "24h"}, Actions: []string{"logs"}},
+	{Name: "--timestamps", Description: "Prefix lines with timestamps", Actions: []string{"logs"}},
+	{Name: "--stdin", Short: "-i", Description: "Pass stdin to the container", Actions: []string{"exec", "attach", "run", "debug"}},
+	{Name: "--tty", Short: "-t", Description: "Allocate a TTY", Actions: []string{"exec", "attach", "run", "debug"}},
+	{Name: "--dry-run", Description: "Only print the object", Values: []string{"none", "client", "server"}, Actions: []string{"apply", "create", "delete", "run", "expose", "label", "annotate", "patch", "scale", "set"}},
+	{Name: "--replicas", Description: "New replica count", Actions: []string{"scale"}},
+	{Name: "--grace-period", Description: "Seconds before force termination", Actions: []string{"delete"}},
+	{Name: "--force", Description: "Delete immediately", Actions: []string{"delete"}},
+	{Name: "--for", Description: "Condition to wait for", Values: []string{"condition=Ready", "condition=Available", "delete"}, Actions: []string{"wait"}},
+	{Name: "--timeout", Description: "How long to wait", Values: []string{"30s", "60s", "5m"}, Actions: []string{"wait", "delete", "rollout"}},
+}
+
+// flagsForAction returns the flags that apply to a kubectl verb, in table order
+func flagsForAction(action string) []kubectlFlag {
+	var flags []kubectlFlag
+	for _, flag := range kubectlFlags {
+		if flag.Actions == nil || slices.Contains(flag.Actions, action) {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// flagValues returns the value completions of a verb's flag, given by its long or short name
+func flagValues(action, name string) []string {
+	for _, flag := range flagsForAction(action) {
+		if flag.Name == name || (flag.Short != "" && flag.Short == name) {
+			return flag.Values
+		}
+	}
+	return nil
+}
+
+func flagsCmd() *cobra.Command {
+	var values string
+
+	cmd := &cobra.Command{
+		Use:   "flags [action]",
+		Short: "List kubectl flags for a verb (for shell completion)",
+		Long: `List the common kubectl flags that apply to a verb, one name per line
+followed by a tab and its description. With --values, list the completions
+for that flag's value instead (e.g. yaml, json and wide for -o).`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var action string
+			if len(args) > 0 {
+				action = args[0]
+			}
+
+			if values != "" {
+				for _, value := range flagValues(action, values) {
+					fmt.Println(value)
+				}
+				return
+			}
+
+			for _, flag := range flagsForAction(action) {
+				fmt.Printf("%s\t%s\n", flag.Name, flag.Description)
+				if flag.Short != "" {
+					fmt.Printf("%s\t%s\n", flag.Short, flag.Description)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&values, "values", "", "List value completions for this flag")
+
+	return cmd
+}
+
 func statusCmd() *cobra.Command {
 	var jsonOutput bool
 
@@ -396,12 +743,52 @@ func statusCmd() *cobra.Command {
 			} else {
 			fmt.Printf("Server running\n")
 			fmt.Printf("  Uptime: %s\n", status.Uptime)
+			fmt.Printf("  Current context: %s\n", status.CurrentContext)
+			fmt.Printf("  Kubeconfig: %s\n", status.KubeconfigPath)
 			fmt.Printf("  Cached resources: %d\n", status.ResourceCount)
+			if rt := status.Runtime; rt != nil {
+				fmt.Printf("  Runtime: %d goroutines, %.1f MiB heap, %d cached clients\n",
+					rt.Goroutines, float64(rt.HeapAlloc)/(1<<20), rt.CachedClients)
+			}
+			if len(status.Requests) > 0 {
+				fmt.Printf("  Requests since %s: %s\n", status.StatsSince, formatCounts(status.Requests))
+			}
+			if len(status.Latency) > 0 {
+				fmt.Printf("  Latency p50/p95: %s\n", formatLatency(status.Latency))
+			}
+			if len(status.Events) > 0 {
+				fmt.Printf("  Watch events: %s\n", formatCounts(status.Events))
+			}
+			if cache := status.Cache; cache != nil {
+				fmt.Printf("  Cache hits: discovery %s, results %s\n",
+					formatHitRate(cache.DiscoveryHits, cache.DiscoveryMisses),
+					formatHitRate(cache.ResultHits, cache.ResultMisses))
+			}
 			fmt.Printf("  Contexts:\n")
 			for ctx, stats := range status.ResourceStats {
 				fmt.Printf("    %s:\n", ctx)
+				if cluster, ok := status.Clusters[ctx]; ok {
+					if cluster.Reachable {
+						fmt.Printf("      (server %s, reachable)\n", cluster.ServerVersion)
+					} else if cluster.ServerVersion != "" {
+						fmt.Printf("      (server %s, unreachable: %s)\n", cluster.ServerVersion, cluster.Error)
+					} else {
+						fmt.Printf("      (server unreachable: %s)\n", cluster.Error)
+					}
+					printDiscoveryError(cluster)
+				}
+				if synced, ok := status.DefaultsSynced[ctx]; ok {
+					fmt.Printf("      (default resources synced: %t)\n", synced)
+				}
 				for resource, count := range stats {
-					fmt.Printf("      %s: %d\n", resource, count)
+					fmt.Printf("      %s: %d%s\n", resource, count, lastSyncSuffix(status.LastSync[ctx][resource], status.LastUpdated[ctx][resource]))
+				}
+			}
+			// Contexts without cached resources only show up when discovery failed
+			for ctx, cluster := range status.Clusters {
+				if _, ok := status.ResourceStats[ctx]; !ok && cluster.DiscoveryError != "" {
+					fmt.Printf("    %s:\n", ctx)
+					printDiscoveryError(cluster)
 				}
 			}
 			}
@@ -415,11 +802,220 @@ func statusCmd() *cobra.Command {
 	return cmd
 }
 
-func refreshCmd() *cobra.Command {
+// formatCounts formats counters from the status response as "key=n" pairs sorted by key
+func formatCounts(counts map[string]int64) string {
+	pairs := make([]string, 0, len(counts))
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", key, counts[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// formatLatency formats latency percentiles from the status response as "type=p50/p95" pairs sorted by type
+func formatLatency(latency map[string]server.LatencyInfo) string {
+	pairs := make([]string, 0, len(latency))
+	for _, key := range slices.Sorted(maps.Keys(latency)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s/%s", key, latency[key].P50, latency[key].P95))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// formatHitRate formats cache hits and misses as "hits/lookups (rate%)"
+func formatHitRate(hits, misses int64) string {
+	lookups := hits + misses
+	if lookups == 0 {
+		return "0/0"
+	}
+	return fmt.Sprintf("%d/%d (%.0f%%)", hits, lookups, 100*float64(hits)/float64(lookups))
+}
+
+// printDiscoveryError prints the last failed discovery of a context, if any
+func printDiscoveryError(cluster server.ClusterInfo) {
+	if cluster.DiscoveryError == "" {
+		return
+	}
+	failed, err := time.Parse(time.RFC3339, cluster.DiscoveryFailedAt)
+	if err != nil {
+		fmt.Printf("      (discovery failed: %s)\n", cluster.DiscoveryError)
+		return
+	}
+	fmt.Printf("      (discovery failed %s ago: %s)\n", time.Since(failed).Round(time.Second), cluster.DiscoveryError)
+}
+
+// lastSyncSuffix formats the last sync and last update timestamps from the status
+// response for display
+func lastSyncSuffix(syncTimestamp, updateTimestamp string) string {
+	synced, err := time.Parse(time.RFC3339, syncTimestamp)
+	if err != nil {
+		return ""
+	}
+	updated, err := time.Parse(time.RFC3339, updateTimestamp)
+	if err != nil {
+		return fmt.Sprintf(" (synced %s ago)", time.Since(synced).Round(time.Second))
+	}
+	return fmt.Sprintf(" (synced %s ago, updated %s ago)",
+		time.Since(synced).Round(time.Second), time.Since(updated).Round(time.Second))
+}
+
+func doctorCmd() *cobra.Command {
 	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the setup and print a pass/fail checklist",
+		Long: `Check that the pieces kfzf depends on are in place: the kubeconfig, the current
+context's API server, fzf, the server and its socket, and the default watches.
+
+Exits with status 1 if any check fails.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := loadConfig()
+			checks := &doctorChecks{out: os.Stdout}
+			check := checks.check
+
+			var clientManager *k8s.ClientManager
+			check("kubeconfig readable", func() (string, error) {
+				var err error
+				clientManager, err = k8s.NewClientManager(server.ClientOptions(cfg))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%s, %d contexts", clientManager.KubeconfigPath(), len(clientManager.ListContexts())), nil
+			})
+
+			check("current context reachable", func() (string, error) {
+				if clientManager == nil {
+					return "", fmt.Errorf("skipped, the kubeconfig could not be loaded")
+				}
+				contextName := clientManager.GetCurrentContext()
+				if contextName == "" {
+					return "", fmt.Errorf("no current context set")
+				}
+				kubeClient, err := clientManager.GetClient(contextName)
+				if err != nil {
+					return "", err
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), doctorProbeTimeout)
+				defer cancel()
+				version, err := k8s.ServerVersion(ctx, kubeClient)
+				if err != nil {
+					return "", fmt.Errorf("%s: %w", contextName, err)
+				}
+				return fmt.Sprintf("%s, server %s", contextName, version), nil
+			})
+
+			check("fzf installed", func() (string, error) {
+				return exec.LookPath("fzf")
+			})
+
+			c := client.NewClient(cfg)
+			running := c.IsServerRunning()
+			check("server running", func() (string, error) {
+				if !running {
+					return "", fmt.Errorf("not running, start it with: kfzf server")
+				}
+				if cfg.Server.ListenAddr != "" {
+					return cfg.Server.ListenAddr, nil
+				}
+				return cfg.Server.SocketPath, nil
+			})
+
+			check("socket permissions", func() (string, error) {
+				if cfg.Server.ListenAddr != "" {
+					return "not used, connecting over TCP", nil
+				}
+				return checkSocket(cfg.Server)
+			})
+
+			check("default watches synced", func() (string, error) {
+				if !running {
+					return "", fmt.Errorf("skipped, the server is not running")
+				}
+				status, err := c.Status()
+				if err != nil {
+					return "", err
+				}
+				synced, ok := status.DefaultsSynced[status.CurrentContext]
+				switch {
+				case !ok:
+					return "", fmt.Errorf("%s has no watches yet, run a completion to start them", status.CurrentContext)
+				case !synced:
+					return "", fmt.Errorf("%s is still syncing", status.CurrentContext)
+				}
+				return status.CurrentContext, nil
+			})
+
+			if checks.failed > 0 {
+				fmt.Printf("\n%d check(s) failed\n", checks.failed)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// doctorProbeTimeout is how long doctor waits for the current context's API server
+const doctorProbeTimeout = 5 * time.Second
+
+// doctorChecks runs the checks of doctor, printing a pass or fail line for each
+type doctorChecks struct {
+	out    io.Writer
+	failed int
+}
+
+// check runs fn and prints whether it passed, with the detail it returned, or why it
+// failed, counting failures
+func (d *doctorChecks) check(name string, fn func() (string, error)) {
+	detail, err := fn()
+	if err != nil {
+		d.failed++
+		fmt.Fprintf(d.out, "✗ %s: %v\n", name, err)
+		return
+	}
+	if detail != "" {
+		fmt.Fprintf(d.out, "✓ %s (%s)\n", name, detail)
+	} else {
+		fmt.Fprintf(d.out, "✓ %s\n", name)
+	}
+}
+
+// checkSocket checks that the unix socket exists and has the configured permissions
+func checkSocket(serverCfg config.ServerConfig) (string, error) {
+	info, err := os.Stat(serverCfg.SocketPath)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return "", fmt.Errorf("%s is not a socket", serverCfg.SocketPath)
+	}
+	want, err := serverCfg.SocketFileMode()
+	if err != nil {
+		return "", err
+	}
+	if got := info.Mode().Perm(); got != want {
+		return "", fmt.Errorf("%s has mode %04o, expected %04o", serverCfg.SocketPath, got, want)
+	}
+	return fmt.Sprintf("%04o", want), nil
+}
+
+func refreshCmd() *cobra.Command {
+	var ctx string
+	var soft bool
+	var clientsOnly bool
+
+	cmd := &cobra.Command{
 		Use:   "refresh",
 		Short: "Refresh kubeconfig",
-		Long:  "Tell the server to reload the kubeconfig file",
+		Long: `Tell the server to reload the kubeconfig file.
+
+Without --context all watches and caches are reset. With --context only that
+context is reset and re-initialized, leaving other contexts warm. With --soft
+watched resources are re-listed in place, so completions keep working.
+With --clients-only just the Kubernetes clients are rebuilt (e.g. after rotating
+credentials); watches and caches are kept and switch over on their next reconnect.
+
+Examples:
+  kfzf refresh
+  kfzf refresh --context prod
+  kfzf refresh --soft
+  kfzf refresh --clients-only`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := loadConfig()
 			c := client.NewClient(cfg)
@@ -428,19 +1024,98 @@ func refreshCmd() *cobra.Command {
 				return fmt.Errorf("server is not running")
 			}
 
-			if err := c.Refresh(); err != nil {
+			if err := c.Refresh(ctx, soft, clientsOnly); err != nil {
 				return err
 			}
 
-			fmt.Println("Kubeconfig refreshed")
+			switch {
+			case clientsOnly:
+				fmt.Println("Clients rebuilt")
+			case ctx != "":
+				fmt.Printf("Context %s refreshed\n", ctx)
+			default:
+				fmt.Println("Kubeconfig refreshed")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Only refresh this context")
+	cmd.Flags().BoolVar(&soft, "soft", false, "Re-list watched resources without dropping caches")
+	cmd.Flags().BoolVar(&clientsOnly, "clients-only", false, "Only rebuild clients (e.g. after credential rotation), keeping watches and caches")
+	cmd.MarkFlagsMutuallyExclusive("clients-only", "soft")
+	cmd.MarkFlagsMutuallyExclusive("clients-only", "context")
+
+	return cmd
+}
+
+func logLevelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "log-level <debug|info|warn|error>",
+		Short: "Change the server log level without restarting",
+		Long: `Change the log level of the running server. The cache and watches are kept,
+so debug logging can be turned on to diagnose a problem and off again afterwards.
+
+Examples:
+  kfzf log-level debug
+  kfzf log-level info`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"debug", "info", "warn", "error"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running")
+			}
+
+			output, err := c.SetLogLevel(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(output)
 			return nil
 		},
 	}
 }
 
+func statsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Manage server statistics",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "reset",
+		Short: "Reset request and watch event counters",
+		Long: `Zero the request and watch event counters shown by "kfzf status" without
+restarting the server, e.g. before a benchmark or after resolving an incident.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running")
+			}
+
+			if err := c.ResetStats(); err != nil {
+				return err
+			}
+
+			fmt.Println("Statistics reset")
+			return nil
+		},
+	})
+
+	return cmd
+}
+
 func watchCmd() *cobra.Command {
 	var ctx string
 	var stop bool
+	var stopAll bool
 
 	cmd := &cobra.Command{
 		Use:   "watch <resource-types...>",
@@ -449,8 +1124,15 @@ func watchCmd() *cobra.Command {
 
 Examples:
   kfzf watch pods deployments
-  kfzf watch --stop pods`,
-		Args: cobra.MinimumNArgs(1),
+  kfzf watch --stop pods
+  kfzf watch --stop-all --context old-cluster
+  kfzf watch list`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if stopAll {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg := loadConfig()
 			c := client.NewClient(cfg)
@@ -459,7 +1141,16 @@ Examples:
 				return fmt.Errorf("server is not running")
 			}
 
-			if stop {
+			if stopAll {
+				if err := c.StopContext(ctx); err != nil {
+					return err
+				}
+				if ctx != "" {
+					fmt.Printf("Stopped all watches for context %s\n", ctx)
+				} else {
+					fmt.Println("Stopped all watches for the current context")
+				}
+			} else if stop {
 				if err := c.StopWatch(ctx, args); err != nil {
 					return err
 				}
@@ -477,6 +1168,95 @@ Examples:
 
 	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context")
 	cmd.Flags().BoolVar(&stop, "stop", false, "Stop watching instead of starting")
+	cmd.Flags().BoolVar(&stopAll, "stop-all", false, "Stop every watch for the context and clear its cache")
+
+	// List subcommand
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List watched resource types",
+		Long: `List the resource types the server is watching, per context, and whether
+their initial list has completed (synced).
+
+Examples:
+  kfzf watch list
+  kfzf watch list --context prod
+  kfzf watch list --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running")
+			}
+
+			ctx, _ := cmd.Flags().GetString("context")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			watches, err := c.WatchList(ctx)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				data, _ := json.MarshalIndent(watches, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "CONTEXT\tRESOURCE\tSYNCED")
+			for _, watch := range watches {
+				resource := watch.Resource
+				if watch.Group != "" {
+					resource += "." + watch.Group
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%t\n", watch.Context, resource, watch.Synced)
+			}
+			return w.Flush()
+		},
+	}
+	listCmd.Flags().StringP("context", "c", "", "Only show this context")
+	listCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	cmd.AddCommand(listCmd)
+
+	return cmd
+}
+
+func streamCmd() *cobra.Command {
+	var ctx string
+	var namespace string
+
+	cmd := &cobra.Command{
+		Use:   "stream <resource-type>",
+		Short: "Print changes to a resource type as JSON events",
+		Long: `Watch a resource type through the server and print one JSON object per line for
+every resource it has cached ("added"), then for every change as it happens ("added",
+"modified" or "deleted"), until interrupted. Frontends such as a TUI can read this
+instead of polling complete.
+
+Examples:
+  kfzf stream pods
+  kfzf stream deployments -n prod --context staging`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running. Start it with: kfzf server")
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			return c.Stream(ctx, namespace, args[0], func(event *server.StreamEvent) error {
+				return encoder.Encode(event)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context (default: current)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace (default: all namespaces)")
 
 	return cmd
 }
@@ -610,6 +1390,80 @@ func configCmd() *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file for mistakes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := cfgFile
+			if path == "" {
+				path = config.ConfigPath()
+			}
+
+			_, warnings, err := config.LoadAndValidate(path)
+			if err != nil {
+				return err
+			}
+			for _, warning := range warnings {
+				fmt.Printf("warning: %s\n", warning)
+			}
+			if len(warnings) > 0 {
+				return fmt.Errorf("%s: %d warning(s)", path, len(warnings))
+			}
+
+			fmt.Printf("%s: OK\n", path)
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+func debugCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Troubleshooting commands",
+	}
+
+	var ctx string
+	var namespace string
+
+	dumpCmd := &cobra.Command{
+		Use:   "dump <resource-type> <name>",
+		Short: "Print the cached object for a resource",
+		Long: `Print the object kfzf has cached for a resource as JSON.
+
+This is the pruned cache representation: fields kfzf does not need (such as
+managedFields and last-applied annotations) have been removed, so it shows
+exactly what column fields are evaluated against. Use it to find out why a
+custom column renders empty.
+
+Examples:
+  kfzf debug dump pods my-pod -n default
+  kfzf debug dump nodes worker-1`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running. Start it with: kfzf server")
+			}
+
+			output, err := c.DebugDump(ctx, namespace, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, "# pruned cache representation (not the full object from the API server)")
+			fmt.Print(output)
+			return nil
+		},
+	}
+	dumpCmd.Flags().StringVarP(&ctx, "context", "c", "", "Kubernetes context (default: current)")
+	dumpCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace (default: search all namespaces)")
+
+	cmd.AddCommand(dumpCmd)
 	return cmd
 }
 
@@ -623,9 +1477,13 @@ Add this to your .zshrc:
   source <(kfzf zsh-completion)
 
 Or save to a file:
-  kfzf zsh-completion > ~/.zsh/completions/_kfzf_kubectl`,
+  kfzf zsh-completion > ~/.zsh/completions/_kfzf_kubectl
+
+Besides kubectl and k, the command names in completion.aliases of the config
+file and in KFZF_KUBECTL_ALIASES are completed (e.g. kc or kubecolor).`,
 		Run: func(cmd *cobra.Command, args []string) {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s", zshCompletionScript)
+			commands := loadConfig().Completion.KubectlCommands()
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "_kfzf_kubectl_commands=(%s)\n%s", strings.Join(commands, " "), zshCompletionScript)
 		},
 	}
 }
@@ -639,18 +1497,34 @@ func systemdCmd() *cobra.Command {
 		Short: "Manage systemd user service",
 		Long: `Manage kfzf as a systemd user service.
 
+A companion kfzf.socket unit is installed so the socket exists immediately
+and the daemon is started by systemd on the first completion.
+
+With --instance NAME the units are named kfzf-NAME.service and kfzf-NAME.socket
+and serve that instance's socket, so several instances can be installed side by side.
+
 Examples:
-  kfzf systemd              # Print the service file
-  kfzf systemd --install    # Install and enable the service
-  kfzf systemd --uninstall  # Stop and remove the service`,
+  kfzf systemd                          # Print the service file
+  kfzf systemd --install                # Install and enable the service
+  kfzf systemd --uninstall              # Stop and remove the service
+  kfzf systemd --install --instance prod`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			home, err := os.UserHomeDir()
 			if err != nil {
 				return fmt.Errorf("failed to get home directory: %w", err)
 			}
 
+			serverCfg := loadConfig().Server
+			unitName := "kfzf"
+			var serverArgs string
+			if serverCfg.Instance != "" {
+				unitName += "-" + serverCfg.Instance
+				serverArgs = " --instance " + serverCfg.Instance
+			}
+
 			serviceDir := home + "/.config/systemd/user"
-			servicePath := serviceDir + "/kfzf.service"
+			servicePath := serviceDir + "/" + unitName + ".service"
+			socketPath := serviceDir + "/" + unitName + ".socket"
 
 			if uninstall {
 				// Stop and disable service
@@ -658,15 +1532,18 @@ Examples:
 					c := execCommand("systemctl", args...)
 					_ = c.Run()
 				}
-				exec("--user", "stop", "kfzf.service")
-				exec("--user", "disable", "kfzf.service")
+				exec("--user", "stop", unitName+".socket", unitName+".service")
+				exec("--user", "disable", unitName+".socket", unitName+".service")
 
 				if err := os.Remove(servicePath); err != nil && !os.IsNotExist(err) {
 					return fmt.Errorf("failed to remove service file: %w", err)
 				}
+				if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove socket file: %w", err)
+				}
 
 				exec("--user", "daemon-reload")
-				fmt.Println("kfzf service uninstalled")
+				fmt.Printf("%s service uninstalled\n", unitName)
 				return nil
 			}
 
@@ -682,8 +1559,17 @@ Examples:
 				kubeconfig = home + "/.kube/config"
 			}
 
-			// Generate service content
-			serviceContent := fmt.Sprintf(systemdServiceTemplate, kfzfPath, home, kubeconfig)
+			// Generate service and socket content
+			serviceContent := fmt.Sprintf(systemdServiceTemplate, unitName, kfzfPath, serverArgs, home, kubeconfig)
+			socketMode, err := serverCfg.SocketFileMode()
+			if err != nil {
+				return err
+			}
+			socketOptions := fmt.Sprintf("SocketMode=%04o", socketMode)
+			if serverCfg.SocketGroup != "" {
+				socketOptions += "\nSocketGroup=" + serverCfg.SocketGroup
+			}
+			socketContent := fmt.Sprintf(systemdSocketTemplate, serverCfg.SocketPath, socketOptions)
 
 			if install {
 				// Create directory if needed
@@ -691,10 +1577,13 @@ Examples:
 					return fmt.Errorf("failed to create service directory: %w", err)
 				}
 
-				// Write service file
+				// Write service and socket files
 				if err := os.WriteFile(servicePath, []byte(serviceContent), 0644); err != nil {
 					return fmt.Errorf("failed to write service file: %w", err)
 				}
+				if err := os.WriteFile(socketPath, []byte(socketContent), 0644); err != nil {
+					return fmt.Errorf("failed to write socket file: %w", err)
+				}
 
 				// Reload and enable
 				c := execCommand("systemctl", "--user", "daemon-reload")
@@ -702,18 +1591,18 @@ Examples:
 					return fmt.Errorf("failed to reload systemd: %w", err)
 				}
 
-				c = execCommand("systemctl", "--user", "enable", "--now", "kfzf.service")
+				c = execCommand("systemctl", "--user", "enable", "--now", unitName+".socket")
 				if err := c.Run(); err != nil {
-					return fmt.Errorf("failed to enable service: %w", err)
+					return fmt.Errorf("failed to enable socket: %w", err)
 				}
 
-				fmt.Println("kfzf service installed and started")
-				fmt.Println("Check status with: systemctl --user status kfzf")
+				fmt.Printf("%s socket installed, the service starts on first completion\n", unitName)
+				fmt.Printf("Check status with: systemctl --user status %s.socket %s.service\n", unitName, unitName)
 				return nil
 			}
 
-			// Just print the service file
-			fmt.Print(serviceContent)
+			// Just print the unit files
+			fmt.Printf("# %s\n%s\n# %s\n%s", servicePath, serviceContent, socketPath, socketContent)
 			return nil
 		},
 	}
@@ -724,16 +1613,102 @@ Examples:
 	return cmd
 }
 
+func launchdCmd() *cobra.Command {
+	var install bool
+	var uninstall bool
+
+	cmd := &cobra.Command{
+		Use:   "launchd",
+		Short: "Manage launchd user agent (macOS)",
+		Long: `Manage kfzf as a launchd user agent on macOS.
+
+Examples:
+  kfzf launchd              # Print the plist
+  kfzf launchd --install    # Install and load the agent
+  kfzf launchd --uninstall  # Unload and remove the agent`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to get home directory: %w", err)
+			}
+
+			agentDir := home + "/Library/LaunchAgents"
+			plistPath := agentDir + "/" + launchdLabel + ".plist"
+
+			if uninstall {
+				c := execCommand("launchctl", "unload", plistPath)
+				_ = c.Run()
+
+				if err := os.Remove(plistPath); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove plist file: %w", err)
+				}
+
+				fmt.Println("kfzf agent uninstalled")
+				return nil
+			}
+
+			// Get the path to kfzf binary
+			kfzfPath, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to get executable path: %w", err)
+			}
+
+			// Get KUBECONFIG from environment, default to ~/.kube/config
+			kubeconfig := os.Getenv("KUBECONFIG")
+			if kubeconfig == "" {
+				kubeconfig = home + "/.kube/config"
+			}
+
+			// Generate plist content
+			logPath := home + "/Library/Logs/kfzf.log"
+			plistContent := launchdPlist(kfzfPath, home, kubeconfig, logPath)
+
+			if install {
+				// Create directory if needed
+				if err := os.MkdirAll(agentDir, 0755); err != nil {
+					return fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+				}
+
+				// Write plist file
+				if err := os.WriteFile(plistPath, []byte(plistContent), 0644); err != nil {
+					return fmt.Errorf("failed to write plist file: %w", err)
+				}
+
+				// Reload in case an older version is already loaded
+				_ = execCommand("launchctl", "unload", plistPath).Run()
+				c := execCommand("launchctl", "load", "-w", plistPath)
+				if err := c.Run(); err != nil {
+					return fmt.Errorf("failed to load agent: %w", err)
+				}
+
+				fmt.Println("kfzf agent installed and started")
+				fmt.Printf("Check status with: launchctl list %s\n", launchdLabel)
+				return nil
+			}
+
+			// Just print the plist
+			fmt.Print(plistContent)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&install, "install", false, "Install and load the launchd agent")
+	cmd.Flags().BoolVar(&uninstall, "uninstall", false, "Unload and remove the launchd agent")
+
+	return cmd
+}
+
 // execCommand is a wrapper to make exec.Command available
 var execCommand = exec.Command
 
 const systemdServiceTemplate = `[Unit]
 Description=kfzf - Kubernetes completion with fzf
 After=network.target
+Requires=%s.socket
 
 [Service]
 Type=simple
-ExecStart=%s server -f
+ExecStart=%s server -f%s
 Restart=on-failure
 RestartSec=5
 Environment="HOME=%s"
@@ -742,3 +1717,118 @@ Environment="KUBECONFIG=%s"
 [Install]
 WantedBy=default.target
 `
+
+const systemdSocketTemplate = `[Unit]
+Description=kfzf socket
+
+[Socket]
+ListenStream=%s
+%s
+
+[Install]
+WantedBy=sockets.target
+`
+
+const launchdLabel = "com.kfzf"
+
+const launchdPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>server</string>
+		<string>-f</string>
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>HOME</key>
+		<string>%s</string>
+		<key>KUBECONFIG</key>
+		<string>%s</string>
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`
+
+// launchdPlist renders the launchd agent plist, escaping the paths for XML
+func launchdPlist(kfzfPath, home, kubeconfig, logPath string) string {
+	return fmt.Sprintf(launchdPlistTemplate, launchdLabel, xmlEscape(kfzfPath), xmlEscape(home), xmlEscape(kubeconfig), xmlEscape(logPath), xmlEscape(logPath))
+}
+
+// xmlEscape escapes s for use as XML character data
+func xmlEscape(s string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func contextsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "contexts",
+		Short: "Inspect kubeconfig contexts",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List contexts from the server's kubeconfig",
+		Long: `List every context of the kubeconfig loaded by the server with its default
+namespace. The current context is marked with "*".
+
+Examples:
+  kfzf contexts list
+  kfzf contexts list --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := loadConfig()
+			c := client.NewClient(cfg)
+
+			if !c.IsServerRunning() {
+				return fmt.Errorf("server is not running")
+			}
+
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			contexts, err := c.Contexts()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				data, _ := json.MarshalIndent(contexts, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "CURRENT\tNAME\tNAMESPACE")
+			for _, info := range contexts {
+				current := ""
+				if info.Current {
+					current = "*"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", current, info.Name, info.Namespace)
+			}
+			return w.Flush()
+		},
+	}
+	listCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	cmd.AddCommand(listCmd)
+
+	return cmd
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pslijkhuis/kfzf/internal/config"
+)
+
+func TestDoctorChecks(t *testing.T) {
+	var out bytes.Buffer
+	checks := &doctorChecks{out: &out}
+	checks.check("plain", func() (string, error) { return "", nil })
+	checks.check("detailed", func() (string, error) { return "v1.30.2", nil })
+	checks.check("broken", func() (string, error) { return "ignored", errors.New("no route to host") })
+
+	want := "✓ plain\n✓ detailed (v1.30.2)\n✗ broken: no route to host\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if checks.failed != 1 {
+		t.Errorf("failed = %d, want 1", checks.failed)
+	}
+}
+
+func TestCheckSocket(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "kfzf.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		t.Fatal(err)
+	}
+	plainFile := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plainFile, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.ServerConfig
+		want    string
+		wantErr string
+	}{
+		{"default mode", config.ServerConfig{SocketPath: socketPath}, "0600", ""},
+		{"other mode", config.ServerConfig{SocketPath: socketPath, SocketMode: "0660"}, "", "has mode 0600, expected 0660"},
+		{"invalid mode", config.ServerConfig{SocketPath: socketPath, SocketMode: "0666"}, "", "world-writable"},
+		{"missing", config.ServerConfig{SocketPath: filepath.Join(dir, "missing.sock")}, "", "no such file"},
+		{"not a socket", config.ServerConfig{SocketPath: plainFile}, "", "is not a socket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkSocket(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("checkSocket() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("checkSocket() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := launchdPlist("/Users/a&b/bin/kfzf", "/Users/a&b", "/Users/a&b/.kube/<dev>.yaml", "/Users/a&b/Library/Logs/kfzf.log")
+
+	for _, want := range []string{
+		"<string>/Users/a&amp;b/bin/kfzf</string>",
+		"<string>/Users/a&amp;b/.kube/&lt;dev&gt;.yaml</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist is missing %q", want)
+		}
+	}
+
+	// The result must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(plist))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("plist is not valid XML: %v", err)
+		}
+	}
+}
@@ -2,7 +2,12 @@ package client
 
 import (
 	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -10,19 +15,62 @@ import (
 	"time"
 
 	"github.com/pslijkhuis/kfzf/internal/config"
+	"github.com/pslijkhuis/kfzf/internal/fzf"
 	"github.com/pslijkhuis/kfzf/internal/server"
 )
 
+// ErrSyncing is returned by Complete when there is nothing to complete yet because
+// the resource type's initial list is still running
+var ErrSyncing = errors.New("resources are still syncing")
+
 // Client communicates with the kfzf server
 type Client struct {
 	socketPath string
+
+	// TCP mode settings (used when address is set)
+	address   string
+	tlsConfig *tls.Config
+	tlsErr    error
+	token     string
 }
 
 // NewClient creates a new client
 func NewClient(cfg *config.Config) *Client {
-	return &Client{
+	c := &Client{
 		socketPath: cfg.Server.SocketPath,
 	}
+
+	if cfg.Server.ListenAddr != "" {
+		c.address = cfg.Server.ListenAddr
+		c.token = cfg.Server.AuthToken()
+		if cfg.Server.TLS.Enabled() {
+			c.tlsConfig, c.tlsErr = clientTLSConfig(cfg.Server.TLS)
+		}
+	}
+
+	return c
+}
+
+// clientTLSConfig builds the TLS configuration used to dial a remote server
+func clientTLSConfig(tlsCfg config.TLSConfig) (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: tlsCfg.InsecureSkipVerify,
+	}
+
+	if tlsCfg.CAFile != "" {
+		pem, err := os.ReadFile(tlsCfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", tlsCfg.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+
+	return conf, nil
 }
 
 // NewClientWithSocket creates a client with a specific socket path
@@ -34,7 +82,7 @@ func NewClientWithSocket(socketPath string) *Client {
 
 // IsServerRunning checks if the server is running
 func (c *Client) IsServerRunning() bool {
-	conn, err := net.DialTimeout("unix", c.socketPath, time.Second)
+	conn, err := c.dial(time.Second)
 	if err != nil {
 		return false
 	}
@@ -42,15 +90,103 @@ func (c *Client) IsServerRunning() bool {
 	return true
 }
 
-// Complete requests completions from the server
-func (c *Client) Complete(ctx, namespace, resourceType string) (string, error) {
+// dial connects to the server over the unix socket, or TCP (optionally TLS) when an address is configured
+func (c *Client) dial(timeout time.Duration) (net.Conn, error) {
+	if c.address == "" {
+		return net.DialTimeout("unix", c.socketPath, timeout)
+	}
+
+	if c.tlsErr != nil {
+		return nil, c.tlsErr
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if c.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", c.address, c.tlsConfig)
+	}
+	return dialer.Dial("tcp", c.address)
+}
+
+// Complete requests completions from the server, at most limit per resource type (0 = unlimited).
+// A comma-separated resourceType ("pods,services") completes several types at once.
+// With namesOnly the output is just the names, one per line. With dedupe resources sharing a
+// name (across namespaces) are collapsed into one row with a "(Nx)" count. A non-empty
+// query returns only the best subsequence matches of the names, ranked by the server.
+// With color the output has ANSI colors if the server enables them (server.color), for
+// display by fzf with --ansi.
+func (c *Client) Complete(ctx, namespace, resourceType, query string, limit int, namesOnly, dedupe, wide, noWatch, color bool) (string, error) {
 	req := &server.Request{
 		Type:         server.RequestTypeComplete,
 		Context:      ctx,
 		Namespace:    namespace,
 		ResourceType: resourceType,
+		Query:        query,
+		Limit:        limit,
+		NamesOnly:    namesOnly,
+		Dedupe:       dedupe,
+		Wide:         wide,
+		NoWatch:      noWatch,
+		Color:        color,
+	}
+	if types := strings.Split(resourceType, ","); len(types) > 1 {
+		req.ResourceType = ""
+		req.ResourceTypes = types
 	}
 
+	return c.complete(req)
+}
+
+// CompleteTemplate gets completions for a single resource type, formatting each resource
+// with a Go text/template instead of the configured columns
+func (c *Client) CompleteTemplate(ctx, namespace, resourceType, tmpl string, limit int, wide, noWatch bool) (string, error) {
+	req := &server.Request{
+		Type:         server.RequestTypeComplete,
+		Context:      ctx,
+		Namespace:    namespace,
+		ResourceType: resourceType,
+		Limit:        limit,
+		Wide:         wide,
+		NoWatch:      noWatch,
+		Template:     tmpl,
+	}
+	return c.complete(req)
+}
+
+// CompleteFormat gets completions for a single resource type as CSV or TSV with a
+// header row, with unpadded column values
+func (c *Client) CompleteFormat(ctx, namespace, resourceType, format string, limit int, wide, noWatch bool) (string, error) {
+	req := &server.Request{
+		Type:         server.RequestTypeComplete,
+		Context:      ctx,
+		Namespace:    namespace,
+		ResourceType: resourceType,
+		Limit:        limit,
+		Wide:         wide,
+		NoWatch:      noWatch,
+		Format:       format,
+	}
+	return c.complete(req)
+}
+
+// CompleteServerColumns gets completions for a single resource type with the columns
+// rendered by the API server, as kubectl get prints them. The type is listed for every
+// request rather than served from the watch cache.
+func (c *Client) CompleteServerColumns(ctx, namespace, resourceType string, limit int, wide bool) (string, error) {
+	req := &server.Request{
+		Type:          server.RequestTypeComplete,
+		Context:       ctx,
+		Namespace:     namespace,
+		ResourceType:  resourceType,
+		Limit:         limit,
+		Wide:          wide,
+		ServerColumns: true,
+	}
+	return c.complete(req)
+}
+
+// complete sends a complete request and returns its output, or ErrSyncing when
+// the output is empty because the resources are still syncing
+func (c *Client) complete(req *server.Request) (string, error) {
 	resp, err := c.sendRequest(req)
 	if err != nil {
 		return "", err
@@ -59,6 +195,9 @@ func (c *Client) Complete(ctx, namespace, resourceType string) (string, error) {
 	if !resp.Success {
 		return "", fmt.Errorf("server error: %s", resp.Error)
 	}
+	if resp.Output == "" && resp.Syncing {
+		return "", ErrSyncing
+	}
 
 	return resp.Output, nil
 }
@@ -81,10 +220,113 @@ func (c *Client) Status() (*server.StatusInfo, error) {
 	return resp.Status, nil
 }
 
-// Refresh tells the server to refresh its kubeconfig
-func (c *Client) Refresh() error {
+// APIResources returns the resource types discovered for a context
+func (c *Client) APIResources(ctx string) ([]server.APIResource, error) {
 	req := &server.Request{
-		Type: server.RequestTypeRefresh,
+		Type:    server.RequestTypeAPIResources,
+		Context: ctx,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return resp.APIResources, nil
+}
+
+// ResourceTypes returns discovered resource type names with short-name hints for completion
+func (c *Client) ResourceTypes(ctx string) (string, error) {
+	req := &server.Request{
+		Type:    server.RequestTypeResourceTypes,
+		Context: ctx,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !resp.Success {
+		return "", fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return resp.Output, nil
+}
+
+// WatchList returns the active watches, for all contexts when ctx is empty
+func (c *Client) WatchList(ctx string) ([]server.WatchInfo, error) {
+	req := &server.Request{
+		Type:    server.RequestTypeWatchList,
+		Context: ctx,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return resp.Watches, nil
+}
+
+// Contexts returns the contexts of the kubeconfig loaded by the server
+func (c *Client) Contexts() ([]server.ContextInfo, error) {
+	req := &server.Request{
+		Type: server.RequestTypeContexts,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return resp.Contexts, nil
+}
+
+// DebugDump returns the cached (pruned) object for a resource as JSON
+func (c *Client) DebugDump(ctx, namespace, resourceType, name string) (string, error) {
+	req := &server.Request{
+		Type:         server.RequestTypeDebugDump,
+		Context:      ctx,
+		Namespace:    namespace,
+		ResourceType: resourceType,
+		ResourceName: name,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !resp.Success {
+		return "", fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return resp.Output, nil
+}
+
+// Refresh tells the server to refresh its kubeconfig.
+// With a context name only that context is refreshed; soft re-lists
+// watched resources in place instead of dropping the caches, and clientsOnly
+// only rebuilds the Kubernetes clients.
+func (c *Client) Refresh(ctx string, soft, clientsOnly bool) error {
+	req := &server.Request{
+		Type:        server.RequestTypeRefresh,
+		Context:     ctx,
+		Soft:        soft,
+		ClientsOnly: clientsOnly,
 	}
 
 	resp, err := c.sendRequest(req)
@@ -162,13 +404,16 @@ func (c *Client) Ports(ctx, namespace, resourceType, resourceName string) (strin
 	return resp.Output, nil
 }
 
-// Labels returns unique label key=value pairs for a resource type from cache
-func (c *Client) Labels(ctx, namespace, resourceType string) (string, error) {
+// Labels returns unique label key=value pairs for a resource type from cache.
+// With counts each pair is followed by a tab and the number of resources carrying it.
+func (c *Client) Labels(ctx, namespace, resourceType string, counts, sortByCount bool) (string, error) {
 	req := &server.Request{
 		Type:         server.RequestTypeLabels,
 		Context:      ctx,
 		Namespace:    namespace,
 		ResourceType: resourceType,
+		Counts:       counts,
+		SortByCount:  sortByCount,
 	}
 
 	resp, err := c.sendRequest(req)
@@ -225,6 +470,62 @@ func (c *Client) StopWatch(ctx string, resourceTypes []string) error {
 	return nil
 }
 
+// StopContext tells the server to stop all watches for a context and drop its cache
+func (c *Client) StopContext(ctx string) error {
+	req := &server.Request{
+		Type:    server.RequestTypeStopContext,
+		Context: ctx,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return nil
+}
+
+// SetLogLevel changes the server's log level at runtime and returns a confirmation message
+func (c *Client) SetLogLevel(level string) (string, error) {
+	req := &server.Request{
+		Type:     server.RequestTypeSetLogLevel,
+		LogLevel: level,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	if !resp.Success {
+		return "", fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return resp.Output, nil
+}
+
+// ResetStats zeroes the server's request and watch event counters
+func (c *Client) ResetStats() error {
+	req := &server.Request{
+		Type: server.RequestTypeResetStats,
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("server error: %s", resp.Error)
+	}
+
+	return nil
+}
+
 // RecordRecent records a recently accessed resource
 func (c *Client) RecordRecent(ctx, namespace, resourceType, resourceName string) error {
 	req := &server.Request{
@@ -270,7 +571,7 @@ func (c *Client) GetRecent(ctx, namespace, resourceType string) (string, error)
 
 // sendRequest sends a request to the server and returns the response
 func (c *Client) sendRequest(req *server.Request) (*server.Response, error) {
-	conn, err := net.DialTimeout("unix", c.socketPath, 5*time.Second)
+	conn, err := c.dial(5 * time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to server: %w", err)
 	}
@@ -284,6 +585,11 @@ func (c *Client) sendRequest(req *server.Request) (*server.Response, error) {
 		return nil, fmt.Errorf("failed to encode request: %w", err)
 	}
 
+	// Authenticate first when talking to a TCP listener
+	if c.address != "" {
+		data = append(server.EncodeToken(c.token), data...)
+	}
+
 	if _, err := conn.Write(data); err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -302,12 +608,86 @@ func (c *Client) sendRequest(req *server.Request) (*server.Response, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if resp.Warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", resp.Warning)
+	}
+
 	return resp, nil
 }
 
+// Stream subscribes to the changes of a resource type and calls handle for each event,
+// starting with an added event per current resource. It returns when handle returns an
+// error (which is returned), the server ends the stream or the connection is lost.
+func (c *Client) Stream(ctx, namespace, resourceType string, handle func(*server.StreamEvent) error) error {
+	conn, err := c.dial(5 * time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	data, err := server.EncodeRequest(&server.Request{
+		Type:         server.RequestTypeStream,
+		Context:      ctx,
+		Namespace:    namespace,
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	if c.address != "" {
+		data = append(server.EncodeToken(c.token), data...)
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// The first line answers the request like any response; it may take as long as the
+	// initial list of the resource type
+	_ = conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	reader := bufio.NewReader(conn)
+	respData, err := reader.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	resp, err := server.DecodeResponse(respData)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("server error: %s", resp.Error)
+	}
+	if resp.Warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", resp.Warning)
+	}
+
+	// Events arrive whenever resources change, so there is no deadline from here on
+	_ = conn.SetReadDeadline(time.Time{})
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("server closed the stream")
+			}
+			return fmt.Errorf("failed to read event: %w", err)
+		}
+		var event server.StreamEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		if event.Type == "error" {
+			return fmt.Errorf("server error: %s", event.Error)
+		}
+		if err := handle(&event); err != nil {
+			return err
+		}
+	}
+}
+
 // CompleteWithFzf gets completions and pipes them through fzf
-func (c *Client) CompleteWithFzf(ctx, namespace, resourceType string, fzfOpts []string) (string, error) {
-	output, err := c.Complete(ctx, namespace, resourceType)
+func (c *Client) CompleteWithFzf(ctx, namespace, resourceType string, limit int, dedupe, wide, noWatch bool, fzfOpts []string) (string, error) {
+	output, err := c.Complete(ctx, namespace, resourceType, "", limit, false, dedupe, wide, noWatch, true)
 	if err != nil {
 		return "", err
 	}
@@ -326,6 +706,10 @@ func (c *Client) CompleteWithFzf(ctx, namespace, resourceType string, fzfOpts []
 		"--select-1",       // Auto-select if only one match
 		"--exit-0",         // Exit if no match
 	}
+	if n := fzf.DisplayFields(output); n > 0 {
+		// Hide the full names appended to rows with a truncated name
+		args = append(args, fmt.Sprintf("--with-nth=1..%d", n))
+	}
 	args = append(args, fzfOpts...)
 
 	cmd := exec.Command("fzf", args...)
@@ -343,26 +727,7 @@ func (c *Client) CompleteWithFzf(ctx, namespace, resourceType string, fzfOpts []
 		return "", fmt.Errorf("fzf failed: %w", err)
 	}
 
-	// Extract the name (first column) from the selected line
-	line := string(result)
-	if line == "" {
-		return "", nil
-	}
-
-	// Split by tab and return first field (the name)
-	for i, ch := range line {
-		if ch == '\t' || ch == ' ' {
-			return line[:i], nil
-		}
-	}
-
-	// No tab found, return trimmed line
-	return trimNewline(line), nil
-}
-
-func trimNewline(s string) string {
-	for len(s) > 0 && (s[len(s)-1] == '\n' || s[len(s)-1] == '\r') {
-		s = s[:len(s)-1]
-	}
-	return s
+	// Extract the name (first column) from the selected line, without colors
+	// and undoing the display escaping
+	return fzf.SelectedName(string(result)), nil
 }
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pslijkhuis/kfzf/internal/server"
+)
+
+// serveStream accepts one connection on a unix socket, answers its stream request with
+// the given lines and reports when the client disconnects
+func serveStream(t *testing.T, lines ...string) (*Client, <-chan *server.Request, <-chan struct{}) {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "kfzf.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	requests := make(chan *server.Request, 1)
+	disconnected := make(chan struct{})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		data, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		req, _ := server.DecodeRequest(data)
+		requests <- req
+		for _, line := range lines {
+			if _, err := conn.Write([]byte(line + "\n")); err != nil {
+				return
+			}
+		}
+		// The client ends the stream by closing the connection
+		_, _ = io.Copy(io.Discard, reader)
+		close(disconnected)
+	}()
+	return NewClientWithSocket(socketPath), requests, disconnected
+}
+
+func TestStream(t *testing.T) {
+	c, requests, disconnected := serveStream(t,
+		`{"success":true}`,
+		`{"type":"added","namespace":"default","name":"web","object":{"kind":"Pod"}}`,
+		`{"type":"deleted","namespace":"default","name":"db"}`,
+		`{"type":"added","namespace":"default","name":"api"}`,
+	)
+
+	errDone := errors.New("done")
+	var got []string
+	err := c.Stream("prod", "default", "pods", func(event *server.StreamEvent) error {
+		got = append(got, event.Type+" "+event.Name)
+		if event.Name == "db" {
+			return errDone
+		}
+		return nil
+	})
+	if !errors.Is(err, errDone) {
+		t.Errorf("Stream() error = %v, want the handler's error", err)
+	}
+	if want := []string{"added web", "deleted db"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	req := <-requests
+	if req.Type != server.RequestTypeStream || req.Context != "prod" || req.Namespace != "default" || req.ResourceType != "pods" {
+		t.Errorf("request = %+v, want a stream of prod/default pods", req)
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Error("client did not close the connection")
+	}
+}
+
+func TestStream_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{"failed request", []string{`{"success":false,"error":"unknown resource type"}`}, "server error: unknown resource type"},
+		{"error event", []string{`{"success":true}`, `{"type":"error","error":"watch stopped"}`}, "server error: watch stopped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, _ := serveStream(t, tt.lines...)
+			err := c.Stream("", "", "pods", func(*server.StreamEvent) error { return nil })
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Stream() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
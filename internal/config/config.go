@@ -2,8 +2,16 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,17 +20,233 @@ import (
 type Config struct {
 	Server    ServerConfig              `yaml:"server"`
 	Resources map[string]ResourceConfig `yaml:"resources"`
+	// Contexts holds per-context overrides keyed by context name, glob pattern (e.g. "prod-*")
+	// or regular expression between slashes (e.g. "/^prod-(eu|us)$/")
+	Contexts map[string]ContextConfig `yaml:"contexts,omitempty"`
+	// Completion configures the generated shell completion
+	Completion CompletionConfig `yaml:"completion,omitempty"`
+}
+
+// CompletionConfig holds shell completion settings
+type CompletionConfig struct {
+	// Aliases are extra command names completed like kubectl (e.g. kc or kubecolor),
+	// in addition to kubectl and k. KFZF_KUBECTL_ALIASES adds more.
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+// commandNamePattern matches command names that are safe to embed in the shell script
+var commandNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+
+// KubectlCommands returns the command names completed like kubectl: kubectl and k,
+// then the configured aliases, then those in KFZF_KUBECTL_ALIASES (separated by commas
+// or spaces), without duplicates. Names that aren't plain command names are skipped.
+func (c CompletionConfig) KubectlCommands() []string {
+	commands := []string{"kubectl", "k"}
+	aliases := slices.Concat(c.Aliases, strings.FieldsFunc(os.Getenv("KFZF_KUBECTL_ALIASES"), func(r rune) bool {
+		return r == ',' || r == ' '
+	}))
+	for _, alias := range aliases {
+		if alias = strings.TrimSpace(alias); commandNamePattern.MatchString(alias) && !slices.Contains(commands, alias) {
+			commands = append(commands, alias)
+		}
+	}
+	return commands
+}
+
+// ContextConfig holds settings that can be overridden per context.
+// Zero values inherit the server-wide setting.
+type ContextConfig struct {
+	// QPS and Burst limit the client-side request rate to the API server
+	QPS   float32 `yaml:"qps,omitempty"`
+	Burst int     `yaml:"burst,omitempty"`
+	// Timeout bounds list and discovery requests (watch streams are not affected)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
 }
 
 // ServerConfig holds server-specific settings
 type ServerConfig struct {
 	SocketPath string `yaml:"socketPath"`
+	// SocketMode is the octal permission mode of the unix socket (default: "0600").
+	// World-writable modes are rejected.
+	SocketMode string `yaml:"socketMode,omitempty"`
+	// SocketGroup is the group name or ID given ownership of the unix socket, so a mode
+	// such as "0660" can grant access to its members (default: the server's group)
+	SocketGroup string `yaml:"socketGroup,omitempty"`
+	// ListenAddr is an optional TCP address (host:port) to serve on instead of the unix socket
+	ListenAddr string `yaml:"listenAddr,omitempty"`
+	// TLS configures TLS for the TCP listener
+	TLS TLSConfig `yaml:"tls,omitempty"`
+	// Token is the shared secret required from clients on the TCP listener.
+	// The KFZF_TOKEN environment variable takes precedence when set.
+	Token string `yaml:"token,omitempty"`
+	// Prewarm blocks startup until the default resources have been listed,
+	// so the first completion after a restart is served from a populated cache
+	Prewarm bool `yaml:"prewarm,omitempty"`
+	// ReadyGate makes completions of a default resource wait for its initial list
+	// (up to ReadyGateTimeout) instead of answering from a possibly empty cache
+	ReadyGate        bool          `yaml:"readyGate,omitempty"`
+	ReadyGateTimeout time.Duration `yaml:"readyGateTimeout,omitempty"`
+	// DeleteGracePeriod keeps deleted resources available to lookups such as previews for
+	// this long, so a resource picked just before it was deleted can still be shown.
+	// Deleted resources are never completed. Disabled when zero (the default).
+	DeleteGracePeriod time.Duration `yaml:"deleteGracePeriod,omitempty"`
+	// StaleWatchTimeout restarts the watch of a large resource set that hasn't changed for
+	// this long, on the assumption that the watch silently died. Only useful on busy
+	// clusters where such sets change constantly. Disabled when zero (the default).
+	StaleWatchTimeout time.Duration `yaml:"staleWatchTimeout,omitempty"`
+	// Color colors completion columns (names, namespaces, statuses, readiness, ages) for
+	// the clients that ask for it because they display through fzf --ansi: the shell
+	// integration and complete --fzf. Plain complete output stays uncolored.
+	Color bool `yaml:"color,omitempty"`
+	// NamespaceColors colors each namespace with a stable color derived from its name
+	// instead of a single color, to visually group resources across namespaces (with Color)
+	NamespaceColors bool `yaml:"namespaceColors,omitempty"`
+	// HighlightMatches bolds the characters of the name column matched by a server-side
+	// query (complete --query), showing why a resource matched (with Color)
+	HighlightMatches bool `yaml:"highlightMatches,omitempty"`
+	// ContainerStates adds each container's current state (running, waiting reason or
+	// termination reason) to the containers command output
+	ContainerStates bool `yaml:"containerStates,omitempty"`
+	// GroupByNamespace clusters all-namespace completions by namespace, with a
+	// header line before each group
+	GroupByNamespace bool `yaml:"groupByNamespace,omitempty"`
+	// AgeFormat selects how ages are rendered: "short" (default, e.g. 3d) or "compound" (e.g. 3d4h)
+	AgeFormat string `yaml:"ageFormat,omitempty"`
+	// TimeFormat is the Go time layout for abs: timestamp columns, rendered in local time
+	// (default: "2006-01-02 15:04:05")
+	TimeFormat string `yaml:"timeFormat,omitempty"`
+	// TargetedDiscovery limits API discovery to the groups of default and configured resources,
+	// falling back to full discovery only for unknown resource types
+	TargetedDiscovery bool `yaml:"targetedDiscovery,omitempty"`
+	// DiscoveryTTL is how long discovered API resources are cached after last use (default: 24h)
+	DiscoveryTTL time.Duration `yaml:"discoveryTTL,omitempty"`
+	// ResourcePreference maps a bare resource name served by several API groups
+	// to the resource.group to use (e.g. events: events.events.k8s.io)
+	ResourcePreference map[string]string `yaml:"resourcePreference,omitempty"`
+	// Impersonate makes all API requests as another user/group (like kubectl --as)
+	Impersonate ImpersonateConfig `yaml:"impersonate,omitempty"`
+	// QPS and Burst limit the client-side request rate to the API server
+	QPS   float32 `yaml:"qps,omitempty"`
+	Burst int     `yaml:"burst,omitempty"`
+	// Timeout bounds list and discovery requests (0 = no timeout)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// WatchBackoff is the delay before retrying a failed watch, doubling up to WatchMaxBackoff
+	// (defaults: 1s and 5m). Retries are jittered so watches don't reconnect in lockstep.
+	WatchBackoff    time.Duration `yaml:"watchBackoff,omitempty"`
+	WatchMaxBackoff time.Duration `yaml:"watchMaxBackoff,omitempty"`
+	// LogFile makes the server log to this file instead of stderr, rotating it once it
+	// reaches LogMaxSize megabytes and keeping LogMaxBackups old files (see LogBackups)
+	LogFile       string `yaml:"logFile,omitempty"`
+	LogMaxSize    int    `yaml:"logMaxSize,omitempty"`
+	LogMaxBackups *int   `yaml:"logMaxBackups,omitempty"`
+	// Instance names one of several daemons running side by side (set with --instance,
+	// not in the config file). See SetInstance.
+	Instance string `yaml:"-"`
+}
+
+// ImpersonateConfig holds user impersonation settings
+type ImpersonateConfig struct {
+	// User is the username to impersonate
+	User string `yaml:"as,omitempty"`
+	// Groups are the groups to impersonate
+	Groups []string `yaml:"asGroups,omitempty"`
+}
+
+// TLSConfig holds TLS settings for the TCP listener
+type TLSConfig struct {
+	// CertFile and KeyFile are the server certificate and key
+	CertFile string `yaml:"certFile,omitempty"`
+	KeyFile  string `yaml:"keyFile,omitempty"`
+	// CAFile is used by clients to verify the server certificate (default: system roots)
+	CAFile string `yaml:"caFile,omitempty"`
+	// InsecureSkipVerify disables server certificate verification on the client
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Enabled returns whether TLS is configured
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.CAFile != "" || t.InsecureSkipVerify
+}
+
+// DefaultSocketMode is the permission mode of the unix socket when none is configured
+const DefaultSocketMode os.FileMode = 0600
+
+// SocketFileMode parses SocketMode, rejecting invalid and world-writable modes
+func (s ServerConfig) SocketFileMode() (os.FileMode, error) {
+	if s.SocketMode == "" {
+		return DefaultSocketMode, nil
+	}
+	mode, err := strconv.ParseUint(s.SocketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socketMode %q: expected an octal permission mode such as 0660", s.SocketMode)
+	}
+	if mode&0002 != 0 {
+		return 0, fmt.Errorf("invalid socketMode %q: the socket must not be world-writable", s.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// instanceNamePattern restricts instance names to what is safe in file and unit names
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// SetInstance selects a named instance, giving it its own socket and log file:
+// "kfzf.sock" becomes "kfzf-NAME.sock" in the same directory. An empty name is the
+// default instance.
+func (s *ServerConfig) SetInstance(name string) error {
+	if name == "" {
+		return nil
+	}
+	if !instanceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	s.Instance = name
+	s.SocketPath = InstancePath(s.SocketPath, name)
+	if s.LogFile != "" {
+		s.LogFile = InstancePath(s.LogFile, name)
+	}
+	return nil
+}
+
+// InstancePath inserts an instance name before the extension of a file path,
+// e.g. /tmp/kfzf.sock becomes /tmp/kfzf-prod.sock. An empty name leaves it unchanged.
+func InstancePath(file, instance string) string {
+	if instance == "" {
+		return file
+	}
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "-" + instance + ext
+}
+
+// defaultLogMaxBackups is the number of rotated log files kept when logMaxBackups is unset
+const defaultLogMaxBackups = 3
+
+// LogBackups returns how many rotated log files to keep: LogMaxBackups, or 3 when it is
+// unset. 0 keeps all of them.
+func (s ServerConfig) LogBackups() int {
+	if s.LogMaxBackups == nil {
+		return defaultLogMaxBackups
+	}
+	return max(*s.LogMaxBackups, 0)
+}
+
+// AuthToken returns the shared secret for the TCP listener, preferring KFZF_TOKEN
+func (s ServerConfig) AuthToken() string {
+	if token := os.Getenv("KFZF_TOKEN"); token != "" {
+		return token
+	}
+	return s.Token
 }
 
 // ResourceConfig defines how to display a specific resource type
 type ResourceConfig struct {
 	// Columns to display in fzf output
 	Columns []ColumnConfig `yaml:"columns"`
+	// ColumnsWide is an optional extended column set used by `complete --wide`
+	// (like kubectl get -o wide)
+	ColumnsWide []ColumnConfig `yaml:"columnsWide,omitempty"`
+	// Namespaced overrides the scope reported by discovery (or the built-in list) when set.
+	// An escape hatch for clusters whose discovery mislabels a CRD's scope.
+	Namespaced *bool `yaml:"namespaced,omitempty"`
 }
 
 // ColumnConfig defines a single column in the fzf output
@@ -34,13 +258,60 @@ type ColumnConfig struct {
 	Field string `yaml:"field"`
 	// Width is the fixed width for the column (0 = auto)
 	Width int `yaml:"width"`
+	// Truncate selects which part of an over-long value is kept: "end" (default) keeps
+	// the beginning, "start" keeps the end, "middle" keeps both ends. Name and namespace
+	// columns are padded but never truncated, since completion returns their full value,
+	// except for a name column with "left": it shows "...<tail>" and the full name is
+	// appended to the row as a last field for selection.
+	Truncate string `yaml:"truncate,omitempty"`
+	// Separator joins the values of a [*] array field (default: ",")
+	Separator string `yaml:"separator,omitempty"`
+	// MaxItems limits how many values of a [*] array field are shown, adding "+N more" (0 = all)
+	MaxItems int `yaml:"maxItems,omitempty"`
+	// Bool renders a boolean field as "trueLabel/falseLabel" (e.g. "Suspended/Active" or "✓/✗").
+	// A missing field renders as the false label.
+	Bool string `yaml:"bool,omitempty"`
+}
+
+// BoolLabels returns the true and false labels of a Bool column, and false if Bool is
+// unset or not of the form "trueLabel/falseLabel"
+func (c ColumnConfig) BoolLabels() (string, string, bool) {
+	trueLabel, falseLabel, ok := strings.Cut(c.Bool, "/")
+	if !ok || strings.Contains(falseLabel, "/") {
+		return "", "", false
+	}
+	return trueLabel, falseLabel, true
 }
 
+// Column truncation modes
+const (
+	TruncateEnd    = "end"
+	TruncateMiddle = "middle"
+	TruncateStart  = "start"
+	TruncateLeft   = "left"
+)
+
+// Age formats
+const (
+	AgeFormatShort    = "short"
+	AgeFormatCompound = "compound"
+)
+
+// DefaultTimeFormat is the layout for abs: timestamp columns when none is configured
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
 // DefaultConfig returns a sensible default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			SocketPath: filepath.Join(os.TempDir(), "kfzf.sock"),
+			SocketPath:       filepath.Join(os.TempDir(), "kfzf.sock"),
+			QPS:              50,
+			Burst:            100,
+			DiscoveryTTL:     24 * time.Hour,
+			ReadyGateTimeout: 10 * time.Second,
+			WatchBackoff:     time.Second,
+			WatchMaxBackoff:  5 * time.Minute,
+			LogMaxSize:       10,
 		},
 		Resources: map[string]ResourceConfig{
 			"pods": {
@@ -50,6 +321,15 @@ func DefaultConfig() *Config {
 					{Name: "STATUS", Field: ".status.phase", Width: 12},
 					{Name: "AGE", Field: ".metadata.creationTimestamp", Width: 10},
 				},
+				ColumnsWide: []ColumnConfig{
+					{Name: "NAME", Field: ".metadata.name", Width: 40},
+					{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 40},
+					{Name: "STATUS", Field: ".status.phase", Width: 12},
+					{Name: "RESTARTS", Field: "_podRestarts", Width: 8},
+					{Name: "AGE", Field: ".metadata.creationTimestamp", Width: 10},
+					{Name: "IP", Field: ".status.podIP", Width: 16},
+					{Name: "NODE", Field: ".spec.nodeName", Width: 30},
+				},
 			},
 			"deployments": {
 				Columns: []ColumnConfig{
@@ -65,6 +345,7 @@ func DefaultConfig() *Config {
 					{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 40},
 					{Name: "TYPE", Field: ".spec.type", Width: 12},
 					{Name: "CLUSTER-IP", Field: ".spec.clusterIP", Width: 16},
+					{Name: "ENDPOINTS", Field: "_svcEndpoints", Width: 10},
 				},
 			},
 			"configmaps": {
@@ -128,22 +409,42 @@ func DefaultConfig() *Config {
 					{Name: "AGE", Field: ".metadata.creationTimestamp", Width: 10},
 				},
 			},
+			"replicasets": {
+				Columns: []ColumnConfig{
+					{Name: "NAME", Field: ".metadata.name", Width: 40},
+					{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 40},
+					{Name: "DESIRED", Field: ".spec.replicas", Width: 8},
+					{Name: "CURRENT", Field: ".status.replicas", Width: 8},
+					{Name: "READY", Field: ".status.readyReplicas", Width: 8},
+					{Name: "AGE", Field: ".metadata.creationTimestamp", Width: 10},
+				},
+			},
 			"daemonsets": {
 				Columns: []ColumnConfig{
 					{Name: "NAME", Field: ".metadata.name", Width: 40},
 					{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 40},
-					{Name: "DESIRED", Field: ".status.desiredNumberScheduled", Width: 8},
-					{Name: "READY", Field: ".status.numberReady", Width: 8},
+					{Name: "READY", Field: "_dsReady", Width: 10},
 				},
 			},
 			"jobs": {
 				Columns: []ColumnConfig{
 					{Name: "NAME", Field: ".metadata.name", Width: 40},
 					{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 40},
-					{Name: "COMPLETIONS", Field: ".status.succeeded/.spec.completions", Width: 12},
+					{Name: "COMPLETIONS", Field: "_jobCompletions", Width: 16},
+					{Name: "DURATION", Field: "_jobDuration", Width: 10},
 					{Name: "AGE", Field: ".metadata.creationTimestamp", Width: 10},
 				},
 			},
+			"horizontalpodautoscalers": {
+				Columns: []ColumnConfig{
+					{Name: "NAME", Field: ".metadata.name", Width: 40},
+					{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 40},
+					{Name: "MIN", Field: ".spec.minReplicas", Width: 6},
+					{Name: "MAX", Field: ".spec.maxReplicas", Width: 6},
+					{Name: "REPLICAS", Field: ".status.currentReplicas", Width: 8},
+					{Name: "TARGETS", Field: "_hpaTargets", Width: 30},
+				},
+			},
 			"cronjobs": {
 				Columns: []ColumnConfig{
 					{Name: "NAME", Field: ".metadata.name", Width: 40},
@@ -256,8 +557,28 @@ func Load() (*Config, error) {
 	return LoadFrom(ConfigPath())
 }
 
-// LoadFrom loads configuration from a specific path, merging with defaults
+// LoadFrom loads configuration from a specific path, merging with defaults.
+// Validation warnings are logged; they don't fail the load.
 func LoadFrom(path string) (*Config, error) {
+	cfg, warnings, err := LoadAndValidate(path)
+	for _, warning := range warnings {
+		slog.Warn("config: "+warning, "path", path)
+	}
+	return cfg, err
+}
+
+// LoadAndValidate loads configuration like LoadFrom and returns its validation warnings
+// instead of logging them
+func LoadAndValidate(path string) (*Config, []string, error) {
+	cfg, err := load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, cfg.Validate(), nil
+}
+
+// load reads the config file at path and merges it with defaults
+func load(path string) (*Config, error) {
 	cfg := DefaultConfig()
 
 	data, err := os.ReadFile(path)
@@ -278,9 +599,107 @@ func LoadFrom(path string) (*Config, error) {
 	if userCfg.Server.SocketPath != "" {
 		cfg.Server.SocketPath = userCfg.Server.SocketPath
 	}
+	if userCfg.Server.ListenAddr != "" {
+		cfg.Server.ListenAddr = userCfg.Server.ListenAddr
+	}
+	cfg.Server.TLS = userCfg.Server.TLS
+	if userCfg.Server.Token != "" {
+		cfg.Server.Token = userCfg.Server.Token
+	}
+	if userCfg.Server.SocketMode != "" {
+		cfg.Server.SocketMode = userCfg.Server.SocketMode
+	}
+	if userCfg.Server.SocketGroup != "" {
+		cfg.Server.SocketGroup = userCfg.Server.SocketGroup
+	}
+	if userCfg.Server.LogFile != "" {
+		cfg.Server.LogFile = userCfg.Server.LogFile
+	}
+	if userCfg.Server.LogMaxSize > 0 {
+		cfg.Server.LogMaxSize = userCfg.Server.LogMaxSize
+	}
+	if userCfg.Server.LogMaxBackups != nil {
+		cfg.Server.LogMaxBackups = userCfg.Server.LogMaxBackups
+	}
+	if userCfg.Server.Prewarm {
+		cfg.Server.Prewarm = true
+	}
+	if userCfg.Server.ReadyGate {
+		cfg.Server.ReadyGate = true
+	}
+	if userCfg.Server.ReadyGateTimeout > 0 {
+		cfg.Server.ReadyGateTimeout = userCfg.Server.ReadyGateTimeout
+	}
+	if userCfg.Server.DeleteGracePeriod > 0 {
+		cfg.Server.DeleteGracePeriod = userCfg.Server.DeleteGracePeriod
+	}
+	if userCfg.Server.StaleWatchTimeout > 0 {
+		cfg.Server.StaleWatchTimeout = userCfg.Server.StaleWatchTimeout
+	}
+	if userCfg.Server.AgeFormat != "" {
+		cfg.Server.AgeFormat = userCfg.Server.AgeFormat
+	}
+	if userCfg.Server.TimeFormat != "" {
+		cfg.Server.TimeFormat = userCfg.Server.TimeFormat
+	}
+	if userCfg.Server.Color {
+		cfg.Server.Color = true
+	}
+	if userCfg.Server.NamespaceColors {
+		cfg.Server.NamespaceColors = true
+	}
+	if userCfg.Server.HighlightMatches {
+		cfg.Server.HighlightMatches = true
+	}
+	if userCfg.Server.ContainerStates {
+		cfg.Server.ContainerStates = true
+	}
+	if userCfg.Server.GroupByNamespace {
+		cfg.Server.GroupByNamespace = true
+	}
+	if userCfg.Server.TargetedDiscovery {
+		cfg.Server.TargetedDiscovery = true
+	}
+	if userCfg.Server.DiscoveryTTL > 0 {
+		cfg.Server.DiscoveryTTL = userCfg.Server.DiscoveryTTL
+	}
+	if len(userCfg.Server.ResourcePreference) > 0 {
+		cfg.Server.ResourcePreference = userCfg.Server.ResourcePreference
+	}
+	if userCfg.Server.Impersonate.User != "" || len(userCfg.Server.Impersonate.Groups) > 0 {
+		cfg.Server.Impersonate = userCfg.Server.Impersonate
+	}
+	if userCfg.Server.QPS > 0 {
+		cfg.Server.QPS = userCfg.Server.QPS
+	}
+	if userCfg.Server.Burst > 0 {
+		cfg.Server.Burst = userCfg.Server.Burst
+	}
+	if userCfg.Server.Timeout > 0 {
+		cfg.Server.Timeout = userCfg.Server.Timeout
+	}
+	if userCfg.Server.WatchBackoff > 0 {
+		cfg.Server.WatchBackoff = userCfg.Server.WatchBackoff
+	}
+	if userCfg.Server.WatchMaxBackoff > 0 {
+		cfg.Server.WatchMaxBackoff = userCfg.Server.WatchMaxBackoff
+	}
+
+	// Per-context overrides
+	if len(userCfg.Contexts) > 0 {
+		cfg.Contexts = userCfg.Contexts
+	}
+	cfg.Completion = userCfg.Completion
 
 	// Merge resource configurations
 	for resource, resCfg := range userCfg.Resources {
+		// An entry that only sets the scope keeps the default columns
+		if len(resCfg.Columns) == 0 {
+			resCfg.Columns = cfg.Resources[resource].Columns
+		}
+		if len(resCfg.ColumnsWide) == 0 {
+			resCfg.ColumnsWide = cfg.Resources[resource].ColumnsWide
+		}
 		cfg.Resources[resource] = resCfg
 	}
 
@@ -313,8 +732,136 @@ func (c *Config) SaveTo(path string) error {
 
 // GetResourceConfig returns the configuration for a resource type, falling back to default
 func (c *Config) GetResourceConfig(resourceType string) ResourceConfig {
-	if cfg, ok := c.Resources[resourceType]; ok {
+	if cfg, ok := c.Resources[resourceType]; ok && len(cfg.Columns) > 0 {
 		return cfg
 	}
 	return c.Resources["_default"]
 }
+
+// columnWarnings returns warnings about a column set: repeated names and invalid bool labels
+func columnWarnings(resource, set string, columns []ColumnConfig) []string {
+	var warnings []string
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if _, _, ok := col.BoolLabels(); col.Bool != "" && !ok {
+			warnings = append(warnings, fmt.Sprintf("resource %s%s: column %q: bool must be \"trueLabel/falseLabel\", got %q", resource, set, col.Name, col.Bool))
+		}
+		if seen[col.Name] {
+			warnings = append(warnings, fmt.Sprintf("resource %s%s: duplicate column name %q", resource, set, col.Name))
+			continue
+		}
+		seen[col.Name] = true
+	}
+	return warnings
+}
+
+// ResourceColumns returns the columns of a resource type: its wide column set when wide
+// is requested and one is configured, otherwise its normal columns
+func (c *Config) ResourceColumns(resourceType string, wide bool) []ColumnConfig {
+	cfg := c.GetResourceConfig(resourceType)
+	if wide && len(cfg.ColumnsWide) > 0 {
+		return cfg.ColumnsWide
+	}
+	return cfg.Columns
+}
+
+// Validate returns warnings about likely mistakes in the configuration, such as
+// two columns of a resource with the same name, malformed bool labels or an invalid
+// context pattern.
+// None of them prevent loading.
+func (c *Config) Validate() []string {
+	resources := slices.Sorted(maps.Keys(c.Resources))
+
+	var warnings []string
+	for _, resource := range resources {
+		warnings = append(warnings, columnWarnings(resource, "", c.Resources[resource].Columns)...)
+		warnings = append(warnings, columnWarnings(resource, " (wide)", c.Resources[resource].ColumnsWide)...)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(c.Contexts)) {
+		var err error
+		if pattern, ok := contextPattern(key); ok {
+			_, err = regexp.Compile(pattern)
+		} else {
+			_, err = path.Match(key, "")
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("context %q: invalid pattern: %v", key, err))
+		}
+	}
+
+	for _, alias := range c.Completion.Aliases {
+		if !commandNamePattern.MatchString(alias) {
+			warnings = append(warnings, fmt.Sprintf("completion alias %q: not a command name, ignored", alias))
+		}
+	}
+	return warnings
+}
+
+// NamespacedOverride returns the configured scope of the first of names that has one.
+// The second result is false when none of them overrides the scope.
+func (c *Config) NamespacedOverride(names ...string) (bool, bool) {
+	for _, name := range names {
+		if cfg, ok := c.Resources[name]; ok && cfg.Namespaced != nil {
+			return *cfg.Namespaced, true
+		}
+	}
+	return false, false
+}
+
+// ContextConfig returns the effective settings for a context: server-wide values
+// overridden by every matching entry in Contexts. The exact name entry takes precedence
+// over patterns; among patterns, earlier keys in sorted order take precedence.
+func (c *Config) ContextConfig(contextName string) ContextConfig {
+	result := ContextConfig{
+		QPS:     c.Server.QPS,
+		Burst:   c.Server.Burst,
+		Timeout: c.Server.Timeout,
+	}
+
+	// Apply from lowest to highest precedence
+	keys := slices.Sorted(maps.Keys(c.Contexts))
+	slices.Reverse(keys)
+	for _, key := range keys {
+		if key != contextName && matchContext(key, contextName) {
+			result = result.merge(c.Contexts[key])
+		}
+	}
+	if exact, ok := c.Contexts[contextName]; ok {
+		result = result.merge(exact)
+	}
+	return result
+}
+
+// merge returns cfg with the settings of override that are set
+func (cfg ContextConfig) merge(override ContextConfig) ContextConfig {
+	if override.QPS > 0 {
+		cfg.QPS = override.QPS
+	}
+	if override.Burst > 0 {
+		cfg.Burst = override.Burst
+	}
+	if override.Timeout > 0 {
+		cfg.Timeout = override.Timeout
+	}
+	return cfg
+}
+
+// contextPattern returns the regular expression of a "/regex/" context key
+func contextPattern(key string) (string, bool) {
+	if len(key) >= 2 && strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/") {
+		return key[1 : len(key)-1], true
+	}
+	return "", false
+}
+
+// matchContext reports whether a Contexts key (glob or "/regex/") matches a context name.
+// Invalid patterns never match; Validate reports them.
+func matchContext(key, contextName string) bool {
+	if pattern, ok := contextPattern(key); ok {
+		re, err := regexp.Compile(pattern)
+		return err == nil && re.MatchString(contextName)
+	}
+	matched, _ := path.Match(key, contextName)
+	return matched
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -31,9 +34,11 @@ func TestDefaultConfig(t *testing.T) {
 		"persistentvolumes",
 		"ingresses",
 		"statefulsets",
+		"replicasets",
 		"daemonsets",
 		"jobs",
 		"cronjobs",
+		"horizontalpodautoscalers",
 		"_default",
 	}
 
@@ -152,6 +157,51 @@ func TestDefaultConfig_Deployments(t *testing.T) {
 	}
 }
 
+func TestDefaultConfig_ReplicaSets(t *testing.T) {
+	cfg := DefaultConfig()
+	rsCfg := cfg.Resources["replicasets"]
+
+	expected := map[string]string{
+		"DESIRED": ".spec.replicas",
+		"CURRENT": ".status.replicas",
+		"READY":   ".status.readyReplicas",
+	}
+	for _, col := range rsCfg.Columns {
+		if want, ok := expected[col.Name]; ok {
+			if col.Field != want {
+				t.Errorf("%s field = %s, want %s", col.Name, col.Field, want)
+			}
+			delete(expected, col.Name)
+		}
+	}
+	for name := range expected {
+		t.Errorf("ReplicaSets config should have %s column", name)
+	}
+}
+
+func TestDefaultConfig_HorizontalPodAutoscalers(t *testing.T) {
+	cfg := DefaultConfig()
+	hpaCfg := cfg.Resources["horizontalpodautoscalers"]
+
+	expected := map[string]string{
+		"MIN":      ".spec.minReplicas",
+		"MAX":      ".spec.maxReplicas",
+		"REPLICAS": ".status.currentReplicas",
+		"TARGETS":  "_hpaTargets",
+	}
+	for _, col := range hpaCfg.Columns {
+		if want, ok := expected[col.Name]; ok {
+			if col.Field != want {
+				t.Errorf("%s field = %s, want %s", col.Name, col.Field, want)
+			}
+			delete(expected, col.Name)
+		}
+	}
+	for name := range expected {
+		t.Errorf("HorizontalPodAutoscalers config should have %s column", name)
+	}
+}
+
 func TestDefaultConfig_Ingresses(t *testing.T) {
 	cfg := DefaultConfig()
 	ingressCfg := cfg.Resources["ingresses"]
@@ -385,6 +435,247 @@ resources:
 	}
 }
 
+func TestLoadFrom_LogFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+server:
+  logFile: /var/log/kfzf.log
+  logMaxBackups: 7
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp config: %v", err)
+	}
+
+	cfg, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Server.LogFile != "/var/log/kfzf.log" {
+		t.Errorf("LogFile = %q, want /var/log/kfzf.log", cfg.Server.LogFile)
+	}
+	if cfg.Server.LogMaxSize != 10 {
+		t.Errorf("LogMaxSize = %d, want default 10", cfg.Server.LogMaxSize)
+	}
+	if cfg.Server.LogBackups() != 7 {
+		t.Errorf("LogBackups() = %d, want 7", cfg.Server.LogBackups())
+	}
+}
+
+func TestLoadFrom_LogMaxBackups(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"unset", "server:\n  logFile: /var/log/kfzf.log\n", 3},
+		{"zero", "server:\n  logMaxBackups: 0\n", 0},
+		{"set", "server:\n  logMaxBackups: 5\n", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write temp config: %v", err)
+			}
+			cfg, err := LoadFrom(configPath)
+			if err != nil {
+				t.Fatalf("LoadFrom() error = %v", err)
+			}
+			if got := cfg.Server.LogBackups(); got != tt.want {
+				t.Errorf("LogBackups() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSocketFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{"", DefaultSocketMode, false},
+		{"0660", 0660, false},
+		{"640", 0640, false},
+		{"0666", 0, true},
+		{"0777", 0, true},
+		{"1777", 0, true},
+		{"rw-rw----", 0, true},
+		{"0680", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ServerConfig{SocketMode: tt.mode}.SocketFileMode()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SocketFileMode(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SocketFileMode(%q) = %o, want %o", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFrom_NamespacedOverride(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+resources:
+  deployments:
+    namespaced: true
+  widgets.example.com:
+    namespaced: false
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp config: %v", err)
+	}
+
+	cfg, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	if namespaced, ok := cfg.NamespacedOverride("widgets", "widgets.example.com"); !ok || namespaced {
+		t.Errorf("NamespacedOverride(widgets) = %t, %t, want false, true", namespaced, ok)
+	}
+	if _, ok := cfg.NamespacedOverride("pods"); ok {
+		t.Error("pods should have no scope override")
+	}
+	// A scope-only entry keeps the built-in columns
+	if got, want := len(cfg.Resources["deployments"].Columns), len(DefaultConfig().Resources["deployments"].Columns); got != want {
+		t.Errorf("deployments has %d columns, want the %d default ones", got, want)
+	}
+}
+
+func TestValidate_DuplicateColumns(t *testing.T) {
+	if warnings := DefaultConfig().Validate(); len(warnings) != 0 {
+		t.Errorf("DefaultConfig().Validate() = %v, want no warnings", warnings)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+resources:
+  pods:
+    columns:
+      - name: NAME
+        field: .metadata.name
+      - name: STATUS
+        field: .status.phase
+      - name: NAME
+        field: .metadata.name
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp config: %v", err)
+	}
+
+	cfg, warnings, err := LoadAndValidate(configPath)
+	if err != nil {
+		t.Fatalf("LoadAndValidate() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "pods") || !strings.Contains(warnings[0], `"NAME"`) {
+		t.Errorf("warnings = %v, want one duplicate NAME warning for pods", warnings)
+	}
+	// Duplicates are reported, not removed
+	if len(cfg.Resources["pods"].Columns) != 3 {
+		t.Errorf("pods has %d columns, want 3", len(cfg.Resources["pods"].Columns))
+	}
+
+	if _, err := LoadFrom(configPath); err != nil {
+		t.Errorf("LoadFrom() should not fail on warnings: %v", err)
+	}
+}
+
+func TestLoadFrom_ReadyGate(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	if err := os.WriteFile(configPath, []byte("server:\n  readyGate: true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if !cfg.Server.ReadyGate {
+		t.Error("ReadyGate should be enabled")
+	}
+	if cfg.Server.ReadyGateTimeout != 10*time.Second {
+		t.Errorf("ReadyGateTimeout = %v, want default 10s", cfg.Server.ReadyGateTimeout)
+	}
+}
+
+func TestLoadFrom_WatchBackoff(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	if err := os.WriteFile(configPath, []byte("server:\n  watchMaxBackoff: 30s\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Server.WatchBackoff != time.Second {
+		t.Errorf("WatchBackoff = %v, want default 1s", cfg.Server.WatchBackoff)
+	}
+	if cfg.Server.WatchMaxBackoff != 30*time.Second {
+		t.Errorf("WatchMaxBackoff = %v, want 30s", cfg.Server.WatchMaxBackoff)
+	}
+}
+
+func TestResourceColumns_Wide(t *testing.T) {
+	cfg := DefaultConfig()
+
+	normal := cfg.ResourceColumns("pods", false)
+	wide := cfg.ResourceColumns("pods", true)
+	if len(wide) <= len(normal) {
+		t.Fatalf("pods wide columns = %d, want more than %d", len(wide), len(normal))
+	}
+	for _, name := range []string{"RESTARTS", "IP", "NODE"} {
+		if !slices.ContainsFunc(wide, func(col ColumnConfig) bool { return col.Name == name }) {
+			t.Errorf("pods wide columns missing %s", name)
+		}
+	}
+
+	// Without a wide set the normal columns are used
+	if got := cfg.ResourceColumns("services", true); len(got) != len(cfg.ResourceColumns("services", false)) {
+		t.Errorf("services wide columns = %d, want the normal %d", len(got), len(cfg.ResourceColumns("services", false)))
+	}
+}
+
+func TestValidate_BoolLabels(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Resources["cronjobs"] = ResourceConfig{Columns: []ColumnConfig{
+		{Name: "NAME", Field: ".metadata.name"},
+		{Name: "SUSPEND", Field: ".spec.suspend", Bool: "Suspended"},
+	}}
+
+	warnings := cfg.Validate()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "SUSPEND") {
+		t.Errorf("Validate() = %v, want one bool labels warning for SUSPEND", warnings)
+	}
+}
+
+func TestServerConfig_SetInstance(t *testing.T) {
+	server := ServerConfig{SocketPath: "/tmp/kfzf.sock", LogFile: "/var/log/kfzf.log"}
+	if err := server.SetInstance(""); err != nil || server.SocketPath != "/tmp/kfzf.sock" {
+		t.Errorf("SetInstance(\"\") = %v, socket %s, want default instance unchanged", err, server.SocketPath)
+	}
+
+	if err := server.SetInstance("prod"); err != nil {
+		t.Fatalf("SetInstance(prod) error = %v", err)
+	}
+	if server.Instance != "prod" || server.SocketPath != "/tmp/kfzf-prod.sock" || server.LogFile != "/var/log/kfzf-prod.log" {
+		t.Errorf("SetInstance(prod) = instance %q, socket %s, log %s", server.Instance, server.SocketPath, server.LogFile)
+	}
+
+	for _, name := range []string{"../etc", "a b", "-x"} {
+		server := ServerConfig{SocketPath: "/tmp/kfzf.sock"}
+		if err := server.SetInstance(name); err == nil {
+			t.Errorf("SetInstance(%q) should fail", name)
+		}
+	}
+}
+
 func TestLoadFrom_Invalid(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.yaml")
@@ -428,6 +719,81 @@ func TestSaveTo(t *testing.T) {
 	}
 }
 
+func TestContextConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+server:
+  qps: 20
+  timeout: 10s
+contexts:
+  prod-*:
+    qps: 5
+    burst: 10
+  prod-eu:
+    burst: 200
+    timeout: 30s
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp config: %v", err)
+	}
+
+	cfg, err := LoadFrom(configPath)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	tests := []struct {
+		context string
+		want    ContextConfig
+	}{
+		{"dev", ContextConfig{QPS: 20, Burst: 100, Timeout: 10 * time.Second}},
+		{"prod-us", ContextConfig{QPS: 5, Burst: 10, Timeout: 10 * time.Second}},
+		// The exact entry is merged over the glob
+		{"prod-eu", ContextConfig{QPS: 5, Burst: 200, Timeout: 30 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			got := cfg.ContextConfig(tt.context)
+			if got != tt.want {
+				t.Errorf("ContextConfig(%s) = %+v, want %+v", tt.context, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextConfig_Patterns(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Contexts = map[string]ContextConfig{
+		"*-eu":            {Timeout: 5 * time.Second},
+		"/^prod-[a-z]+$/": {QPS: 5, Burst: 10},
+		"prod-*":          {QPS: 8},
+		"/^staging-(eu/":  {QPS: 1},
+	}
+
+	// "*-eu" sorts before "/^prod..." and "prod-*", so its settings win; the others
+	// fill in what it leaves unset, with the regex taking precedence over "prod-*"
+	got := cfg.ContextConfig("prod-eu")
+	want := ContextConfig{QPS: 5, Burst: 10, Timeout: 5 * time.Second}
+	if got != want {
+		t.Errorf("ContextConfig(prod-eu) = %+v, want %+v", got, want)
+	}
+
+	// The invalid regex never matches
+	got = cfg.ContextConfig("staging-eu")
+	want = ContextConfig{QPS: 50, Burst: 100, Timeout: 5 * time.Second}
+	if got != want {
+		t.Errorf("ContextConfig(staging-eu) = %+v, want %+v", got, want)
+	}
+
+	warnings := cfg.Validate()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "/^staging-(eu/") {
+		t.Errorf("Validate() = %v, want one invalid pattern warning", warnings)
+	}
+}
+
 func TestColumnConfig(t *testing.T) {
 	col := ColumnConfig{
 		Name:  "TEST",
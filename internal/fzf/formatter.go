@@ -1,20 +1,46 @@
 package fzf
 
 import (
+	"bytes"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/pslijkhuis/kfzf/internal/config"
 	"github.com/pslijkhuis/kfzf/internal/store"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
 )
 
+// jsonPathPrefix marks a field as a full JSONPath expression (e.g. "jsonpath:{.spec.foo}")
+const jsonPathPrefix = "jsonpath:"
+
+// percentPrefix marks a ratio field rendered as a percentage (e.g. "percent:.status.readyReplicas/.spec.replicas")
+const percentPrefix = "percent:"
+
+// absTimePrefix marks a timestamp field rendered as an absolute local time instead of an age
+// (e.g. "abs:.metadata.creationTimestamp")
+const absTimePrefix = "abs:"
+
+// quantityPrefix marks a field whose value is a resource quantity to normalize (e.g. "quantity:.spec.capacity.storage")
+const quantityPrefix = "quantity:"
+
 // ANSI color codes
 const (
 	colorReset   = "\033[0m"
 	colorBold    = "\033[1m"
 	colorDim     = "\033[2m"
+	colorNormal  = "\033[22m"
 	colorCyan    = "\033[36m"
 	colorGreen   = "\033[32m"
 	colorYellow  = "\033[33m"
@@ -23,64 +49,370 @@ const (
 	colorMagenta = "\033[35m"
 )
 
+// namespacePalette holds the colors namespaces are hashed onto when namespace colors are enabled
+var namespacePalette = []string{
+	colorBlue,
+	colorMagenta,
+	colorCyan,
+	colorGreen,
+	colorYellow,
+	"\033[94m",
+	"\033[95m",
+	"\033[96m",
+}
+
 // Formatter formats resources for fzf display
 type Formatter struct {
 	config *config.Config
+	// lookup resolves other cached resources for cross-resource fields (nil if unavailable)
+	lookup Lookup
+	// wide selects the wide column set of resources that configure one
+	wide bool
+	// color adds ANSI colors to the columns, for display by fzf --ansi
+	color bool
+	// highlight is the query whose matched characters are bolded in the name column (with color)
+	highlight string
 }
 
+// Lookup returns the cached resources of a GVR in a namespace
+type Lookup func(gvr schema.GroupVersionResource, namespace string) []*store.Resource
+
+// EndpointSlicesGVR is the GVR of the EndpointSlices backing a service, which replace the
+// deprecated core/v1 Endpoints
+var EndpointSlicesGVR = schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}
+
+// serviceNameLabel names the service an EndpointSlice belongs to
+const serviceNameLabel = "kubernetes.io/service-name"
+
 // NewFormatter creates a new formatter
 func NewFormatter(cfg *config.Config) *Formatter {
 	return &Formatter{config: cfg}
 }
 
-// Format formats a list of resources for fzf output (plain text, colors added in shell)
+// WithLookup returns a copy of the formatter that resolves cross-resource fields
+// such as _svcEndpoints with lookup
+func (f *Formatter) WithLookup(lookup Lookup) *Formatter {
+	c := *f
+	c.lookup = lookup
+	return &c
+}
+
+// WithWide returns a copy of the formatter that uses the wide column set (columnsWide)
+// of resources that configure one
+func (f *Formatter) WithWide(wide bool) *Formatter {
+	c := *f
+	c.wide = wide
+	return &c
+}
+
+// WithColor returns a copy of the formatter that colors names, namespaces, statuses and
+// other columns with ANSI codes. Only for output displayed by fzf with --ansi.
+func (f *Formatter) WithColor(color bool) *Formatter {
+	c := *f
+	c.color = color
+	return &c
+}
+
+// WithHighlight returns a copy of the formatter that bolds the characters of the name
+// column matched by query, showing why a resource matched a server-side query. Only
+// applies with WithColor; an empty query disables it.
+func (f *Formatter) WithHighlight(query string) *Formatter {
+	c := *f
+	c.highlight = query
+	return &c
+}
+
+// columns returns the columns to render for a resource type
+func (f *Formatter) columns(resourceType string) []config.ColumnConfig {
+	return f.config.ResourceColumns(resourceType, f.wide)
+}
+
+// Format formats a list of resources for fzf output (plain text unless WithColor is set)
 func (f *Formatter) Format(resources []*store.Resource, resourceType string) string {
 	if len(resources) == 0 {
 		return ""
 	}
 
-	resCfg := f.config.GetResourceConfig(resourceType)
-	columns := resCfg.Columns
+	columns := f.columns(resourceType)
 
 	var buf strings.Builder
-	buf.Grow(len(resources) * 100)
+	buf.Grow(len(resources) * rowSize(columns))
+	f.writeRows(&buf, resources, columns)
+	return buf.String()
+}
 
+// GroupHeaderPrefix starts the header line of each namespace group in grouped output.
+// Resource names can't start with it, so selections of header lines can be ignored.
+const GroupHeaderPrefix = "# "
+
+// FormatGrouped formats resources like Format, clustered by namespace with a dim header
+// line before each group. Resources keep their relative order within a namespace.
+func (f *Formatter) FormatGrouped(resources []*store.Resource, resourceType string) string {
+	if len(resources) == 0 {
+		return ""
+	}
+
+	columns := f.columns(resourceType)
+
+	grouped := slices.Clone(resources)
+	slices.SortStableFunc(grouped, func(a, b *store.Resource) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+
+	var buf strings.Builder
+	buf.Grow(len(grouped) * rowSize(columns))
+	for i, res := range grouped {
+		if i == 0 || res.Namespace != grouped[i-1].Namespace {
+			if i > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(colorDim + GroupHeaderPrefix + sanitizeField(res.Namespace) + colorReset)
+		}
+		buf.WriteByte('\n')
+		f.writeRow(&buf, res, columns)
+	}
+	return buf.String()
+}
+
+// IsGroupHeader reports whether a selected line is a group header of grouped output,
+// with or without its color codes
+func IsGroupHeader(line string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(line, colorDim), GroupHeaderPrefix)
+}
+
+// truncatedPrefix starts a name shortened by truncate: left. Names can't start with a dot,
+// so it can't be a name's own prefix.
+const truncatedPrefix = "..."
+
+// SelectedName returns the exact resource name of a line selected from the output: the
+// first field without colors, or the full name in the last field when the name column was
+// truncated (truncate: left). Group headers and empty lines return "".
+func SelectedName(line string) string {
+	line = strings.TrimRight(StripANSI(line), "\r\n")
+	if line == "" || IsGroupHeader(line) {
+		return ""
+	}
+	name, _, _ := strings.Cut(line, "\t")
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, truncatedPrefix) {
+		name = line[strings.LastIndexByte(line, '\t')+1:]
+	}
+	return UnescapeName(name)
+}
+
+// DisplayFields returns how many tab-separated fields of the output to show, hiding the
+// full names appended to rows with a truncated name, or 0 when no name was truncated
+func DisplayFields(output string) int {
+	fields := 0
+	truncated := false
+	for _, line := range strings.Split(output, "\n") {
+		line = StripANSI(line)
+		if line == "" || IsGroupHeader(line) {
+			continue
+		}
+		n := strings.Count(line, "\t") + 1
+		if strings.HasPrefix(line, truncatedPrefix) {
+			truncated = true
+			n--
+		}
+		if fields == 0 || n < fields {
+			fields = n
+		}
+	}
+	if !truncated {
+		return 0
+	}
+	return fields
+}
+
+// StripANSI removes ANSI color codes, e.g. from a line selected from colored output
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\033[") {
+		return s
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for {
+		start := strings.Index(s, "\033[")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], 'm')
+		if end < 0 {
+			break
+		}
+		buf.WriteString(s[:start])
+		s = s[start+end+1:]
+	}
+	buf.WriteString(s)
+	return buf.String()
+}
+
+// FormatTo writes the same output as Format to buf, so callers can reuse buffers
+func (f *Formatter) FormatTo(buf *bytes.Buffer, resources []*store.Resource, resourceType string) {
+	if len(resources) == 0 {
+		return
+	}
+
+	columns := f.columns(resourceType)
+	buf.Grow(len(resources) * rowSize(columns))
+	f.writeRows(buf, resources, columns)
+}
+
+// FormatNamesTo writes just the resource names to buf, one per line, without columns or padding
+func (f *Formatter) FormatNamesTo(buf *bytes.Buffer, resources []*store.Resource) {
 	for i, res := range resources {
 		if i > 0 {
 			buf.WriteByte('\n')
 		}
-		for j, col := range columns {
-			if j > 0 {
-				buf.WriteByte('\t')
-			}
-			value := f.extractField(res.Object, col.Field, res.CreationTimestamp)
-			if col.Width > 0 {
-				if col.Field == ".metadata.name" || col.Field == ".metadata.namespace" {
-					// Pad name/namespace but never truncate (needed for completion)
-					value = f.padOnly(value, col.Width)
-				} else {
-					value = f.truncateOrPad(value, col.Width)
+		buf.WriteString(res.Name)
+	}
+}
+
+// rowWriter is implemented by both strings.Builder and bytes.Buffer
+type rowWriter interface {
+	io.StringWriter
+	io.ByteWriter
+}
+
+// writeRows writes newline-separated rows for resources
+func (f *Formatter) writeRows(buf rowWriter, resources []*store.Resource, columns []config.ColumnConfig) {
+	for i, res := range resources {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		f.writeRow(buf, res, columns)
+	}
+}
+
+// rowSize estimates the bytes of one formatted row, to size the output buffer up front
+func rowSize(columns []config.ColumnConfig) int {
+	size := 0
+	for _, col := range columns {
+		width := col.Width
+		if width == 0 {
+			width = 20
+		}
+		size += width + 1
+	}
+	return size
+}
+
+// writeRow writes one resource's tab-separated columns to buf, padding and
+// truncating in place to avoid building intermediate strings
+func (f *Formatter) writeRow(buf rowWriter, res *store.Resource, columns []config.ColumnConfig) {
+	fullName := ""
+	for j, col := range columns {
+		if j > 0 {
+			buf.WriteByte('\t')
+		}
+		value := sanitizeColumn(col.Field, f.extractColumn(res, col))
+		// Colors wrap the padding too, so the value is still followed by whitespace
+		color := ""
+		if f.color {
+			color = f.columnColor(value, col.Name, j)
+			if col.Field == ".metadata.name" && f.highlight != "" && (col.Width <= 0 || utf8.RuneCountInString(value) <= col.Width) {
+				// Only the matched characters are bold
+				buf.WriteString(colorCyan)
+				writeHighlighted(buf, value, MatchPositions(f.highlight, value))
+				if col.Width > 0 {
+					writePadding(buf, col.Width-utf8.RuneCountInString(value))
 				}
+				buf.WriteString(colorReset)
+				continue
 			}
+			buf.WriteString(color)
+		}
+		switch n := utf8.RuneCountInString(value); {
+		case col.Width <= 0:
+			buf.WriteString(value)
+		case n <= col.Width:
 			buf.WriteString(value)
+			writePadding(buf, col.Width-n)
+		case col.Field == ".metadata.name" && col.Truncate == config.TruncateLeft && col.Width > 3:
+			// Show the tail only; the full name is appended as the last field for selection
+			buf.WriteString(f.truncateOrPad(value, col.Width, config.TruncateLeft))
+			fullName = value
+		case col.Field == ".metadata.name" || col.Field == ".metadata.namespace":
+			// Pad name/namespace but never truncate (needed for completion)
+			buf.WriteString(value)
+		default:
+			buf.WriteString(f.truncateOrPad(value, col.Width, col.Truncate))
 		}
+		if color != "" {
+			buf.WriteString(colorReset)
+		}
+	}
+	if fullName != "" {
+		buf.WriteByte('\t')
+		buf.WriteString(fullName)
 	}
+}
 
-	return buf.String()
+// writeHighlighted writes value with the runs of bytes at positions (ascending offsets)
+// in bold, ending bold with normal intensity so the surrounding color is kept
+func writeHighlighted(buf rowWriter, value string, positions []int) {
+	last := 0
+	for i := 0; i < len(positions); {
+		j := i + 1
+		for j < len(positions) && positions[j] == positions[j-1]+1 {
+			j++
+		}
+		start, end := positions[i], positions[j-1]+1
+		buf.WriteString(value[last:start])
+		buf.WriteString(colorBold)
+		buf.WriteString(value[start:end])
+		buf.WriteString(colorNormal)
+		last = end
+		i = j
+	}
+	buf.WriteString(value[last:])
+}
+
+// padding is a run of spaces sliced by writePadding
+const padding = "                                                                "
+
+// writePadding writes n spaces to buf
+func writePadding(buf rowWriter, n int) {
+	for n > len(padding) {
+		buf.WriteString(padding)
+		n -= len(padding)
+	}
+	buf.WriteString(padding[:n])
+}
+
+// namespaceColor picks a palette color for a namespace, always the same for the same name
+func namespaceColor(namespace string) string {
+	h := fnv.New32a()
+	h.Write([]byte(namespace))
+	return namespacePalette[h.Sum32()%uint32(len(namespacePalette))]
 }
 
 // colorize applies ANSI colors based on column name and value
 func (f *Formatter) colorize(value, colName string, colIndex int) string {
+	color := f.columnColor(value, colName, colIndex)
+	if color == "" {
+		return value
+	}
+	return color + value + colorReset
+}
+
+// columnColor returns the ANSI color for a column value, or "" to leave it uncolored
+func (f *Formatter) columnColor(value, colName string, colIndex int) string {
 	trimmed := strings.TrimSpace(value)
 
 	// First column (usually NAME) - cyan and bold
 	if colIndex == 0 {
-		return colorCyan + colorBold + value + colorReset
+		return colorCyan + colorBold
 	}
 
-	// Namespace column - blue
+	// Namespace column - blue, or a stable per-namespace color
 	if strings.EqualFold(colName, "NAMESPACE") {
-		return colorBlue + value + colorReset
+		if f.config.Server.NamespaceColors {
+			return namespaceColor(trimmed)
+		}
+		return colorBlue
 	}
 
 	// Status/Phase/Health column - color based on value
@@ -88,15 +420,15 @@ func (f *Formatter) colorize(value, colName string, colIndex int) string {
 	if colUpper == "STATUS" || colUpper == "PHASE" || colUpper == "HEALTH" {
 		switch strings.ToLower(trimmed) {
 		case "running", "active", "ready", "true", "bound", "available", "healthy", "provisioned":
-			return colorGreen + value + colorReset
+			return colorGreen
 		case "pending", "waiting", "unknown", "terminating", "progressing", "suspended", "missing", "degraded":
-			return colorYellow + value + colorReset
+			return colorYellow
 		case "failed", "error", "crashloopbackoff", "imagepullbackoff", "false", "notready":
-			return colorRed + value + colorReset
+			return colorRed
 		case "succeeded", "completed":
-			return colorGreen + value + colorReset
+			return colorGreen
 		default:
-			return colorYellow + value + colorReset
+			return colorYellow
 		}
 	}
 
@@ -104,13 +436,13 @@ func (f *Formatter) colorize(value, colName string, colIndex int) string {
 	if colUpper == "SYNC" {
 		switch strings.ToLower(trimmed) {
 		case "synced":
-			return colorGreen + value + colorReset
+			return colorGreen
 		case "outofsync":
-			return colorYellow + value + colorReset
+			return colorYellow
 		case "unknown":
-			return colorRed + value + colorReset
+			return colorRed
 		default:
-			return colorYellow + value + colorReset
+			return colorYellow
 		}
 	}
 
@@ -119,20 +451,56 @@ func (f *Formatter) colorize(value, colName string, colIndex int) string {
 		if strings.Contains(trimmed, "/") {
 			parts := strings.Split(trimmed, "/")
 			if len(parts) == 2 && parts[0] == parts[1] && parts[0] != "0" && parts[0] != "" {
-				return colorGreen + value + colorReset
+				return colorGreen
 			} else if parts[0] == "0" || parts[0] == "" {
-				return colorRed + value + colorReset
+				return colorRed
+			}
+			return colorYellow
+		}
+	}
+
+	// Job completions - red once any pod has failed, otherwise like READY
+	if colUpper == "COMPLETIONS" {
+		switch {
+		case strings.HasSuffix(trimmed, "failed)"):
+			return colorRed
+		case strings.Contains(trimmed, "/"):
+			done, total, _ := strings.Cut(trimmed, "/")
+			if done == total {
+				return colorGreen
 			}
-			return colorYellow + value + colorReset
+			return colorYellow
 		}
 	}
 
+	// Percentage values (percent: fields) - green near 100%, red when low
+	if pct, ok := strings.CutSuffix(trimmed, "%"); ok {
+		if n, err := strconv.ParseFloat(pct, 64); err == nil {
+			switch {
+			case n >= 90:
+				return colorGreen
+			case n >= 50:
+				return colorYellow
+			default:
+				return colorRed
+			}
+		}
+	}
+
+	// Check marks, e.g. from bool: "✓/✗" columns
+	switch trimmed {
+	case "✓":
+		return colorGreen
+	case "✗":
+		return colorRed
+	}
+
 	// Age column - dim
 	if colUpper == "AGE" {
-		return colorDim + value + colorReset
+		return colorDim
 	}
 
-	return value
+	return ""
 }
 
 // FormatWithHeader formats resources with a header line
@@ -141,11 +509,10 @@ func (f *Formatter) FormatWithHeader(resources []*store.Resource, resourceType s
 		return ""
 	}
 
-	resCfg := f.config.GetResourceConfig(resourceType)
-	columns := resCfg.Columns
+	columns := f.columns(resourceType)
 
 	var buf strings.Builder
-	buf.Grow((len(resources) + 1) * 100)
+	buf.Grow((len(resources) + 1) * rowSize(columns))
 
 	// Build header
 	for j, col := range columns {
@@ -154,7 +521,7 @@ func (f *Formatter) FormatWithHeader(resources []*store.Resource, resourceType s
 		}
 		header := col.Name
 		if col.Width > 0 {
-			header = f.truncateOrPad(header, col.Width)
+			header = f.truncateOrPad(header, col.Width, "")
 		}
 		buf.WriteString(header)
 	}
@@ -162,24 +529,118 @@ func (f *Formatter) FormatWithHeader(resources []*store.Resource, resourceType s
 	// Build data lines
 	for _, res := range resources {
 		buf.WriteByte('\n')
-		for j, col := range columns {
-			if j > 0 {
+		f.writeRow(&buf, res, columns)
+	}
+
+	return buf.String()
+}
+
+// Records returns a header row of column names followed by one row of unpadded,
+// untruncated column values per resource, for machine-readable output
+func (f *Formatter) Records(resources []*store.Resource, resourceType string) [][]string {
+	columns := f.columns(resourceType)
+
+	records := make([][]string, 0, len(resources)+1)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	records = append(records, header)
+
+	for _, res := range resources {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = f.extractColumn(res, col)
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+// WriteTSV writes records as tab-separated lines. Tabs, newlines and control characters
+// in values are replaced like in the padded output, so no quoting is needed.
+func WriteTSV(buf *bytes.Buffer, records [][]string) {
+	for _, record := range records {
+		for i, value := range record {
+			if i > 0 {
 				buf.WriteByte('\t')
 			}
-			value := f.extractField(res.Object, col.Field, res.CreationTimestamp)
-			if col.Width > 0 {
-				if col.Field == ".metadata.name" || col.Field == ".metadata.namespace" {
-					// Pad name/namespace but never truncate (needed for completion)
-					value = f.padOnly(value, col.Width)
-				} else {
-					value = f.truncateOrPad(value, col.Width)
-				}
-			}
-			buf.WriteString(value)
+			buf.WriteString(sanitizeField(value))
 		}
+		buf.WriteByte('\n')
 	}
+}
 
-	return buf.String()
+// TemplateData is the data a completion output template is executed with, per resource
+type TemplateData struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	// Columns holds the resource type's configured column values by column name, unpadded
+	Columns map[string]string
+	// Object is the cached (pruned) object
+	Object map[string]interface{}
+}
+
+// FormatTemplateTo executes tmpl for each resource, writing one line per resource.
+// Newlines in the template output become spaces; tabs are kept as column separators.
+func (f *Formatter) FormatTemplateTo(buf *bytes.Buffer, resources []*store.Resource, resourceType string, tmpl *template.Template) error {
+	columns := f.columns(resourceType)
+
+	var line strings.Builder
+	for i, res := range resources {
+		data := TemplateData{
+			Name:      res.Name,
+			Namespace: res.Namespace,
+			Columns:   make(map[string]string, len(columns)),
+		}
+		if res.Object != nil {
+			data.Labels = res.Object.GetLabels()
+			data.Annotations = res.Object.GetAnnotations()
+			data.Object = res.Object.Object
+		}
+		for _, col := range columns {
+			data.Columns[col.Name] = f.extractColumn(res, col)
+		}
+
+		line.Reset()
+		if err := tmpl.Execute(&line, data); err != nil {
+			return fmt.Errorf("%s: %w", res.Name, err)
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		templateNewlines.WriteString(buf, line.String())
+	}
+	return nil
+}
+
+// templateNewlines replaces line breaks in template output
+var templateNewlines = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// extractColumn extracts a column's value, applying the column's bool labels, or its
+// array join options to simple [*] fields
+func (f *Formatter) extractColumn(res *store.Resource, col config.ColumnConfig) string {
+	if trueLabel, falseLabel, ok := col.BoolLabels(); ok {
+		switch value := f.extractField(res.Object, col.Field, res.CreationTimestamp); value {
+		case "true":
+			return trueLabel
+		case "false", "":
+			return falseLabel
+		default:
+			return value
+		}
+	}
+	if (col.Separator != "" || col.MaxItems > 0) && res.Object != nil &&
+		strings.HasPrefix(col.Field, ".") && strings.Contains(col.Field, "[*]") && !strings.Contains(col.Field, "[?(") {
+		sep := col.Separator
+		if sep == "" {
+			sep = ","
+		}
+		return f.extractArrayField(res.Object.Object, col.Field, sep, col.MaxItems)
+	}
+	return f.extractField(res.Object, col.Field, res.CreationTimestamp)
 }
 
 // extractField extracts a field value from an unstructured object
@@ -211,6 +672,45 @@ func (f *Formatter) extractField(obj *unstructured.Unstructured, field string, c
 		return f.extractCertReady(obj.Object)
 	case "_issuerReady":
 		return f.extractIssuerReady(obj.Object)
+	case "_dsReady":
+		return f.extractDaemonSetReady(obj.Object)
+	case "_jobCompletions":
+		return f.extractJobCompletions(obj.Object)
+	case "_jobDuration":
+		return f.extractJobDuration(obj.Object)
+	case "_hpaTargets":
+		return f.extractHPATargets(obj.Object)
+	case "_svcEndpoints":
+		return f.extractServiceEndpoints(obj)
+	case "_podRestarts":
+		return f.extractPodRestarts(obj.Object)
+	}
+
+	if ratio, ok := strings.CutPrefix(field, percentPrefix); ok {
+		return f.extractPercent(obj.Object, ratio)
+	}
+	if inner, ok := strings.CutPrefix(field, quantityPrefix); ok {
+		return formatQuantities(f.extractField(obj, inner, creationTime))
+	}
+	if inner, ok := strings.CutPrefix(field, absTimePrefix); ok {
+		if inner == ".metadata.creationTimestamp" {
+			return f.formatTime(creationTime)
+		}
+		value := valueString(f.getNestedValue(obj.Object, inner))
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return value
+		}
+		return f.formatTime(t)
+	}
+
+	// Full JSONPath is opt-in since it is much slower than the custom paths below.
+	// The braced kubectl custom-columns form ("{.spec.foo}") is evaluated the same way.
+	if expr, ok := strings.CutPrefix(field, jsonPathPrefix); ok {
+		return f.extractJSONPath(obj.Object, expr)
+	}
+	if strings.HasPrefix(field, "{") {
+		return f.extractJSONPath(obj.Object, field)
 	}
 
 	// Handle ratio fields like ".status.readyReplicas/.spec.replicas"
@@ -232,19 +732,105 @@ func (f *Formatter) extractField(obj *unstructured.Unstructured, field string, c
 
 	// Handle simple array access like ".spec.rules[*].host"
 	if strings.Contains(field, "[*]") {
-		return f.extractArrayField(obj.Object, field)
+		return f.extractArrayField(obj.Object, field, ",", 0)
 	}
 
-	value := f.getNestedValue(obj.Object, field)
-	if value == nil {
+	return valueString(f.getNestedValue(obj.Object, field))
+}
+
+// valueString converts a scalar from an unstructured object to a string,
+// avoiding fmt for the common types
+func valueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
 		return ""
-	}
-	if s, ok := value.(string); ok {
-		return s
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
 	}
 	return fmt.Sprintf("%v", value)
 }
 
+// extractPercent renders a ratio field like ".status.readyReplicas/.spec.replicas" as a
+// percentage. A missing numerator counts as 0 (e.g. readyReplicas is omitted when none are
+// ready); a missing or zero denominator renders "-".
+func (f *Formatter) extractPercent(obj map[string]interface{}, ratio string) string {
+	numPath, denomPath, ok := strings.Cut(ratio, "/")
+	if !ok {
+		return ""
+	}
+	denom, ok := toFloat(f.getNestedValue(obj, denomPath))
+	if !ok || denom == 0 {
+		return "-"
+	}
+	num, _ := toFloat(f.getNestedValue(obj, numPath))
+	return fmt.Sprintf("%.0f%%", num/denom*100)
+}
+
+// toFloat converts a numeric value from an unstructured object to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// binarySuffixes are the suffixes formatQuantity renders large quantities with, smallest first
+var binarySuffixes = []string{"Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+
+// formatQuantities normalizes each comma-separated quantity in value
+func formatQuantities(value string) string {
+	if value == "" {
+		return ""
+	}
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		parts[i] = formatQuantity(part)
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatQuantity renders a resource quantity in a normalized form, so values written
+// differently compare at a glance: fractions as millis ("0.5" -> "500m"), small values
+// as integers and everything from 1024 up in binary units ("1073741824" -> "1Gi",
+// "1G" -> "953.7Mi"). Values that don't parse as quantities are returned unchanged.
+func formatQuantity(value string) string {
+	q, err := resource.ParseQuantity(strings.TrimSpace(value))
+	if err != nil {
+		return value
+	}
+
+	if milli := q.MilliValue(); milli%1000 != 0 && milli < 1000*1000 {
+		return fmt.Sprintf("%dm", milli)
+	}
+
+	n := q.Value()
+	if n < 1024 && n > -1024 {
+		return strconv.FormatInt(n, 10)
+	}
+
+	size := float64(n)
+	suffix := ""
+	for _, s := range binarySuffixes {
+		if size < 1024 && size > -1024 {
+			break
+		}
+		size /= 1024
+		suffix = s
+	}
+	return strconv.FormatFloat(math.Round(size*10)/10, 'f', -1, 64) + suffix
+}
+
 // extractNodeStatus returns node status like "Ready" or "Ready,SchedulingDisabled"
 func (f *Formatter) extractNodeStatus(obj map[string]interface{}) string {
 	var statuses []string
@@ -430,6 +1016,165 @@ func (f *Formatter) extractCnpgClusterReady(obj map[string]interface{}) string {
 	return ready + "/" + total
 }
 
+// extractDaemonSetReady returns a DaemonSet's ready/desired pods (e.g. 3/3).
+// The status omits zero counts, so missing fields count as 0.
+func (f *Formatter) extractDaemonSetReady(obj map[string]interface{}) string {
+	ready := valueString(f.getNestedValue(obj, ".status.numberReady"))
+	if ready == "" {
+		ready = "0"
+	}
+	desired := valueString(f.getNestedValue(obj, ".status.desiredNumberScheduled"))
+	if desired == "" {
+		desired = "0"
+	}
+	return ready + "/" + desired
+}
+
+// extractPodRestarts returns the total restart count of a pod's containers
+func (f *Formatter) extractPodRestarts(obj map[string]interface{}) string {
+	statuses, _, _ := unstructured.NestedSlice(obj, "status", "containerStatuses")
+	var restarts int64
+	for _, s := range statuses {
+		status, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch n := status["restartCount"].(type) {
+		case int64:
+			restarts += n
+		case float64:
+			restarts += int64(n)
+		}
+	}
+	return strconv.FormatInt(restarts, 10)
+}
+
+// extractJobCompletions returns a Job's succeeded/desired completions (e.g. 1/1),
+// suffixed with the failure count when pods have failed (e.g. "0/1 (2 failed)")
+func (f *Formatter) extractJobCompletions(obj map[string]interface{}) string {
+	succeeded := valueString(f.getNestedValue(obj, ".status.succeeded"))
+	if succeeded == "" {
+		succeeded = "0"
+	}
+	// A Job without .spec.completions runs until a single pod succeeds
+	completions := valueString(f.getNestedValue(obj, ".spec.completions"))
+	if completions == "" {
+		completions = "1"
+	}
+	result := succeeded + "/" + completions
+	if failed := valueString(f.getNestedValue(obj, ".status.failed")); failed != "" && failed != "0" {
+		result += " (" + failed + " failed)"
+	}
+	return result
+}
+
+// extractJobDuration returns how long a Job ran, from start to completion. Jobs
+// that are still running report the time since they started.
+func (f *Formatter) extractJobDuration(obj map[string]interface{}) string {
+	start, err := time.Parse(time.RFC3339, valueString(f.getNestedValue(obj, ".status.startTime")))
+	if err != nil {
+		return "-"
+	}
+	end, err := time.Parse(time.RFC3339, valueString(f.getNestedValue(obj, ".status.completionTime")))
+	if err != nil {
+		end = time.Now()
+	}
+	return f.formatDuration(end.Sub(start))
+}
+
+// extractHPATargets returns an HPA's current/target value per metric, like
+// kubectl (e.g. "cpu: 40%/80%"). Current metrics are matched to the spec by index.
+func (f *Formatter) extractHPATargets(obj map[string]interface{}) string {
+	metrics, _ := f.getNestedValue(obj, ".spec.metrics").([]interface{})
+	if len(metrics) == 0 {
+		return "<none>"
+	}
+	current, _ := f.getNestedValue(obj, ".status.currentMetrics").([]interface{})
+
+	parts := make([]string, 0, len(metrics))
+	for i, m := range metrics {
+		metric, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// The metric source lives under the lower-camel-cased type, e.g. "Resource" -> .resource
+		typ := valueString(metric["type"])
+		if typ == "" {
+			continue
+		}
+		key := strings.ToLower(typ[:1]) + typ[1:]
+		source, _ := metric[key].(map[string]interface{})
+
+		name := valueString(source["name"])
+		if name == "" {
+			name = valueString(f.getNestedValue(source, ".metric.name"))
+		}
+		if name == "" {
+			name = strings.ToLower(typ)
+		}
+
+		target, _ := source["target"].(map[string]interface{})
+		cur := "<unknown>"
+		if i < len(current) {
+			if c, ok := current[i].(map[string]interface{}); ok {
+				if v := hpaMetricValue(f.getNestedValue(c, "."+key+".current")); v != "" {
+					cur = v
+				}
+			}
+		}
+		parts = append(parts, name+": "+cur+"/"+hpaMetricValue(target))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hpaMetricValue formats an HPA MetricTarget or MetricValueStatus
+func hpaMetricValue(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if u := valueString(m["averageUtilization"]); u != "" {
+		return u + "%"
+	}
+	if a := valueString(m["averageValue"]); a != "" {
+		return a
+	}
+	return valueString(m["value"])
+}
+
+// extractServiceEndpoints returns the number of ready addresses in the EndpointSlices
+// of a service, or "<none>" if there are none
+func (f *Formatter) extractServiceEndpoints(obj *unstructured.Unstructured) string {
+	if f.lookup == nil {
+		return "<none>"
+	}
+	ready := 0
+	for _, slice := range f.lookup(EndpointSlicesGVR, obj.GetNamespace()) {
+		if slice.Object == nil || slice.Object.GetLabels()[serviceNameLabel] != obj.GetName() {
+			continue
+		}
+		endpoints, _ := slice.Object.Object["endpoints"].([]interface{})
+		for _, e := range endpoints {
+			endpoint, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// An unknown readiness counts as ready, as the API documents
+			if conditions, ok := endpoint["conditions"].(map[string]interface{}); ok {
+				if isReady, ok := conditions["ready"].(bool); ok && !isReady {
+					continue
+				}
+			}
+			addresses, _ := endpoint["addresses"].([]interface{})
+			ready += len(addresses)
+		}
+	}
+	if ready == 0 {
+		return "<none>"
+	}
+	return strconv.Itoa(ready)
+}
+
 // extractCertReady returns cert-manager Certificate ready status
 func (f *Formatter) extractCertReady(obj map[string]interface{}) string {
 	conditions := f.getNestedValue(obj, ".status.conditions")
@@ -512,8 +1257,9 @@ func (f *Formatter) getNestedValue(obj map[string]interface{}, path string) inte
 	return current
 }
 
-// extractArrayField handles fields with [*] array access
-func (f *Formatter) extractArrayField(obj map[string]interface{}, field string) string {
+// extractArrayField handles fields with [*] array access, joining values with sep.
+// With maxItems > 0 only the first maxItems values are shown, followed by "+N more".
+func (f *Formatter) extractArrayField(obj map[string]interface{}, field, sep string, maxItems int) string {
 	// Split on [*]
 	parts := strings.Split(field, "[*]")
 	if len(parts) != 2 {
@@ -541,6 +1287,39 @@ func (f *Formatter) extractArrayField(obj map[string]interface{}, field string)
 		}
 	}
 
+	if maxItems > 0 && len(values) > maxItems {
+		more := len(values) - maxItems
+		return strings.Join(values[:maxItems], sep) + sep + fmt.Sprintf("+%d more", more)
+	}
+	return strings.Join(values, sep)
+}
+
+// extractJSONPath evaluates a JSONPath expression using the kubectl JSONPath implementation.
+// Multiple results are joined with commas; missing keys yield an empty string.
+func (f *Formatter) extractJSONPath(obj map[string]interface{}, expr string) string {
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+
+	jp := jsonpath.New("field").AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return ""
+	}
+
+	results, err := jp.FindResults(obj)
+	if err != nil {
+		return ""
+	}
+
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, fmt.Sprintf("%v", value.Interface()))
+			}
+		}
+	}
+
 	return strings.Join(values, ",")
 }
 
@@ -565,14 +1344,19 @@ func (f *Formatter) extractFilteredArrayField(obj map[string]interface{}, field
 	filterExpr := remaining[3:endBracket] // Skip "[?(" and get until ")"
 	fieldAfter := strings.TrimPrefix(remaining[endBracket+2:], ".")
 
-	// Parse filter expression like "@.type==\"Ready\""
-	filterParts := strings.Split(filterExpr, "==")
-	if len(filterParts) != 2 {
+	// Parse filter expression like "@.type==\"Ready\"". The leftmost operator is the
+	// comparison, so operators inside the quoted value are left alone.
+	op, opIdx := "", -1
+	for _, candidate := range filterOperators {
+		if idx := strings.Index(filterExpr, candidate); idx != -1 && (opIdx == -1 || idx < opIdx) {
+			op, opIdx = candidate, idx
+		}
+	}
+	if op == "" {
 		return ""
 	}
-
-	filterField := strings.TrimPrefix(filterParts[0], "@.")
-	filterValue := strings.Trim(filterParts[1], "\"")
+	filterField := strings.TrimPrefix(filterExpr[:opIdx], "@.")
+	filterValue := strings.Trim(filterExpr[opIdx+len(op):], "\"")
 
 	// Get the array
 	arrayValue := f.getNestedValue(obj, arrayPath)
@@ -584,7 +1368,7 @@ func (f *Formatter) extractFilteredArrayField(obj map[string]interface{}, field
 	// Find matching item
 	for _, item := range arr {
 		if itemMap, ok := item.(map[string]interface{}); ok {
-			if val := f.getNestedValue(itemMap, "."+filterField); fmt.Sprintf("%v", val) == filterValue {
+			if val := f.getNestedValue(itemMap, "."+filterField); matchFilter(op, fmt.Sprintf("%v", val), filterValue) {
 				if fieldAfter != "" {
 					result := f.getNestedValue(itemMap, "."+fieldAfter)
 					return fmt.Sprintf("%v", result)
@@ -597,48 +1381,218 @@ func (f *Formatter) extractFilteredArrayField(obj map[string]interface{}, field
 	return ""
 }
 
-// formatAge formats a duration as a human-readable age string
+// filterOperators are the comparisons supported in [?()] filters. "=~" is a substring
+// match (not a regex, to keep it cheap) and "^=" a prefix match.
+var filterOperators = []string{"==", "!=", "=~", "^="}
+
+// matchFilter reports whether value satisfies the filter comparison against want
+func matchFilter(op, value, want string) bool {
+	switch op {
+	case "==":
+		return value == want
+	case "!=":
+		return value != want
+	case "=~":
+		return strings.Contains(value, want)
+	case "^=":
+		return strings.HasPrefix(value, want)
+	}
+	return false
+}
+
+// ageUnits are the units used by formatAge, largest first
+var ageUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"y", 365 * 24 * time.Hour},
+	{"M", 30 * 24 * time.Hour},
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// formatAge formats a duration as a human-readable age string: the largest unit by
+// default (e.g. "3d"), or the two largest units in compound mode (e.g. "3d4h")
 func (f *Formatter) formatAge(t time.Time) string {
 	if t.IsZero() {
 		return "<unknown>"
 	}
 
-	duration := time.Since(t)
+	return f.formatDuration(time.Since(t))
+}
+
+// formatDuration formats a duration using the same units as formatAge
+func (f *Formatter) formatDuration(duration time.Duration) string {
+	// A timestamp in the future means the cluster and client clocks differ
+	if duration < 0 {
+		duration = 0
+	}
+	compound := f.config.Server.AgeFormat == config.AgeFormatCompound
+
+	for i, unit := range ageUnits {
+		if duration < unit.size {
+			continue
+		}
+		n := duration / unit.size
+		if compound && i+1 < len(ageUnits) {
+			next := ageUnits[i+1]
+			if rest := (duration - n*unit.size) / next.size; rest > 0 {
+				return strconv.FormatInt(int64(n), 10) + unit.suffix + strconv.FormatInt(int64(rest), 10) + next.suffix
+			}
+		}
+		return strconv.FormatInt(int64(n), 10) + unit.suffix
+	}
+	return strconv.FormatInt(int64(duration/time.Second), 10) + "s"
+}
+
+// formatTime formats a timestamp as local time using the configured layout
+func (f *Formatter) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	layout := f.config.Server.TimeFormat
+	if layout == "" {
+		layout = config.DefaultTimeFormat
+	}
+	return t.Local().Format(layout)
+}
+
+// truncateOrPad truncates or pads a string to a fixed width in runes.
+// mode selects what is kept when truncating: "end" (default) keeps the prefix,
+// "start" or "left" keeps the suffix and "middle" keeps both ends.
+func (f *Formatter) truncateOrPad(s string, width int, mode string) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s + strings.Repeat(" ", width-len(runes))
+	}
+
+	if width <= 3 {
+		if mode == config.TruncateStart || mode == config.TruncateLeft {
+			return string(runes[len(runes)-width:])
+		}
+		return string(runes[:width])
+	}
 
-	if duration < time.Minute {
-		return fmt.Sprintf("%ds", int(duration.Seconds()))
+	keep := width - 3
+	switch mode {
+	case config.TruncateStart, config.TruncateLeft:
+		return "..." + string(runes[len(runes)-keep:])
+	case config.TruncateMiddle:
+		head := (keep + 1) / 2
+		tail := keep - head
+		return string(runes[:head]) + "..." + string(runes[len(runes)-tail:])
+	default:
+		return string(runes[:keep]) + "..."
 	}
-	if duration < time.Hour {
-		return fmt.Sprintf("%dm", int(duration.Minutes()))
+}
+
+// sanitizeColumn sanitizes a column value, using the reversible name escaping for the name column
+func sanitizeColumn(field, value string) string {
+	if field == ".metadata.name" {
+		return sanitizeName(value)
 	}
-	if duration < 24*time.Hour {
-		return fmt.Sprintf("%dh", int(duration.Hours()))
+	return sanitizeField(value)
+}
+
+// sanitizeName escapes non-printable characters, spaces and backslashes in a resource name
+// so it can't inject terminal sequences or split the name field. The escaping is reversible
+// with UnescapeName, so the exact name can be recovered from a selected line.
+func sanitizeName(s string) string {
+	clean := true
+	for _, r := range s {
+		if r == '\\' || r == ' ' || !unicode.IsPrint(r) {
+			clean = false
+			break
+		}
 	}
-	days := int(duration.Hours() / 24)
-	if days < 30 {
-		return fmt.Sprintf("%dd", days)
+	if clean {
+		return s
 	}
-	if days < 365 {
-		return fmt.Sprintf("%dM", days/30)
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == ' ' || (r < 0x80 && !unicode.IsPrint(r)):
+			fmt.Fprintf(&buf, "\\x%02x", r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		default:
+			buf.WriteRune(r)
+		}
 	}
-	return fmt.Sprintf("%dy", days/365)
+	return buf.String()
 }
 
-// truncateOrPad truncates or pads a string to a fixed width
-func (f *Formatter) truncateOrPad(s string, width int) string {
-	if len(s) > width {
-		if width > 3 {
-			return s[:width-3] + "..."
+// UnescapeName reverses the escaping applied to the name column, returning the exact resource name
+func UnescapeName(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			buf.WriteByte(s[i])
+			continue
+		}
+		digits := 0
+		switch s[i+1] {
+		case '\\':
+			buf.WriteByte('\\')
+			i++
+			continue
+		case 'x':
+			digits = 2
+		case 'u':
+			digits = 4
+		}
+		if digits > 0 && i+2+digits <= len(s) {
+			if v, err := strconv.ParseUint(s[i+2:i+2+digits], 16, 32); err == nil {
+				buf.WriteRune(rune(v))
+				i += 1 + digits
+				continue
+			}
 		}
-		return s[:width]
+		buf.WriteByte(s[i])
 	}
-	return s + strings.Repeat(" ", width-len(s))
+	return buf.String()
 }
 
-// padOnly pads a string to at least the given width (never truncates)
-func (f *Formatter) padOnly(s string, width int) string {
-	if len(s) >= width {
+// sanitizeField makes a field value safe for the tab/newline delimited output.
+// Tabs, newlines and carriage returns become spaces; other control characters
+// are replaced by their escaped form (e.g. \x1b).
+func sanitizeField(s string) string {
+	clean := true
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
 		return s
 	}
-	return s + strings.Repeat(" ", width-len(s))
-}
\ No newline at end of file
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			buf.WriteByte(' ')
+		case r < 0x80 && unicode.IsControl(r):
+			fmt.Fprintf(&buf, "\\x%02x", r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
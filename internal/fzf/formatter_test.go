@@ -1,13 +1,17 @@
 package fzf
 
 import (
+	"bytes"
+	"slices"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/pslijkhuis/kfzf/internal/config"
 	"github.com/pslijkhuis/kfzf/internal/store"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestFormatter_FormatAge(t *testing.T) {
@@ -22,6 +26,8 @@ func TestFormatter_FormatAge(t *testing.T) {
 		{"5 minutes", 5 * time.Minute, "5m"},
 		{"2 hours", 2 * time.Hour, "2h"},
 		{"3 days", 3 * 24 * time.Hour, "3d"},
+		{"10 days", 10 * 24 * time.Hour, "1w"},
+		{"20 days", 20 * 24 * time.Hour, "2w"},
 		{"45 days", 45 * 24 * time.Hour, "1M"},
 		{"400 days", 400 * 24 * time.Hour, "1y"},
 	}
@@ -37,6 +43,36 @@ func TestFormatter_FormatAge(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatAgeCompound(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.AgeFormat = config.AgeFormatCompound
+	f := NewFormatter(cfg)
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected string
+	}{
+		{"30 seconds", 30 * time.Second, "30s"},
+		{"3 hours 20 minutes", 3*time.Hour + 20*time.Minute, "3h20m"},
+		{"exactly 2 hours", 2 * time.Hour, "2h"},
+		{"1 day 2 hours", 26 * time.Hour, "1d2h"},
+		{"9 days", 9 * 24 * time.Hour, "1w2d"},
+		{"400 days", 400 * 24 * time.Hour, "1y1M"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Pad slightly so elapsed test time never rounds down a unit
+			creationTime := time.Now().Add(-tt.duration - 500*time.Millisecond)
+			result := f.formatAge(creationTime)
+			if result != tt.expected {
+				t.Errorf("formatAge(%v) = %s, want %s", tt.duration, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestFormatter_FormatAgeZero(t *testing.T) {
 	f := NewFormatter(config.DefaultConfig())
 	result := f.formatAge(time.Time{})
@@ -45,6 +81,52 @@ func TestFormatter_FormatAgeZero(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatAgeFuture(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+	result := f.formatAge(time.Now().Add(5 * time.Second))
+	if result != "0s" {
+		t.Errorf("formatAge(future) = %s, want 0s", result)
+	}
+}
+
+func TestFormatter_AbsTimeField(t *testing.T) {
+	created := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"startTime": "2024-03-10T08:00:00Z",
+				"reason":    "not a time",
+			},
+		},
+	}
+
+	f := NewFormatter(config.DefaultConfig())
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"abs:.metadata.creationTimestamp", created.Local().Format(config.DefaultTimeFormat)},
+		{"abs:.status.startTime", time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC).Local().Format(config.DefaultTimeFormat)},
+		{"abs:.status.reason", "not a time"},
+	}
+	for _, tt := range tests {
+		if got := f.extractField(obj, tt.field, created); got != tt.expected {
+			t.Errorf("extractField(%q) = %q, want %q", tt.field, got, tt.expected)
+		}
+	}
+
+	if got := f.extractField(obj, "abs:.metadata.creationTimestamp", time.Time{}); got != "<unknown>" {
+		t.Errorf("extractField(zero time) = %q, want <unknown>", got)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Server.TimeFormat = time.RFC3339
+	f = NewFormatter(cfg)
+	if got, want := f.extractField(obj, "abs:.metadata.creationTimestamp", created), created.Local().Format(time.RFC3339); got != want {
+		t.Errorf("extractField() with custom format = %q, want %q", got, want)
+	}
+}
+
 func TestFormatter_TruncateOrPad(t *testing.T) {
 	f := NewFormatter(config.DefaultConfig())
 
@@ -62,7 +144,7 @@ func TestFormatter_TruncateOrPad(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			result := f.truncateOrPad(tt.input, tt.width)
+			result := f.truncateOrPad(tt.input, tt.width, "")
 			if result != tt.expected {
 				t.Errorf("truncateOrPad(%q, %d) = %q, want %q", tt.input, tt.width, result, tt.expected)
 			}
@@ -70,6 +152,35 @@ func TestFormatter_TruncateOrPad(t *testing.T) {
 	}
 }
 
+func TestFormatter_TruncateModes(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	tests := []struct {
+		input    string
+		width    int
+		mode     string
+		expected string
+	}{
+		{"my-deployment-7d9f8c6b5-abcde", 12, config.TruncateEnd, "my-deploy..."},
+		{"my-deployment-7d9f8c6b5-abcde", 12, config.TruncateStart, "...6b5-abcde"},
+		{"my-deployment-7d9f8c6b5-abcde", 12, config.TruncateMiddle, "my-de...bcde"},
+		{"short", 8, config.TruncateMiddle, "short   "},
+		{"abcdef", 3, config.TruncateStart, "def"},
+		{"héllo-wörld-ünïcode", 10, config.TruncateEnd, "héllo-w..."},
+		{"héllo-wörld-ünïcode", 10, config.TruncateStart, "...ünïcode"},
+		{"naïve", 7, config.TruncateEnd, "naïve  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.input, func(t *testing.T) {
+			result := f.truncateOrPad(tt.input, tt.width, tt.mode)
+			if result != tt.expected {
+				t.Errorf("truncateOrPad(%q, %d, %q) = %q, want %q", tt.input, tt.width, tt.mode, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestFormatter_ExtractNodeStatus(t *testing.T) {
 	f := NewFormatter(config.DefaultConfig())
 
@@ -516,7 +627,7 @@ func TestFormatter_ExtractArrayField(t *testing.T) {
 		},
 	}
 
-	result := f.extractArrayField(obj, ".spec.rules[*].host")
+	result := f.extractArrayField(obj, ".spec.rules[*].host", ",", 0)
 	expected := "app1.example.com,app2.example.com"
 	if result != expected {
 		t.Errorf("extractArrayField() = %s, want %s", result, expected)
@@ -552,6 +663,43 @@ func TestFormatter_ExtractFilteredArrayField(t *testing.T) {
 	}
 }
 
+func TestFormatter_FilterOperators(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "PodScheduled", "status": "True"},
+				map[string]interface{}{"type": "DatabaseReady", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "Unknown"},
+				map[string]interface{}{"type": "Degraded", "status": "True", "reason": "a==b"},
+			},
+		},
+	}
+
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{`.status.conditions[?(@.type=="Ready")].status`, "Unknown"},
+		{`.status.conditions[?(@.type!="PodScheduled")].status`, "False"},
+		{`.status.conditions[?(@.type=~"Ready")].status`, "False"},
+		{`.status.conditions[?(@.type=~"Sched")].status`, "True"},
+		{`.status.conditions[?(@.type^="Ready")].status`, "Unknown"},
+		{`.status.conditions[?(@.type^="Database")].status`, "False"},
+		{`.status.conditions[?(@.type=~"Missing")].status`, ""},
+		{`.status.conditions[?(@.type>"Ready")].status`, ""},
+		{`.status.conditions[?(@.reason=~"a==b")].type`, "Degraded"},
+		{`.status.conditions[?(@.reason^="a!=")].type`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := f.extractFilteredArrayField(obj, tt.field); got != tt.expected {
+			t.Errorf("extractFilteredArrayField(%s) = %q, want %q", tt.field, got, tt.expected)
+		}
+	}
+}
+
 func TestFormatter_Colorize(t *testing.T) {
 	f := NewFormatter(config.DefaultConfig())
 
@@ -720,3 +868,849 @@ func TestFormatter_RatioField(t *testing.T) {
 		t.Errorf("extractField(ratio) = %s, want %s", result, expected)
 	}
 }
+
+func TestFormatter_PercentField(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	tests := []struct {
+		name     string
+		object   map[string]interface{}
+		expected string
+	}{
+		{"partial", map[string]interface{}{
+			"status": map[string]interface{}{"readyReplicas": float64(2)},
+			"spec":   map[string]interface{}{"replicas": float64(3)},
+		}, "67%"},
+		{"all ready", map[string]interface{}{
+			"status": map[string]interface{}{"readyReplicas": int64(3)},
+			"spec":   map[string]interface{}{"replicas": int64(3)},
+		}, "100%"},
+		{"none ready", map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": float64(3)},
+		}, "0%"},
+		{"scaled to zero", map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": float64(0)},
+		}, "-"},
+	}
+
+	for _, tt := range tests {
+		obj := &unstructured.Unstructured{Object: tt.object}
+		result := f.extractField(obj, "percent:.status.readyReplicas/.spec.replicas", time.Time{})
+		if result != tt.expected {
+			t.Errorf("%s: extractField(percent) = %q, want %q", tt.name, result, tt.expected)
+		}
+	}
+
+	colors := map[string]string{"100%": colorGreen, "67%": colorYellow, "10%": colorRed}
+	for value, color := range colors {
+		if got := f.colorize(value, "READY", 2); !strings.HasPrefix(got, color) {
+			t.Errorf("colorize(%s) = %q, want prefix %q", value, got, color)
+		}
+	}
+}
+
+func TestFormatter_JSONPathField(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx:1.25"},
+					map[string]interface{}{"name": "sidecar", "image": "envoy:1.30"},
+				},
+			},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "False"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"jsonpath:{.spec.containers[*].image}", "nginx:1.25,envoy:1.30"},
+		{"jsonpath:{.spec.containers[?(@.name==\"sidecar\")].image}", "envoy:1.30"},
+		{"jsonpath:{.spec.containers[-1:].name}", "sidecar"},
+		{"jsonpath:.status.conditions[0].status", "False"},
+		{"jsonpath:{.spec.missing}", ""},
+		{"jsonpath:{.spec[}", ""},
+	}
+
+	for _, tt := range tests {
+		result := f.extractField(obj, tt.field, time.Time{})
+		if result != tt.expected {
+			t.Errorf("extractField(%s) = %q, want %q", tt.field, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatter_CustomColumnsField(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":   "web",
+				"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": float64(3),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": "nginx:1.25"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"{.metadata.name}", "web"},
+		{"{.spec.replicas}", "3"},
+		{"{.spec.template.spec.containers[*].image}", "nginx:1.25"},
+		{"{.metadata.labels.app\\.kubernetes\\.io/name}", "web"},
+		{"{.status.readyReplicas}", ""},
+	}
+
+	for _, tt := range tests {
+		result := f.extractField(obj, tt.field, time.Time{})
+		if result != tt.expected {
+			t.Errorf("extractField(%s) = %q, want %q", tt.field, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatter_NamespaceColors(t *testing.T) {
+	cfg := config.DefaultConfig()
+	f := NewFormatter(cfg)
+
+	if got := f.colorize("kube-system", "NAMESPACE", 1); got != colorBlue+"kube-system"+colorReset {
+		t.Errorf("default namespace color = %q, want blue", got)
+	}
+
+	cfg.Server.NamespaceColors = true
+	first := f.colorize("kube-system", "NAMESPACE", 1)
+	if second := f.colorize("kube-system   ", "NAMESPACE", 1); !strings.HasPrefix(second, namespaceColor("kube-system")) {
+		t.Errorf("padded namespace got a different color: %q", second)
+	}
+	if first != namespaceColor("kube-system")+"kube-system"+colorReset {
+		t.Errorf("colorize() = %q, want hashed namespace color", first)
+	}
+
+	seen := make(map[string]bool)
+	for _, ns := range []string{"default", "kube-system", "monitoring", "ingress-nginx", "argocd", "cert-manager"} {
+		seen[namespaceColor(ns)] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected different namespaces to map onto more than one color")
+	}
+}
+
+// TestFormatter_WithColor tests that colors reach the formatted rows, wrapping the padding
+func TestFormatter_WithColor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Resources["widgets"] = config.ResourceConfig{
+		Columns: []config.ColumnConfig{
+			{Name: "NAME", Field: ".metadata.name", Width: 6},
+			{Name: "NAMESPACE", Field: ".metadata.namespace"},
+			{Name: "READY", Field: ".status.ready"},
+		},
+	}
+	resources := []*store.Resource{{
+		Name:      "web",
+		Namespace: "prod",
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+			"status":   map[string]interface{}{"ready": "0/1"},
+		}},
+	}}
+
+	f := NewFormatter(cfg)
+	plain := f.Format(resources, "widgets")
+	if plain != "web   \tprod\t0/1" {
+		t.Errorf("Format() = %q, want no colors by default", plain)
+	}
+
+	colored := f.WithColor(true).Format(resources, "widgets")
+	want := colorCyan + colorBold + "web   " + colorReset + "\t" +
+		colorBlue + "prod" + colorReset + "\t" +
+		colorRed + "0/1" + colorReset
+	if colored != want {
+		t.Errorf("Format() with color = %q, want %q", colored, want)
+	}
+	if got := StripANSI(colored); got != plain {
+		t.Errorf("StripANSI() = %q, want %q", got, plain)
+	}
+
+	cfg.Server.NamespaceColors = true
+	if got := f.WithColor(true).Format(resources, "widgets"); !strings.Contains(got, namespaceColor("prod")+"prod"+colorReset) {
+		t.Errorf("Format() = %q, want the hashed namespace color", got)
+	}
+
+	// Only the characters matching the query are bold, and stripping restores the plain row
+	highlighted := f.WithColor(true).WithHighlight("wb").Format(resources, "widgets")
+	wantName := colorCyan + colorBold + "w" + colorNormal + "e" + colorBold + "b" + colorNormal + "   " + colorReset + "\t"
+	if !strings.HasPrefix(highlighted, wantName) {
+		t.Errorf("Format() with highlight = %q, want prefix %q", highlighted, wantName)
+	}
+	if got := StripANSI(highlighted); got != plain {
+		t.Errorf("StripANSI() = %q, want %q", got, plain)
+	}
+	if got := f.WithHighlight("wb").Format(resources, "widgets"); got != plain {
+		t.Errorf("Format() with highlight but no color = %q, want %q", got, plain)
+	}
+}
+
+func TestFormatter_SanitizeFieldValues(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Resources["configmaps"] = config.ResourceConfig{
+		Columns: []config.ColumnConfig{
+			{Name: "NAME", Field: ".metadata.name"},
+			{Name: "NOTE", Field: ".metadata.annotations.note"},
+		},
+	}
+	f := NewFormatter(cfg)
+
+	resources := []*store.Resource{
+		{
+			Object: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":        "cm-1",
+						"annotations": map[string]interface{}{"note": "line one\nline\ttwo\r\x1b[31m"},
+					},
+				},
+			},
+		},
+	}
+
+	result := f.Format(resources, "configmaps")
+	if strings.Contains(result, "\n") {
+		t.Errorf("Format() output spans multiple lines: %q", result)
+	}
+	fields := strings.Split(result, "\t")
+	if len(fields) != 2 {
+		t.Fatalf("Format() produced %d fields, want 2: %q", len(fields), result)
+	}
+	if want := `line one line two \x1b[31m`; fields[1] != want {
+		t.Errorf("sanitized field = %q, want %q", fields[1], want)
+	}
+
+	if got := sanitizeField("plain-value"); got != "plain-value" {
+		t.Errorf("sanitizeField(plain) = %q", got)
+	}
+}
+
+func TestFormatter_SanitizeName(t *testing.T) {
+	tests := []struct {
+		name    string
+		escaped string
+	}{
+		{"my-pod", "my-pod"},
+		{"evil\x1b[2Jpod", `evil\x1b[2Jpod`},
+		{"with space", `with\x20space`},
+		{"back\\slash", `back\\slash`},
+		{"bidi\u202eeman", `bidi\u202eeman`},
+		{"unicode-é", "unicode-é"},
+	}
+
+	for _, tt := range tests {
+		got := sanitizeName(tt.name)
+		if got != tt.escaped {
+			t.Errorf("sanitizeName(%q) = %q, want %q", tt.name, got, tt.escaped)
+		}
+		if back := UnescapeName(got); back != tt.name {
+			t.Errorf("UnescapeName(%q) = %q, want %q", got, back, tt.name)
+		}
+	}
+
+	f := NewFormatter(config.DefaultConfig())
+	resources := []*store.Resource{
+		{
+			Object: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      "pod\x1b]0;pwned\x07",
+						"namespace": "default",
+					},
+				},
+			},
+		},
+	}
+	result := f.Format(resources, "pods")
+	if strings.ContainsAny(result, "\x1b\x07") {
+		t.Errorf("Format() leaked control characters: %q", result)
+	}
+	name := strings.TrimSpace(strings.Split(result, "\t")[0])
+	if UnescapeName(name) != "pod\x1b]0;pwned\x07" {
+		t.Errorf("name column %q does not round-trip to the exact name", name)
+	}
+}
+
+func TestFormatter_TruncateLeftName(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Resources["pods"] = config.ResourceConfig{
+		Columns: []config.ColumnConfig{
+			{Name: "NAME", Field: ".metadata.name", Width: 10, Truncate: config.TruncateLeft},
+			{Name: "NAMESPACE", Field: ".metadata.namespace", Width: 7},
+		},
+	}
+	pod := func(name string) *store.Resource {
+		return &store.Resource{Name: name, Namespace: "default", Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+		}}}
+	}
+
+	f := NewFormatter(cfg)
+	result := f.Format([]*store.Resource{pod("web-7d9f8c6b5-abcde"), pod("db"), pod("with space-long")}, "pods")
+	want := "...5-abcde\tdefault\tweb-7d9f8c6b5-abcde\n" +
+		"db        \tdefault\n" +
+		"...ce-long\tdefault\twith\\x20space-long"
+	if result != want {
+		t.Errorf("Format() = %q, want %q", result, want)
+	}
+
+	// The full name is selected, and only the configured columns are shown
+	lines := strings.Split(result, "\n")
+	for i, name := range []string{"web-7d9f8c6b5-abcde", "db", "with space-long"} {
+		if got := SelectedName(lines[i] + "\n"); got != name {
+			t.Errorf("SelectedName(%q) = %q, want %q", lines[i], got, name)
+		}
+	}
+	if got := DisplayFields(result); got != 2 {
+		t.Errorf("DisplayFields() = %d, want 2", got)
+	}
+	if got := DisplayFields(lines[1]); got != 0 {
+		t.Errorf("DisplayFields() without truncated names = %d, want 0", got)
+	}
+
+	colored := f.WithColor(true).Format([]*store.Resource{pod("web-7d9f8c6b5-abcde")}, "pods")
+	if got := SelectedName(colored); got != "web-7d9f8c6b5-abcde" {
+		t.Errorf("SelectedName(colored) = %q, want full name", got)
+	}
+
+	// Other modes still never truncate the name
+	cfg.Resources["pods"].Columns[0].Truncate = config.TruncateStart
+	if got := NewFormatter(cfg).Format([]*store.Resource{pod("web-7d9f8c6b5-abcde")}, "pods"); got != "web-7d9f8c6b5-abcde\tdefault" {
+		t.Errorf("Format() with truncate: start = %q, want the full name", got)
+	}
+}
+
+func TestFormatter_QuantityField(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"capacity": map[string]interface{}{"storage": "10Gi"},
+				"containers": []interface{}{
+					map[string]interface{}{"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "0.5", "memory": "268435456"}}},
+					map[string]interface{}{"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "2", "memory": "1G"}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"quantity:.spec.capacity.storage", "10Gi"},
+		{"quantity:.spec.containers[*].resources.requests.cpu", "500m,2"},
+		{"quantity:.spec.containers[*].resources.requests.memory", "256Mi,953.7Mi"},
+		{"quantity:.spec.missing", ""},
+	}
+
+	for _, tt := range tests {
+		result := f.extractField(obj, tt.field, time.Time{})
+		if result != tt.expected {
+			t.Errorf("extractField(%s) = %q, want %q", tt.field, result, tt.expected)
+		}
+	}
+
+	if got := formatQuantity("not-a-quantity"); got != "not-a-quantity" {
+		t.Errorf("formatQuantity(invalid) = %q, want input unchanged", got)
+	}
+}
+
+func TestFormatter_ArrayJoinOptions(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	res := &store.Resource{
+		Object: &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"rules": []interface{}{
+						map[string]interface{}{"host": "a.example.com"},
+						map[string]interface{}{"host": "b.example.com"},
+						map[string]interface{}{"host": "c.example.com"},
+						map[string]interface{}{"host": "d.example.com"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		col      config.ColumnConfig
+		expected string
+	}{
+		{"default", config.ColumnConfig{Field: ".spec.rules[*].host"}, "a.example.com,b.example.com,c.example.com,d.example.com"},
+		{"separator", config.ColumnConfig{Field: ".spec.rules[*].host", Separator: " "}, "a.example.com b.example.com c.example.com d.example.com"},
+		{"max items", config.ColumnConfig{Field: ".spec.rules[*].host", MaxItems: 2}, "a.example.com,b.example.com,+2 more"},
+		{"both", config.ColumnConfig{Field: ".spec.rules[*].host", Separator: " ", MaxItems: 3}, "a.example.com b.example.com c.example.com +1 more"},
+		{"max not reached", config.ColumnConfig{Field: ".spec.rules[*].host", MaxItems: 4}, "a.example.com,b.example.com,c.example.com,d.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := f.extractColumn(res, tt.col); got != tt.expected {
+			t.Errorf("%s: extractColumn() = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatter_FormatGrouped(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Resources["pods"] = config.ResourceConfig{
+		Columns: []config.ColumnConfig{
+			{Name: "NAME", Field: ".metadata.name"},
+			{Name: "NAMESPACE", Field: ".metadata.namespace"},
+		},
+	}
+	f := NewFormatter(cfg)
+
+	pod := func(namespace, name string) *store.Resource {
+		return &store.Resource{
+			Namespace: namespace,
+			Name:      name,
+			Object: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+				},
+			},
+		}
+	}
+	resources := []*store.Resource{
+		pod("prod", "api"),
+		pod("dev", "api"),
+		pod("prod", "web"),
+		pod("dev", "worker"),
+	}
+
+	expected := colorDim + "# dev" + colorReset + "\n" +
+		"api\tdev\n" +
+		"worker\tdev\n" +
+		colorDim + "# prod" + colorReset + "\n" +
+		"api\tprod\n" +
+		"web\tprod"
+	if got := f.FormatGrouped(resources, "pods"); got != expected {
+		t.Errorf("FormatGrouped() = %q, want %q", got, expected)
+	}
+	if resources[1].Namespace != "dev" || resources[2].Namespace != "prod" {
+		t.Error("FormatGrouped() reordered the caller's slice")
+	}
+
+	for _, line := range strings.Split(expected, "\n") {
+		header := strings.Contains(line, GroupHeaderPrefix)
+		if IsGroupHeader(line) != header {
+			t.Errorf("IsGroupHeader(%q) = %t, want %t", line, !header, header)
+		}
+	}
+	if !IsGroupHeader("# dev") {
+		t.Error("IsGroupHeader() should recognize headers with colors stripped")
+	}
+}
+
+func TestFormatter_DaemonSetReady(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	tests := []struct {
+		status   map[string]interface{}
+		expected string
+		color    string
+	}{
+		{map[string]interface{}{"numberReady": int64(3), "desiredNumberScheduled": int64(3)}, "3/3", colorGreen},
+		{map[string]interface{}{"numberReady": int64(1), "desiredNumberScheduled": int64(3)}, "1/3", colorYellow},
+		{map[string]interface{}{"desiredNumberScheduled": int64(2)}, "0/2", colorRed},
+		{map[string]interface{}{}, "0/0", colorRed},
+	}
+
+	for _, tt := range tests {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": tt.status}}
+		got := f.extractField(obj, "_dsReady", time.Time{})
+		if got != tt.expected {
+			t.Errorf("_dsReady(%v) = %q, want %q", tt.status, got, tt.expected)
+		}
+		if colored := f.colorize(got, "READY", 2); !strings.HasPrefix(colored, tt.color) {
+			t.Errorf("colorize(%q) = %q, want prefix %q", got, colored, tt.color)
+		}
+	}
+}
+
+func TestFormatter_JobColumns(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	tests := []struct {
+		object      map[string]interface{}
+		completions string
+		duration    string
+		color       string
+	}{
+		{
+			map[string]interface{}{
+				"spec":   map[string]interface{}{"completions": int64(1)},
+				"status": map[string]interface{}{"succeeded": int64(1), "startTime": "2024-01-01T10:00:00Z", "completionTime": "2024-01-01T10:02:30Z"},
+			},
+			"1/1", "2m", colorGreen,
+		},
+		{
+			map[string]interface{}{
+				"spec":   map[string]interface{}{"completions": int64(3)},
+				"status": map[string]interface{}{"succeeded": int64(1), "failed": int64(2), "startTime": "2024-01-01T10:00:00Z", "completionTime": "2024-01-01T13:00:00Z"},
+			},
+			"1/3 (2 failed)", "3h", colorRed,
+		},
+		{
+			map[string]interface{}{"status": map[string]interface{}{}},
+			"0/1", "-", colorYellow,
+		},
+	}
+
+	for _, tt := range tests {
+		obj := &unstructured.Unstructured{Object: tt.object}
+		got := f.extractField(obj, "_jobCompletions", time.Time{})
+		if got != tt.completions {
+			t.Errorf("_jobCompletions(%v) = %q, want %q", tt.object, got, tt.completions)
+		}
+		if colored := f.colorize(got, "COMPLETIONS", 2); !strings.HasPrefix(colored, tt.color) {
+			t.Errorf("colorize(%q) = %q, want prefix %q", got, colored, tt.color)
+		}
+		if got := f.extractField(obj, "_jobDuration", time.Time{}); got != tt.duration {
+			t.Errorf("_jobDuration(%v) = %q, want %q", tt.object, got, tt.duration)
+		}
+	}
+}
+
+func TestFormatter_HPATargets(t *testing.T) {
+	f := NewFormatter(config.DefaultConfig())
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"metrics": []interface{}{
+				map[string]interface{}{
+					"type": "Resource",
+					"resource": map[string]interface{}{
+						"name":   "cpu",
+						"target": map[string]interface{}{"type": "Utilization", "averageUtilization": int64(80)},
+					},
+				},
+				map[string]interface{}{
+					"type": "Pods",
+					"pods": map[string]interface{}{
+						"metric": map[string]interface{}{"name": "requests_per_second"},
+						"target": map[string]interface{}{"type": "AverageValue", "averageValue": "1k"},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"currentMetrics": []interface{}{
+				map[string]interface{}{
+					"type": "Resource",
+					"resource": map[string]interface{}{
+						"name":    "cpu",
+						"current": map[string]interface{}{"averageUtilization": int64(40), "averageValue": "200m"},
+					},
+				},
+			},
+		},
+	}}
+
+	expected := "cpu: 40%/80%, requests_per_second: <unknown>/1k"
+	if got := f.extractField(obj, "_hpaTargets", time.Time{}); got != expected {
+		t.Errorf("_hpaTargets = %q, want %q", got, expected)
+	}
+
+	empty := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	if got := f.extractField(empty, "_hpaTargets", time.Time{}); got != "<none>" {
+		t.Errorf("_hpaTargets without metrics = %q, want <none>", got)
+	}
+}
+
+func TestFormatter_ServiceEndpoints(t *testing.T) {
+	svc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+	}}
+	endpoint := func(ip string, ready interface{}) map[string]interface{} {
+		e := map[string]interface{}{"addresses": []interface{}{ip}}
+		if ready != nil {
+			e["conditions"] = map[string]interface{}{"ready": ready}
+		}
+		return e
+	}
+	slice := func(name, service string, endpoints ...interface{}) *store.Resource {
+		return &store.Resource{Name: name, Namespace: "default", Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"labels":    map[string]interface{}{"kubernetes.io/service-name": service},
+			},
+			"endpoints": endpoints,
+		}}}
+	}
+	// A service's endpoints may be split over several slices; unknown readiness counts as ready
+	endpointSlices := []*store.Resource{
+		slice("web-abcde", "web", endpoint("10.0.0.1", true), endpoint("10.0.0.2", nil)),
+		slice("web-fghij", "web", endpoint("10.0.1.1", true), endpoint("10.0.1.2", false)),
+		slice("idle-klmno", "idle"),
+		slice("api-pqrst", "api", endpoint("10.0.2.1", true)),
+	}
+	lookup := func(gvr schema.GroupVersionResource, namespace string) []*store.Resource {
+		if gvr != EndpointSlicesGVR {
+			t.Errorf("lookup of %s, want endpointslices", gvr.Resource)
+		}
+		return endpointSlices
+	}
+
+	f := NewFormatter(config.DefaultConfig())
+	if got := f.extractField(svc, "_svcEndpoints", time.Time{}); got != "<none>" {
+		t.Errorf("_svcEndpoints without lookup = %q, want <none>", got)
+	}
+
+	f = f.WithLookup(lookup)
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"web", "3"},
+		{"idle", "<none>"},
+		{"missing", "<none>"},
+	}
+	for _, tt := range tests {
+		svc.SetName(tt.name)
+		if got := f.extractField(svc, "_svcEndpoints", time.Time{}); got != tt.expected {
+			t.Errorf("_svcEndpoints(%s) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatter_Template(t *testing.T) {
+	pod := func(name, app, phase string) *store.Resource {
+		return &store.Resource{Name: name, Namespace: "default", Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": "default", "labels": map[string]interface{}{"app": app}},
+			"status":   map[string]interface{}{"phase": phase},
+		}}}
+	}
+	resources := []*store.Resource{pod("web-1", "web", "Running"), pod("db-1", "db", "Pending")}
+
+	f := NewFormatter(config.DefaultConfig())
+	tmpl := template.Must(template.New("test").Parse("{{.Name}}\t{{index .Labels \"app\"}}\t{{.Columns.STATUS}}\n{{.Object.status.phase}}"))
+
+	var buf bytes.Buffer
+	if err := f.FormatTemplateTo(&buf, resources, "pods", tmpl); err != nil {
+		t.Fatalf("FormatTemplateTo() error = %v", err)
+	}
+	// Newlines in the template output are flattened so each resource stays on one line
+	want := "web-1\tweb\tRunning Running\ndb-1\tdb\tPending Pending"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatTemplateTo() = %q, want %q", got, want)
+	}
+
+	failing := template.Must(template.New("test").Parse("{{.Name.Missing}}"))
+	buf.Reset()
+	if err := f.FormatTemplateTo(&buf, resources, "pods", failing); err == nil || !strings.Contains(err.Error(), "web-1") {
+		t.Errorf("FormatTemplateTo() error = %v, want error naming web-1", err)
+	}
+}
+
+func TestFormatter_Wide(t *testing.T) {
+	pod := &store.Resource{Name: "web", Namespace: "default", Object: &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec":     map[string]interface{}{"nodeName": "node-1"},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"podIP": "10.0.0.7",
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "restartCount": int64(2)},
+				map[string]interface{}{"name": "sidecar", "restartCount": int64(1)},
+			},
+		},
+	}}}
+	resources := []*store.Resource{pod}
+
+	f := NewFormatter(config.DefaultConfig())
+	if got := f.Format(resources, "pods"); strings.Contains(got, "node-1") {
+		t.Errorf("normal output should not contain the node: %q", got)
+	}
+
+	fields := strings.Fields(f.WithWide(true).Format(resources, "pods"))
+	want := []string{"web", "default", "Running", "3"}
+	if len(fields) < len(want) || !slices.Equal(fields[:len(want)], want) {
+		t.Errorf("wide fields = %q, want prefix %q", fields, want)
+	}
+	if !slices.Contains(fields, "10.0.0.7") || !slices.Contains(fields, "node-1") {
+		t.Errorf("wide output should contain IP and node: %q", fields)
+	}
+}
+
+func TestFormatter_BoolColumns(t *testing.T) {
+	cronJob := func(spec map[string]interface{}) *store.Resource {
+		return &store.Resource{Name: "backup", Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "backup"},
+			"spec":     spec,
+		}}}
+	}
+	col := config.ColumnConfig{Name: "SUSPEND", Field: ".spec.suspend", Bool: "Suspended/Active"}
+
+	f := NewFormatter(config.DefaultConfig())
+	tests := []struct {
+		name string
+		spec map[string]interface{}
+		want string
+	}{
+		{"true", map[string]interface{}{"suspend": true}, "Suspended"},
+		{"false", map[string]interface{}{"suspend": false}, "Active"},
+		{"missing", map[string]interface{}{}, "Active"},
+		{"not a bool", map[string]interface{}{"suspend": "maybe"}, "maybe"},
+	}
+	for _, tt := range tests {
+		if got := f.extractColumn(cronJob(tt.spec), col); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Malformed labels leave the raw value
+	col.Bool = "Suspended"
+	if got := f.extractColumn(cronJob(map[string]interface{}{"suspend": true}), col); got != "true" {
+		t.Errorf("malformed bool labels: got %q, want raw true", got)
+	}
+
+	if got := f.colorize("✓", "ENABLED", 2); got != colorGreen+"✓"+colorReset {
+		t.Errorf("colorize(✓) = %q, want green", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		ok      bool
+	}{
+		{"", "nginx", true},
+		{"ngx", "nginx-7d4b9", true},
+		{"NGX", "nginx-7d4b9", false},
+		{"xgn", "nginx-7d4b9", false},
+		{"api", "web-5f6c8", false},
+	}
+	for _, tt := range tests {
+		if _, ok := Match(tt.pattern, tt.text); ok != tt.ok {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.ok)
+		}
+	}
+
+	// Consecutive characters and word starts score higher than scattered ones
+	prefix, _ := Match("web", "web-5f6c8")
+	word, _ := Match("web", "api-web")
+	scattered, _ := Match("web", "wide-ebb")
+	if prefix <= word || word <= scattered {
+		t.Errorf("scores prefix=%d, word=%d, scattered=%d, want decreasing", prefix, word, scattered)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		want    []int
+	}{
+		{"", "nginx", nil},
+		{"ngx", "nginx-7d4b9", []int{0, 1, 4}},
+		{"web", "api-web", []int{4, 5, 6}},
+		{"Web", "api-web", nil},
+		// The shortest window ending at the first match, like Match scores
+		{"ab", "a-a-b", []int{2, 4}},
+	}
+	for _, tt := range tests {
+		if got := MatchPositions(tt.pattern, tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("MatchPositions(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMatchResources(t *testing.T) {
+	var resources []*store.Resource
+	for _, name := range []string{"api-web", "db", "web", "web-5f6c8", "wide-ebb"} {
+		resources = append(resources, &store.Resource{Name: name})
+	}
+
+	var got []string
+	for _, res := range MatchResources(resources, "web") {
+		got = append(got, res.Name)
+	}
+	want := []string{"web", "web-5f6c8", "api-web", "wide-ebb"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MatchResources() = %v, want %v", got, want)
+	}
+}
+
+// benchmarkResources builds n pods shaped like real cached objects
+func benchmarkResources(n int) []*store.Resource {
+	resources := make([]*store.Resource, n)
+	now := time.Now()
+	for i := range resources {
+		name := "app-" + strings.Repeat("x", i%20) + "-" + time.Duration(i).String()
+		resources[i] = &store.Resource{
+			Name:      name,
+			Namespace: "default",
+			Object: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      name,
+						"namespace": "default",
+					},
+					"spec": map[string]interface{}{
+						"nodeName": "worker-1",
+					},
+					"status": map[string]interface{}{
+						"phase": "Running",
+						"podIP": "10.0.0.1",
+					},
+				},
+			},
+			CreationTimestamp: now.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return resources
+}
+
+func BenchmarkFormat(b *testing.B) {
+	f := NewFormatter(config.DefaultConfig())
+	resources := benchmarkResources(20000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Format(resources, "pods")
+	}
+}
+
+func BenchmarkFormatWithHeader(b *testing.B) {
+	f := NewFormatter(config.DefaultConfig())
+	resources := benchmarkResources(20000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.FormatWithHeader(resources, "pods")
+	}
+}
@@ -0,0 +1,155 @@
+package fzf
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pslijkhuis/kfzf/internal/store"
+)
+
+// Scores of a match, modeled on fzf's: every matched character scores, with bonuses for
+// runs of consecutive characters and for characters at the start of a word (more so at
+// the start of the name), and a penalty for each character skipped inside the match
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 8
+	bonusBoundary    = 8
+	bonusFirst       = 8
+	penaltyGap       = 1
+)
+
+// Match reports whether the characters of pattern appear in text in order (not
+// necessarily adjacent), and scores the match; higher is better. Matching ignores case
+// unless pattern contains an upper case letter, like fzf's smart case.
+func Match(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	text = matchText(pattern, text)
+	start, end := matchWindow(pattern, text)
+	if end < 0 {
+		return 0, false
+	}
+
+	score := 0
+	p := 0
+	consecutive := false
+	for i := start; i <= end; i++ {
+		if text[i] != pattern[p] {
+			score -= penaltyGap
+			consecutive = false
+			continue
+		}
+		score += scoreMatch
+		if consecutive {
+			score += bonusConsecutive
+		}
+		if i == 0 {
+			score += bonusBoundary + bonusFirst
+		} else if isWordSeparator(text[i-1]) {
+			score += bonusBoundary
+		}
+		consecutive = true
+		p++
+	}
+	return score, true
+}
+
+// MatchPositions returns the byte offsets in text of the characters matched by pattern,
+// in the same window Match scores, or nil if pattern is empty or doesn't match
+func MatchPositions(pattern, text string) []int {
+	if pattern == "" {
+		return nil
+	}
+	matched := matchText(pattern, text)
+	if len(matched) != len(text) {
+		// Lower-casing changed the length, so offsets wouldn't carry over
+		return nil
+	}
+	start, end := matchWindow(pattern, matched)
+	if end < 0 {
+		return nil
+	}
+
+	positions := make([]int, 0, len(pattern))
+	for i, p := start, 0; i <= end; i++ {
+		if matched[i] == pattern[p] {
+			positions = append(positions, i)
+			p++
+		}
+	}
+	return positions
+}
+
+// matchText returns text lower-cased for a pattern without upper case letters (smart case)
+func matchText(pattern, text string) string {
+	if pattern == strings.ToLower(pattern) {
+		return strings.ToLower(text)
+	}
+	return text
+}
+
+// matchWindow finds the end of the first match of pattern in text, then walks back from it
+// to find the shortest window ending there. end is -1 if pattern doesn't match.
+func matchWindow(pattern, text string) (start, end int) {
+	p := 0
+	end = -1
+	for i := 0; i < len(text); i++ {
+		if text[i] == pattern[p] {
+			p++
+			if p == len(pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, -1
+	}
+	start = end
+	for p = len(pattern) - 1; ; start-- {
+		if text[start] == pattern[p] {
+			if p == 0 {
+				break
+			}
+			p--
+		}
+	}
+	return start, end
+}
+
+// isWordSeparator reports whether c separates the words of a resource name
+func isWordSeparator(c byte) bool {
+	switch c {
+	case '-', '_', '.', '/', ':':
+		return true
+	}
+	return false
+}
+
+// MatchResources returns the resources whose name matches query, best match first.
+// Ties keep their order in resources, preferring shorter names first.
+func MatchResources(resources []*store.Resource, query string) []*store.Resource {
+	type scored struct {
+		resource *store.Resource
+		score    int
+	}
+	matches := make([]scored, 0, len(resources))
+	for _, res := range resources {
+		if score, ok := Match(query, res.Name); ok {
+			matches = append(matches, scored{res, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return len(a.resource.Name) - len(b.resource.Name)
+	})
+
+	result := make([]*store.Resource, len(matches))
+	for i, m := range matches {
+		result[i] = m.resource
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package fzf
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// tableRow is a row of a server-rendered table with its cells converted to strings
+type tableRow struct {
+	name      string
+	namespace string
+	cells     []string
+}
+
+// TableColumns returns the column definitions of a server-rendered table that kubectl
+// get shows: those with priority 0, or all of them when wide
+func TableColumns(definitions []metav1.TableColumnDefinition, wide bool) []metav1.TableColumnDefinition {
+	columns := make([]metav1.TableColumnDefinition, 0, len(definitions))
+	for _, def := range definitions {
+		if wide || def.Priority == 0 {
+			columns = append(columns, def)
+		}
+	}
+	return columns
+}
+
+// FormatTableTo writes a server-rendered table (see k8s.ListTable) the way kubectl get
+// prints it, with each column aligned to its widest value, but tab-separated and without
+// a header like the configured columns. The name comes first and, when withNamespace is
+// set, the namespace second. Columns with a priority above 0 are only written when the
+// formatter is wide. Rows are sorted by name, then namespace; limit caps them (0 = unlimited).
+func (f *Formatter) FormatTableTo(buf *bytes.Buffer, table *metav1.Table, withNamespace bool, limit int) {
+	var columns []int
+	nameColumn := -1
+	for i, def := range table.ColumnDefinitions {
+		switch {
+		case nameColumn < 0 && def.Format == "name":
+			nameColumn = i
+		case f.wide || def.Priority == 0:
+			columns = append(columns, i)
+		}
+	}
+
+	rows := make([]tableRow, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		var meta metav1.PartialObjectMetadata
+		if row.Object.Raw != nil {
+			_ = json.Unmarshal(row.Object.Raw, &meta)
+		}
+		r := tableRow{name: meta.Name, namespace: meta.Namespace, cells: make([]string, len(columns))}
+		if nameColumn >= 0 && nameColumn < len(row.Cells) {
+			r.name = valueString(row.Cells[nameColumn])
+		}
+		for j, i := range columns {
+			if i < len(row.Cells) {
+				r.cells[j] = sanitizeField(valueString(row.Cells[i]))
+			}
+		}
+		r.name = sanitizeName(r.name)
+		r.namespace = sanitizeName(r.namespace)
+		rows = append(rows, r)
+	}
+	slices.SortFunc(rows, func(a, b tableRow) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.namespace, b.namespace)
+	})
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	// Column widths: name, namespace, then the cells
+	widths := make([]int, len(columns)+2)
+	for _, r := range rows {
+		widths[0] = max(widths[0], utf8.RuneCountInString(r.name))
+		widths[1] = max(widths[1], utf8.RuneCountInString(r.namespace))
+		for j, cell := range r.cells {
+			widths[j+2] = max(widths[j+2], utf8.RuneCountInString(cell))
+		}
+	}
+
+	for i, r := range rows {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		values := append([]string{r.name, r.namespace}, r.cells...)
+		for j, value := range values {
+			if j == 1 && !withNamespace {
+				continue
+			}
+			if j > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(value)
+			if j < len(values)-1 {
+				writePadding(buf, widths[j]-utf8.RuneCountInString(value))
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package fzf
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/pslijkhuis/kfzf/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestFormatter_FormatTableTo(t *testing.T) {
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Ready", Type: "string"},
+			{Name: "Restarts", Type: "integer"},
+			{Name: "Node", Type: "string", Priority: 1},
+		},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"web-1", "1/1", float64(12), "node-a"}, Object: runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"web-1","namespace":"prod"}}`)}},
+			{Cells: []interface{}{"api", "0/1", float64(0), "node-b"}, Object: runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"api","namespace":"default"}}`)}},
+		},
+	}
+	f := NewFormatter(config.DefaultConfig())
+
+	var buf bytes.Buffer
+	f.FormatTableTo(&buf, table, true, 0)
+	want := "api  \tdefault\t0/1\t0\n" +
+		"web-1\tprod   \t1/1\t12"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatTableTo() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	f.WithWide(true).FormatTableTo(&buf, table, false, 1)
+	if got, want := buf.String(), "api\t0/1\t0\tnode-b"; got != want {
+		t.Errorf("FormatTableTo(wide, limit 1) = %q, want %q", got, want)
+	}
+
+	var names []string
+	for _, def := range TableColumns(table.ColumnDefinitions, false) {
+		names = append(names, def.Name)
+	}
+	if want := []string{"Name", "Ready", "Restarts"}; !slices.Equal(names, want) {
+		t.Errorf("TableColumns() = %v, want %v", names, want)
+	}
+}
@@ -1,9 +1,11 @@
 package k8s
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,58 +16,140 @@ import (
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
+// InClusterContext is the name of the synthetic context used when running inside a pod
+const InClusterContext = "in-cluster"
+
+// inClusterNamespaceFile holds the namespace of the pod's service account
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 // ClientManager manages Kubernetes clients for multiple contexts
 type ClientManager struct {
-	mu            sync.RWMutex
-	kubeConfig    api.Config
-	clients       map[string]*ContextClient
-	clientAccess  map[string]int64 // last access time (unix timestamp) per context
-	configLoader  clientcmd.ClientConfig
-	loadingRules  *clientcmd.ClientConfigLoadingRules
-
-	// Cached current context with file modification tracking
+	mu           sync.RWMutex
+	kubeConfig   api.Config
+	clients      map[string]*ContextClient
+	clientAccess map[string]int64 // last access time (unix timestamp) per context
+	loadingRules *clientcmd.ClientConfigLoadingRules
+
+	// Cached current context with file modification tracking across all kubeconfig files
 	cachedCurrentContext string
 	configModTime        time.Time
-	configPath           string
+	configPaths          []string
+
+	// In-cluster mode: set when no kubeconfig is present and we run inside a pod
+	inClusterConfig    *rest.Config
+	inClusterNamespace string
+
+	options ClientOptions
+}
+
+// ClientOptions configures how clients are created for every context
+type ClientOptions struct {
+	// Impersonate makes requests as another user/groups (like kubectl --as/--as-group)
+	Impersonate rest.ImpersonationConfig
+	// Limits returns the rate limits and timeout for a context (nil = defaults)
+	Limits func(contextName string) ClientLimits
+}
+
+// ClientLimits holds rate limits and request timeout for a context's clients
+type ClientLimits struct {
+	QPS     float32
+	Burst   int
+	Timeout time.Duration // applies to list and discovery requests, not watch streams
 }
 
+// Default rate limits, tuned for watch-heavy workloads
+const (
+	defaultQPS   = 50
+	defaultBurst = 100
+)
+
 // ContextClient holds clients for a specific context
 type ContextClient struct {
 	Context         string
 	DynamicClient   dynamic.Interface
 	DiscoveryClient discovery.DiscoveryInterface
 	RestConfig      *rest.Config
-	Namespace       string // default namespace for this context
+	Namespace       string        // default namespace for this context
+	Timeout         time.Duration // timeout for list requests (0 = none)
 }
 
 // NewClientManager creates a new client manager
-func NewClientManager() (*ClientManager, error) {
+func NewClientManager(options ClientOptions) (*ClientManager, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	configLoader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
 
-	rawConfig, err := configLoader.RawConfig()
+	m := &ClientManager{
+		clients:      make(map[string]*ContextClient),
+		clientAccess: make(map[string]int64),
+		loadingRules: loadingRules,
+		options:      options,
+	}
+
+	rawConfig, err := m.rawConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
-	// Get the kubeconfig path and its modification time
-	configPath := KubeconfigPath()
-	var modTime time.Time
-	if stat, err := os.Stat(configPath); err == nil {
-		modTime = stat.ModTime()
+	// Fall back to the pod's service account when there is no kubeconfig
+	if len(rawConfig.Contexts) == 0 {
+		if inClusterConfig, err := rest.InClusterConfig(); err == nil {
+			m.inClusterConfig = inClusterConfig
+			m.inClusterNamespace = readInClusterNamespace()
+			rawConfig = m.withInClusterContext(rawConfig)
+		}
 	}
 
-	return &ClientManager{
-		kubeConfig:           rawConfig,
-		clients:              make(map[string]*ContextClient),
-		clientAccess:         make(map[string]int64),
-		configLoader:         configLoader,
-		loadingRules:         loadingRules,
-		cachedCurrentContext: rawConfig.CurrentContext,
-		configModTime:        modTime,
-		configPath:           configPath,
-	}, nil
+	// Track the modification time of every file that contributes to the merged config
+	m.configPaths = loadingRules.GetLoadingPrecedence()
+	m.configModTime, _ = latestModTime(m.configPaths)
+
+	m.kubeConfig = rawConfig
+	m.cachedCurrentContext = rawConfig.CurrentContext
+
+	return m, nil
+}
+
+// InCluster returns whether the manager is using in-cluster configuration
+func (m *ClientManager) InCluster() bool {
+	return m.inClusterConfig != nil
+}
+
+// rawConfig reads and merges the kubeconfig files from disk, adding the in-cluster context
+// when in in-cluster mode. It loads through the loading rules on every call: a
+// ClientConfig caches its first load, so it would never see later edits.
+func (m *ClientManager) rawConfig() (api.Config, error) {
+	loaded, err := m.loadingRules.Load()
+	if err != nil {
+		return api.Config{}, err
+	}
+	rawConfig := *loaded
+	if m.inClusterConfig != nil {
+		rawConfig = m.withInClusterContext(rawConfig)
+	}
+	return rawConfig, nil
+}
+
+// withInClusterContext adds the synthetic in-cluster context and makes it current if none is set
+func (m *ClientManager) withInClusterContext(rawConfig api.Config) api.Config {
+	if rawConfig.Contexts == nil {
+		rawConfig.Contexts = make(map[string]*api.Context)
+	}
+	rawConfig.Contexts[InClusterContext] = &api.Context{Namespace: m.inClusterNamespace}
+	if rawConfig.CurrentContext == "" {
+		rawConfig.CurrentContext = InClusterContext
+	}
+	return rawConfig
+}
+
+// readInClusterNamespace returns the pod's namespace, defaulting to "default"
+func readInClusterNamespace() string {
+	data, err := os.ReadFile(inClusterNamespaceFile)
+	if err != nil {
+		return "default"
+	}
+	if ns := strings.TrimSpace(string(data)); ns != "" {
+		return ns
+	}
+	return "default"
 }
 
 // GetClient returns a client for the specified context
@@ -103,39 +187,22 @@ func (m *ClientManager) GetClient(contextName string) (*ContextClient, error) {
 }
 
 // GetCurrentContext returns the name of the current context
-// It re-reads from the kubeconfig file only if the file has been modified
+// It re-reads the kubeconfig only if one of its files has been modified
 func (m *ClientManager) GetCurrentContext() string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Check if kubeconfig file has been modified
-	stat, err := os.Stat(m.configPath)
-	if err != nil {
-		// Can't stat file, return cached value
-		return m.cachedCurrentContext
-	}
-
-	// If file hasn't changed, return cached value
-	if !stat.ModTime().After(m.configModTime) {
-		return m.cachedCurrentContext
-	}
-
-	// File changed, reload config
-	rawConfig, err := m.configLoader.RawConfig()
-	if err != nil {
-		return m.cachedCurrentContext
-	}
-
-	// Update cache
-	m.configModTime = stat.ModTime()
-	m.cachedCurrentContext = rawConfig.CurrentContext
-	m.kubeConfig = rawConfig
-
+	m.reloadIfModified()
 	return m.cachedCurrentContext
 }
 
-// ListContexts returns all available context names
+// ListContexts returns all available context names, merged from every kubeconfig file
+// It re-reads the kubeconfig only if one of its files has been modified
 func (m *ClientManager) ListContexts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reloadIfModified()
 	contexts := make([]string, 0, len(m.kubeConfig.Contexts))
 	for name := range m.kubeConfig.Contexts {
 		contexts = append(contexts, name)
@@ -143,40 +210,83 @@ func (m *ClientManager) ListContexts() []string {
 	return contexts
 }
 
+// reloadIfModified re-reads the kubeconfig when any of its files was modified, added or
+// removed since the last load, keeping the cached config if it can't be read.
+// The caller must hold m.mu for writing.
+func (m *ClientManager) reloadIfModified() {
+	// Can't stat any file, keep the cached config
+	modTime, ok := latestModTime(m.configPaths)
+	if !ok || modTime.Equal(m.configModTime) {
+		return
+	}
+
+	rawConfig, err := m.rawConfig()
+	if err != nil {
+		return
+	}
+
+	m.configModTime = modTime
+	m.cachedCurrentContext = rawConfig.CurrentContext
+	m.kubeConfig = rawConfig
+}
+
 // createClient creates a new client for the specified context
 func (m *ClientManager) createClient(contextName string) (*ContextClient, error) {
-	// Create config for specific context
-	configOverrides := &clientcmd.ConfigOverrides{
-		CurrentContext: contextName,
-	}
+	var restConfig *rest.Config
+	var namespace string
+
+	if m.inClusterConfig != nil && contextName == InClusterContext {
+		restConfig = rest.CopyConfig(m.inClusterConfig)
+		namespace = m.inClusterNamespace
+	} else {
+		// Create config for specific context
+		configOverrides := &clientcmd.ConfigOverrides{
+			CurrentContext: contextName,
+		}
 
-	contextConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		m.loadingRules,
-		configOverrides,
-	)
+		contextConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			m.loadingRules,
+			configOverrides,
+		)
 
-	restConfig, err := contextConfig.ClientConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create rest config for context %s: %w", contextName, err)
+		var err error
+		restConfig, err = contextConfig.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rest config for context %s: %w", contextName, err)
+		}
+
+		namespace, _, err = contextConfig.Namespace()
+		if err != nil {
+			namespace = "default"
+		}
+	}
+
+	// Apply impersonation if configured
+	if m.options.Impersonate.UserName != "" || len(m.options.Impersonate.Groups) > 0 {
+		restConfig.Impersonate = m.options.Impersonate
 	}
 
 	// Increase QPS and Burst for watch operations
-	restConfig.QPS = 50
-	restConfig.Burst = 100
+	limits := ClientLimits{QPS: defaultQPS, Burst: defaultBurst}
+	if m.options.Limits != nil {
+		limits = m.options.Limits(contextName)
+	}
+	restConfig.QPS = limits.QPS
+	restConfig.Burst = limits.Burst
 
 	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dynamic client for context %s: %w", contextName, err)
 	}
 
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create discovery client for context %s: %w", contextName, err)
-	}
+	// The timeout only applies to discovery: rest.Config.Timeout would also cut
+	// long-running watch streams, so lists get a per-request context timeout instead
+	discoveryConfig := rest.CopyConfig(restConfig)
+	discoveryConfig.Timeout = limits.Timeout
 
-	namespace, _, err := contextConfig.Namespace()
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(discoveryConfig)
 	if err != nil {
-		namespace = "default"
+		return nil, fmt.Errorf("failed to create discovery client for context %s: %w", contextName, err)
 	}
 
 	return &ContextClient{
@@ -185,6 +295,7 @@ func (m *ClientManager) createClient(contextName string) (*ContextClient, error)
 		DiscoveryClient: discoveryClient,
 		RestConfig:      restConfig,
 		Namespace:       namespace,
+		Timeout:         limits.Timeout,
 	}, nil
 }
 
@@ -193,12 +304,13 @@ func (m *ClientManager) RefreshConfig() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	rawConfig, err := m.configLoader.RawConfig()
+	rawConfig, err := m.rawConfig()
 	if err != nil {
 		return fmt.Errorf("failed to reload kubeconfig: %w", err)
 	}
 
 	m.kubeConfig = rawConfig
+	m.cachedCurrentContext = rawConfig.CurrentContext
 	// Clear cached clients as contexts may have changed
 	m.clients = make(map[string]*ContextClient)
 	m.clientAccess = make(map[string]int64)
@@ -206,6 +318,87 @@ func (m *ClientManager) RefreshConfig() error {
 	return nil
 }
 
+// ReloadConfig reloads the kubeconfig from disk, keeping the cached clients. Contexts
+// without a client yet, and the current context, follow the new kubeconfig.
+func (m *ClientManager) ReloadConfig() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rawConfig, err := m.rawConfig()
+	if err != nil {
+		return fmt.Errorf("failed to reload kubeconfig: %w", err)
+	}
+
+	m.kubeConfig = rawConfig
+	m.cachedCurrentContext = rawConfig.CurrentContext
+
+	return nil
+}
+
+// RefreshClientsOnly reloads the kubeconfig from disk and rebuilds the cached clients
+// in place, e.g. after credentials were rotated. Unlike RefreshConfig the callers'
+// caches stay valid: watches pick up the new client on their next reconnect.
+// Clients that can't be rebuilt (e.g. their context was removed) are dropped and
+// reported in the returned error.
+func (m *ClientManager) RefreshClientsOnly() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rawConfig, err := m.rawConfig()
+	if err != nil {
+		return fmt.Errorf("failed to reload kubeconfig: %w", err)
+	}
+
+	m.kubeConfig = rawConfig
+	m.cachedCurrentContext = rawConfig.CurrentContext
+
+	var errs []error
+	for contextName := range m.clients {
+		client, err := m.createClient(contextName)
+		if err != nil {
+			delete(m.clients, contextName)
+			delete(m.clientAccess, contextName)
+			errs = append(errs, err)
+			continue
+		}
+		m.clients[contextName] = client
+	}
+
+	return errors.Join(errs...)
+}
+
+// RefreshContext reloads the kubeconfig from disk but only drops the cached client for
+// one context, so other contexts keep their clients
+func (m *ClientManager) RefreshContext(contextName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rawConfig, err := m.rawConfig()
+	if err != nil {
+		return fmt.Errorf("failed to reload kubeconfig: %w", err)
+	}
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		return fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	m.kubeConfig = rawConfig
+	m.cachedCurrentContext = rawConfig.CurrentContext
+	delete(m.clients, contextName)
+	delete(m.clientAccess, contextName)
+
+	return nil
+}
+
+// InvalidateClient drops the cached client for a context so the next GetClient rebuilds it,
+// re-running exec/OIDC credential plugins
+func (m *ClientManager) InvalidateClient(contextName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.clients, contextName)
+	delete(m.clientAccess, contextName)
+}
+
 // CleanupUnusedClients removes clients that haven't been accessed within the given duration
 func (m *ClientManager) CleanupUnusedClients(maxAge time.Duration) int {
 	m.mu.Lock()
@@ -234,6 +427,9 @@ func (m *ClientManager) ClientCount() int {
 
 // GetContextNamespace returns the default namespace for a context
 func (m *ClientManager) GetContextNamespace(contextName string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if ctx, ok := m.kubeConfig.Contexts[contextName]; ok {
 		if ctx.Namespace != "" {
 			return ctx.Namespace
@@ -242,6 +438,39 @@ func (m *ClientManager) GetContextNamespace(contextName string) string {
 	return "default"
 }
 
+// KubeconfigPath returns the kubeconfig files the manager loaded, joined like $KUBECONFIG,
+// or "in-cluster" when using the pod's service account
+func (m *ClientManager) KubeconfigPath() string {
+	if m.inClusterConfig != nil {
+		return "in-cluster"
+	}
+	return strings.Join(m.configPaths, string(filepath.ListSeparator))
+}
+
+// KubeconfigPaths returns all kubeconfig file paths in clientcmd's loading precedence
+// It respects the KUBECONFIG environment variable, which can list multiple files
+func KubeconfigPaths() []string {
+	return clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence()
+}
+
+// latestModTime returns the most recent modification time across the given files.
+// The second return value is false if none of the files could be stat'ed.
+func latestModTime(paths []string) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		found = true
+		if stat.ModTime().After(latest) {
+			latest = stat.ModTime()
+		}
+	}
+	return latest, found
+}
+
 // KubeconfigPath returns the path to the kubeconfig file
 // It respects the KUBECONFIG environment variable
 func KubeconfigPath() string {
@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeKubeconfig writes a kubeconfig with the given contexts, all pointing at one
+// unreachable cluster, and bumps its mod time so mod-time checks always see the change
+func writeKubeconfig(t *testing.T, path, current string, contexts ...string) {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("apiVersion: v1\nkind: Config\nclusters:\n- name: local\n  cluster: {server: \"https://127.0.0.1:1\"}\nusers:\n- name: user\ncontexts:\n")
+	for _, name := range contexts {
+		fmt.Fprintf(&b, "- name: %s\n  context: {cluster: local, user: user}\n", name)
+	}
+	fmt.Fprintf(&b, "current-context: %s\n", current)
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Duration(len(contexts)) * time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestClientManager returns a manager for a temp kubeconfig holding context "a"
+func newTestClientManager(t *testing.T) (*ClientManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	writeKubeconfig(t, kubeconfig, "a", "a")
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	m, err := NewClientManager(ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClientManager failed: %v", err)
+	}
+	return m, kubeconfig
+}
+
+// TestRefreshConfig_ReadsEdits tests that a reload sees a changed current context and new contexts
+func TestRefreshConfig_ReadsEdits(t *testing.T) {
+	m, kubeconfig := newTestClientManager(t)
+
+	writeKubeconfig(t, kubeconfig, "b", "a", "b")
+	if err := m.RefreshConfig(); err != nil {
+		t.Fatalf("RefreshConfig failed: %v", err)
+	}
+
+	m.mu.RLock()
+	current := m.cachedCurrentContext
+	_, hasB := m.kubeConfig.Contexts["b"]
+	m.mu.RUnlock()
+	if current != "b" {
+		t.Errorf("current context = %q, want b", current)
+	}
+	if !hasB {
+		t.Error("context b missing after RefreshConfig")
+	}
+}
+
+// TestRefreshContext_NewContext tests that a context added after startup can be refreshed
+func TestRefreshContext_NewContext(t *testing.T) {
+	m, kubeconfig := newTestClientManager(t)
+
+	writeKubeconfig(t, kubeconfig, "a", "a", "b")
+	if err := m.RefreshContext("b"); err != nil {
+		t.Fatalf("RefreshContext failed: %v", err)
+	}
+	if err := m.RefreshContext("missing"); err == nil {
+		t.Error("RefreshContext succeeded for a context not in the kubeconfig")
+	}
+}
+
+// TestListContexts_ReloadsModifiedFiles tests that contexts from a kubeconfig file edited
+// or created after startup are listed
+func TestListContexts_ReloadsModifiedFiles(t *testing.T) {
+	m, kubeconfig := newTestClientManager(t)
+
+	writeKubeconfig(t, kubeconfig, "a", "a", "b")
+	if got := m.ListContexts(); !slices.Contains(got, "b") {
+		t.Errorf("ListContexts() = %v, want the edited file's context b", got)
+	}
+
+	// A file listed in KUBECONFIG that didn't exist at startup
+	extra := filepath.Join(filepath.Dir(kubeconfig), "extra")
+	t.Setenv("KUBECONFIG", kubeconfig+string(filepath.ListSeparator)+extra)
+	m, err := NewClientManager(ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClientManager failed: %v", err)
+	}
+	writeKubeconfig(t, extra, "", "c", "d")
+	if got := m.ListContexts(); !slices.Contains(got, "d") {
+		t.Errorf("ListContexts() = %v, want the new file's context d", got)
+	}
+}
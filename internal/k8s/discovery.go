@@ -1,11 +1,18 @@
 package k8s
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
 )
 
 // ResourceInfo contains information about a Kubernetes resource type
@@ -17,50 +24,136 @@ type ResourceInfo struct {
 	Verbs      []string
 }
 
-// DiscoverResources discovers all available API resources in a cluster
-func DiscoverResources(client *ContextClient) ([]ResourceInfo, error) {
+// ServerVersion returns the Kubernetes version reported by the API server (e.g. "v1.30.2").
+// It is a single cheap request, so with a short deadline on ctx it doubles as a probe
+// of whether the cluster is reachable.
+func ServerVersion(ctx context.Context, client *ContextClient) (string, error) {
+	data, err := client.DiscoveryClient.RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return "", fmt.Errorf("failed to get server version: %w", err)
+	}
+	var info version.Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return "", fmt.Errorf("failed to decode server version: %w", err)
+	}
+	return info.GitVersion, nil
+}
+
+// DiscoverResources discovers all available API resources in a cluster. Skipped
+// resources and failed API groups are reported to logger.
+func DiscoverResources(client *ContextClient, logger *slog.Logger) ([]ResourceInfo, error) {
 	_, apiResourceLists, err := client.DiscoveryClient.ServerGroupsAndResources()
 	if err != nil {
-		// Some resources may fail to discover, but we can still use the ones that work
-		if !strings.Contains(err.Error(), "unable to retrieve") {
+		// Groups that fail to discover (e.g. an aggregated API that is down) are
+		// reported separately; the resources of all other groups are still usable
+		var groupErr *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &groupErr) {
 			return nil, fmt.Errorf("failed to discover API resources: %w", err)
 		}
+		failed := make([]string, 0, len(groupErr.Groups))
+		for gv := range groupErr.Groups {
+			failed = append(failed, gv.String())
+		}
+		slices.Sort(failed)
+		logger.Warn("partial API discovery failure", "context", client.Context, "groups", failed)
 	}
 
 	var resources []ResourceInfo
-
 	for _, apiResourceList := range apiResourceLists {
-		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+		resources = appendResources(resources, apiResourceList, logger)
+	}
+
+	return resources, nil
+}
+
+// DiscoverGroups discovers API resources only for the given API groups ("" is the core group),
+// using the server's preferred version of each group. This avoids enumerating every
+// group/resource on clusters with many CRDs or aggregated APIs.
+func DiscoverGroups(client *ContextClient, groups []string, logger *slog.Logger) ([]ResourceInfo, error) {
+	groupList, err := client.DiscoveryClient.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		wanted[group] = true
+	}
+
+	var resources []ResourceInfo
+	for _, group := range groupList.Groups {
+		if !wanted[group.Name] {
+			continue
+		}
+		groupVersion := group.PreferredVersion.GroupVersion
+		if groupVersion == "" && len(group.Versions) > 0 {
+			groupVersion = group.Versions[0].GroupVersion
+		}
+		apiResourceList, err := client.DiscoveryClient.ServerResourcesForGroupVersion(groupVersion)
 		if err != nil {
+			// A single failing group shouldn't prevent completing the others
+			logger.Debug("skipping API group", "groupVersion", groupVersion, "error", err)
 			continue
 		}
+		resources = appendResources(resources, apiResourceList, logger)
+	}
 
-		for _, apiResource := range apiResourceList.APIResources {
-			// Skip subresources (e.g., pods/log, pods/status)
-			if strings.Contains(apiResource.Name, "/") {
-				continue
-			}
+	return resources, nil
+}
 
-			// Check if we can list and watch this resource
-			if !containsVerb(apiResource.Verbs, "list") || !containsVerb(apiResource.Verbs, "watch") {
-				continue
-			}
+// appendResources appends the listable and watchable resources of an API resource list.
+// The names of skipped resources are logged at debug level, grouped by reason.
+func appendResources(resources []ResourceInfo, apiResourceList *metav1.APIResourceList, logger *slog.Logger) []ResourceInfo {
+	gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+	if err != nil {
+		return resources
+	}
 
-			resources = append(resources, ResourceInfo{
-				GVR: schema.GroupVersionResource{
-					Group:    gv.Group,
-					Version:  gv.Version,
-					Resource: apiResource.Name,
-				},
-				Kind:       apiResource.Kind,
-				Namespaced: apiResource.Namespaced,
-				ShortNames: apiResource.ShortNames,
-				Verbs:      apiResource.Verbs,
-			})
+	var subresources, unwatchable []string
+	for _, apiResource := range apiResourceList.APIResources {
+		// Skip subresources (e.g., pods/log, pods/status)
+		if strings.Contains(apiResource.Name, "/") {
+			subresources = append(subresources, apiResource.Name)
+			continue
 		}
+
+		// Check if we can list and watch this resource
+		if !containsVerb(apiResource.Verbs, "list") || !containsVerb(apiResource.Verbs, "watch") {
+			unwatchable = append(unwatchable, apiResource.Name)
+			continue
+		}
+
+		resources = append(resources, ResourceInfo{
+			GVR: schema.GroupVersionResource{
+				Group:    gv.Group,
+				Version:  gv.Version,
+				Resource: apiResource.Name,
+			},
+			Kind:       apiResource.Kind,
+			Namespaced: apiResource.Namespaced,
+			ShortNames: apiResource.ShortNames,
+			Verbs:      apiResource.Verbs,
+		})
 	}
 
-	return resources, nil
+	if len(unwatchable) > 0 {
+		logger.Debug("skipping resources without list/watch verbs",
+			"groupVersion", apiResourceList.GroupVersion, "resources", unwatchable)
+	}
+	if len(subresources) > 0 {
+		logger.Debug("skipping subresources",
+			"groupVersion", apiResourceList.GroupVersion, "resources", subresources)
+	}
+
+	return resources
+}
+
+// ResourceGroup returns the API group of a "resource.group" name, or "" for bare names
+func ResourceGroup(name string) string {
+	if _, group, ok := strings.Cut(name, "."); ok {
+		return group
+	}
+	return ""
 }
 
 // FindResource finds a resource by name, short name, kind, or resource.group format
@@ -108,46 +201,70 @@ func FindResource(resources []ResourceInfo, name string) *ResourceInfo {
 	return nil
 }
 
+// FindAllResources returns every resource whose name, short name, or kind matches
+// a bare name, e.g. to detect names served by several API groups
+func FindAllResources(resources []ResourceInfo, name string) []ResourceInfo {
+	name = strings.ToLower(name)
+
+	var matches []ResourceInfo
+	for _, r := range resources {
+		if strings.ToLower(r.GVR.Resource) == name || strings.ToLower(r.Kind) == name {
+			matches = append(matches, r)
+			continue
+		}
+		for _, shortName := range r.ShortNames {
+			if strings.ToLower(shortName) == name {
+				matches = append(matches, r)
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
 // GetPreferredGVR returns the preferred GVR for common resource types
 // This helps avoid ambiguity when multiple API groups provide the same resource
 func GetPreferredGVR(resourceName string) *schema.GroupVersionResource {
 	preferred := map[string]schema.GroupVersionResource{
-		"pods":                   {Group: "", Version: "v1", Resource: "pods"},
-		"po":                     {Group: "", Version: "v1", Resource: "pods"},
-		"services":               {Group: "", Version: "v1", Resource: "services"},
-		"svc":                    {Group: "", Version: "v1", Resource: "services"},
-		"nodes":                  {Group: "", Version: "v1", Resource: "nodes"},
-		"no":                     {Group: "", Version: "v1", Resource: "nodes"},
-		"namespaces":             {Group: "", Version: "v1", Resource: "namespaces"},
-		"ns":                     {Group: "", Version: "v1", Resource: "namespaces"},
-		"configmaps":             {Group: "", Version: "v1", Resource: "configmaps"},
-		"cm":                     {Group: "", Version: "v1", Resource: "configmaps"},
-		"secrets":                {Group: "", Version: "v1", Resource: "secrets"},
-		"persistentvolumes":      {Group: "", Version: "v1", Resource: "persistentvolumes"},
-		"pv":                     {Group: "", Version: "v1", Resource: "persistentvolumes"},
-		"persistentvolumeclaims": {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
-		"pvc":                    {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
-		"serviceaccounts":        {Group: "", Version: "v1", Resource: "serviceaccounts"},
-		"sa":                     {Group: "", Version: "v1", Resource: "serviceaccounts"},
-		"events":                 {Group: "", Version: "v1", Resource: "events"},
-		"ev":                     {Group: "", Version: "v1", Resource: "events"},
-		"endpoints":              {Group: "", Version: "v1", Resource: "endpoints"},
-		"ep":                     {Group: "", Version: "v1", Resource: "endpoints"},
-		"deployments":            {Group: "apps", Version: "v1", Resource: "deployments"},
-		"deploy":                 {Group: "apps", Version: "v1", Resource: "deployments"},
-		"replicasets":            {Group: "apps", Version: "v1", Resource: "replicasets"},
-		"rs":                     {Group: "apps", Version: "v1", Resource: "replicasets"},
-		"statefulsets":           {Group: "apps", Version: "v1", Resource: "statefulsets"},
-		"sts":                    {Group: "apps", Version: "v1", Resource: "statefulsets"},
-		"daemonsets":             {Group: "apps", Version: "v1", Resource: "daemonsets"},
-		"ds":                     {Group: "apps", Version: "v1", Resource: "daemonsets"},
-		"jobs":                   {Group: "batch", Version: "v1", Resource: "jobs"},
-		"cronjobs":               {Group: "batch", Version: "v1", Resource: "cronjobs"},
-		"cj":                     {Group: "batch", Version: "v1", Resource: "cronjobs"},
-		"ingresses":              {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
-		"ing":                    {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
-		"networkpolicies":        {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
-		"netpol":                 {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+		"pods":                     {Group: "", Version: "v1", Resource: "pods"},
+		"po":                       {Group: "", Version: "v1", Resource: "pods"},
+		"services":                 {Group: "", Version: "v1", Resource: "services"},
+		"svc":                      {Group: "", Version: "v1", Resource: "services"},
+		"nodes":                    {Group: "", Version: "v1", Resource: "nodes"},
+		"no":                       {Group: "", Version: "v1", Resource: "nodes"},
+		"namespaces":               {Group: "", Version: "v1", Resource: "namespaces"},
+		"ns":                       {Group: "", Version: "v1", Resource: "namespaces"},
+		"configmaps":               {Group: "", Version: "v1", Resource: "configmaps"},
+		"cm":                       {Group: "", Version: "v1", Resource: "configmaps"},
+		"secrets":                  {Group: "", Version: "v1", Resource: "secrets"},
+		"persistentvolumes":        {Group: "", Version: "v1", Resource: "persistentvolumes"},
+		"pv":                       {Group: "", Version: "v1", Resource: "persistentvolumes"},
+		"persistentvolumeclaims":   {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+		"pvc":                      {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+		"serviceaccounts":          {Group: "", Version: "v1", Resource: "serviceaccounts"},
+		"sa":                       {Group: "", Version: "v1", Resource: "serviceaccounts"},
+		"events":                   {Group: "", Version: "v1", Resource: "events"},
+		"ev":                       {Group: "", Version: "v1", Resource: "events"},
+		"endpoints":                {Group: "", Version: "v1", Resource: "endpoints"},
+		"ep":                       {Group: "", Version: "v1", Resource: "endpoints"},
+		"deployments":              {Group: "apps", Version: "v1", Resource: "deployments"},
+		"deploy":                   {Group: "apps", Version: "v1", Resource: "deployments"},
+		"replicasets":              {Group: "apps", Version: "v1", Resource: "replicasets"},
+		"rs":                       {Group: "apps", Version: "v1", Resource: "replicasets"},
+		"statefulsets":             {Group: "apps", Version: "v1", Resource: "statefulsets"},
+		"sts":                      {Group: "apps", Version: "v1", Resource: "statefulsets"},
+		"daemonsets":               {Group: "apps", Version: "v1", Resource: "daemonsets"},
+		"ds":                       {Group: "apps", Version: "v1", Resource: "daemonsets"},
+		"jobs":                     {Group: "batch", Version: "v1", Resource: "jobs"},
+		"cronjobs":                 {Group: "batch", Version: "v1", Resource: "cronjobs"},
+		"cj":                       {Group: "batch", Version: "v1", Resource: "cronjobs"},
+		"ingresses":                {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		"ing":                      {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		"networkpolicies":          {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+		"netpol":                   {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+		"horizontalpodautoscalers": {Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
+		"hpa":                      {Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
 	}
 
 	name := strings.ToLower(resourceName)
@@ -190,6 +307,7 @@ var ResourceAliases = map[string]string{
 	"ing":         "ingresses",
 	"ingress":     "ingresses",
 	"netpol":      "networkpolicies",
+	"hpa":         "horizontalpodautoscalers",
 	// ArgoCD resources
 	"app":            "applications.argoproj.io",
 	"application":    "applications.argoproj.io",
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// kubeconfigDebounce is how long to wait after the last write before reloading,
+// so partial saves don't trigger a reload of a half-written file
+const kubeconfigDebounce = 500 * time.Millisecond
+
+// WatchKubeconfig watches all kubeconfig files for changes and reloads the config
+// (dropping cached clients so rotated credentials are picked up) after writes settle.
+// Parent directories are watched so editors that save via rename are handled.
+// It blocks until ctx is cancelled.
+func (m *ClientManager) WatchKubeconfig(ctx context.Context, logger *slog.Logger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create kubeconfig watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, path := range KubeconfigPaths() {
+		files[filepath.Clean(path)] = true
+		dirs[filepath.Dir(path)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			logger.Warn("failed to watch kubeconfig directory", "dir", dir, "error", err)
+		}
+	}
+
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !files[filepath.Clean(event.Name)] {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			debounce = time.After(kubeconfigDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warn("kubeconfig watcher error", "error", err)
+		case <-debounce:
+			debounce = nil
+			if err := m.RefreshConfig(); err != nil {
+				logger.Warn("failed to reload kubeconfig", "error", err)
+				continue
+			}
+			logger.Info("kubeconfig changed, reloaded", "context", m.GetCurrentContext())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// tableAccept asks the API server to render a list as a Table, the format kubectl get
+// prints, falling back to plain JSON for servers without the Table API
+const tableAccept = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// ListTable lists a resource type with server-side printing and returns the table,
+// with the object metadata of each row. An empty namespace lists all namespaces. The
+// list is fetched in pages of listPageSize; limit stops after the page reaching that
+// many rows (0 = unlimited).
+func ListTable(ctx context.Context, client *ContextClient, gvr schema.GroupVersionResource, namespace string, limit int) (*metav1.Table, error) {
+	if client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.Timeout)
+		defer cancel()
+	}
+
+	pageSize := listPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	var table *metav1.Table
+	continueToken := ""
+	for {
+		page, err := listTablePage(ctx, client, gvr, namespace, pageSize, continueToken)
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			table = page
+		} else {
+			table.Rows = append(table.Rows, page.Rows...)
+		}
+		continueToken = page.Continue
+		if continueToken == "" || (limit > 0 && len(table.Rows) >= limit) {
+			break
+		}
+	}
+	table.Continue = ""
+	return table, nil
+}
+
+// listTablePage lists one page of a resource type as a table
+func listTablePage(ctx context.Context, client *ContextClient, gvr schema.GroupVersionResource, namespace string, pageSize int, continueToken string) (*metav1.Table, error) {
+	req := client.DiscoveryClient.RESTClient().Get().
+		AbsPath(resourcePath(gvr, namespace)...).
+		SetHeader("Accept", tableAccept).
+		Param("includeObject", string(metav1.IncludeMetadata)).
+		Param("limit", strconv.Itoa(pageSize))
+	if continueToken != "" {
+		req = req.Param("continue", continueToken)
+	}
+	data, err := req.Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	table := &metav1.Table{}
+	if err := json.Unmarshal(data, table); err != nil {
+		return nil, fmt.Errorf("failed to decode table: %w", err)
+	}
+	if table.Kind != "Table" {
+		return nil, fmt.Errorf("server did not return a table for %s", gvr.Resource)
+	}
+	return table, nil
+}
+
+// resourcePath returns the API path segments for listing a resource type
+func resourcePath(gvr schema.GroupVersionResource, namespace string) []string {
+	path := []string{"/apis", gvr.Group, gvr.Version}
+	if gvr.Group == "" {
+		path = []string{"/api", gvr.Version}
+	}
+	if namespace != "" {
+		path = append(path, "namespaces", namespace)
+	}
+	return append(path, gvr.Resource)
+}
@@ -2,27 +2,69 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pslijkhuis/kfzf/internal/store"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
+// maxConcurrentLists bounds how many initial lists run at once, so a burst of new
+// watches (e.g. the default set for a new context) doesn't flood the API server
+const maxConcurrentLists = 8
+
+// listPageSize is the number of objects fetched per request by one-off lists, which are
+// paginated so a large resource type doesn't come back as one huge response
+const listPageSize = 500
+
+// watchBackoffJitter spreads retries by up to this fraction of the backoff, so watches
+// that failed together (e.g. after an API server blip) don't reconnect in lockstep
+const watchBackoffJitter = 0.2
+
+// WatchBackoff configures the delay between retries of a failing watch, doubling
+// from Initial up to Max
+type WatchBackoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// errWatchRestarted is returned by runWatch when RestartWatch was called
+var errWatchRestarted = errors.New("watch restarted")
+
+// DefaultWatchBackoff is used when no backoff is configured
+var DefaultWatchBackoff = WatchBackoff{Initial: time.Second, Max: 5 * time.Minute}
+
 // WatchManager manages watches for multiple contexts and resource types
 type WatchManager struct {
 	clientManager *ClientManager
 	store         *store.Store
 	logger        *slog.Logger
 
+	// Backoff configures watch retries; set before starting watches
+	Backoff WatchBackoff
+
 	mu       sync.RWMutex
 	watches  map[watchKey]context.CancelFunc
-	contexts map[string]bool // contexts being actively watched
+	contexts map[string]bool        // contexts being actively watched
+	lastSync map[watchKey]time.Time // time of the last successful list per watch
+	restarts map[watchKey]chan struct{}
+
+	// Semaphore for limiting concurrent initial lists
+	listSemaphore chan struct{}
+
+	// Watch events received, across all watches
+	eventsAdded    atomic.Int64
+	eventsModified atomic.Int64
+	eventsDeleted  atomic.Int64
 }
 
 type watchKey struct {
@@ -36,12 +78,18 @@ func NewWatchManager(clientManager *ClientManager, store *store.Store, logger *s
 		clientManager: clientManager,
 		store:         store,
 		logger:        logger,
+		Backoff:       DefaultWatchBackoff,
 		watches:       make(map[watchKey]context.CancelFunc),
 		contexts:      make(map[string]bool),
+		lastSync:      make(map[watchKey]time.Time),
+		restarts:      make(map[watchKey]chan struct{}),
+		listSemaphore: make(chan struct{}, maxConcurrentLists),
 	}
 }
 
-// StartWatching starts watching a resource type in a context
+// StartWatching starts watching a resource type in a context. The watch runs until it is
+// stopped with StopWatching, StopContext or StopAll; cancelling ctx doesn't stop it, so
+// the watches stay known while the server shuts down (e.g. to persist them).
 func (m *WatchManager) StartWatching(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespaced bool) error {
 	key := watchKey{context: contextName, gvr: gvr}
 
@@ -51,15 +99,34 @@ func (m *WatchManager) StartWatching(ctx context.Context, contextName string, gv
 		return nil // Already watching
 	}
 
-	watchCtx, cancel := context.WithCancel(ctx)
+	watchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	restart := make(chan struct{}, 1)
 	m.watches[key] = cancel
+	m.restarts[key] = restart
 	m.contexts[contextName] = true
 	m.mu.Unlock()
 
-	go m.watch(watchCtx, contextName, gvr, namespaced)
+	go m.watch(watchCtx, contextName, gvr, namespaced, restart)
 	return nil
 }
 
+// RestartWatch makes a running watch re-list and reconnect, keeping the cached data
+// until the new list replaces it. It returns false if the resource isn't watched.
+func (m *WatchManager) RestartWatch(contextName string, gvr schema.GroupVersionResource) bool {
+	m.mu.RLock()
+	restart, exists := m.restarts[watchKey{context: contextName, gvr: gvr}]
+	m.mu.RUnlock()
+	if !exists {
+		return false
+	}
+
+	select {
+	case restart <- struct{}{}:
+	default: // A restart is already pending
+	}
+	return true
+}
+
 // StopWatching stops watching a resource type in a context and clears cached data
 func (m *WatchManager) StopWatching(contextName string, gvr schema.GroupVersionResource) {
 	key := watchKey{context: contextName, gvr: gvr}
@@ -70,11 +137,103 @@ func (m *WatchManager) StopWatching(contextName string, gvr schema.GroupVersionR
 	if cancel, exists := m.watches[key]; exists {
 		cancel()
 		delete(m.watches, key)
+		delete(m.lastSync, key)
+		delete(m.restarts, key)
 		m.store.SetWatching(contextName, gvr, false)
 		m.store.Clear(contextName, gvr) // Clear cached data to prevent memory leak
 	}
 }
 
+// Resync re-lists every watched resource type of a context and reconciles the store in
+// place, keeping existing data available while the lists are in flight.
+// It returns the number of resource types that were re-listed successfully, and the
+// list failures joined into one error.
+func (m *WatchManager) Resync(ctx context.Context, contextName string) (int, error) {
+	client, err := m.clientManager.GetClient(contextName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	m.mu.RLock()
+	var gvrs []schema.GroupVersionResource
+	for key := range m.watches {
+		if key.context == contextName {
+			gvrs = append(gvrs, key.gvr)
+		}
+	}
+	m.mu.RUnlock()
+
+	synced := 0
+	var errs []error
+	for _, gvr := range gvrs {
+		listCtx := ctx
+		var cancel context.CancelFunc = func() {}
+		if client.Timeout > 0 {
+			listCtx, cancel = context.WithTimeout(ctx, client.Timeout)
+		}
+		list, err := client.DynamicClient.Resource(gvr).List(listCtx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			m.logger.Warn("resync failed",
+				"context", contextName,
+				"resource", gvr.Resource,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", gvr.Resource, err))
+			continue
+		}
+
+		// The watch keeps running, so changes it made after this list win
+		m.store.Replace(contextName, gvr, list.Items, list.GetResourceVersion(), pruneObject)
+		m.recordSync(contextName, gvr)
+		synced++
+	}
+
+	m.logger.Info("resync complete", "context", contextName, "resources", synced, "failed", len(errs))
+	return synced, errors.Join(errs...)
+}
+
+// ListOnce lists a resource type a single time, without watching it or touching the
+// store, and returns the pruned objects. An empty namespace lists all namespaces. The
+// list is fetched in pages of listPageSize.
+func (m *WatchManager) ListOnce(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string) ([]*store.Resource, error) {
+	client, err := m.clientManager.GetClient(contextName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	select {
+	case m.listSemaphore <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-m.listSemaphore }()
+
+	listCtx := ctx
+	if client.Timeout > 0 {
+		var cancel context.CancelFunc
+		listCtx, cancel = context.WithTimeout(ctx, client.Timeout)
+		defer cancel()
+	}
+	var resources []*store.Resource
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		list, err := client.DynamicClient.Resource(gvr).Namespace(namespace).List(listCtx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			pruneObject(obj)
+			resources = append(resources, store.NewResource(gvr, obj))
+		}
+		if list.GetContinue() == "" {
+			return resources, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
 // StopAll stops all watches and clears all cached data
 func (m *WatchManager) StopAll() {
 	m.mu.Lock()
@@ -87,10 +246,12 @@ func (m *WatchManager) StopAll() {
 	}
 	m.watches = make(map[watchKey]context.CancelFunc)
 	m.contexts = make(map[string]bool)
+	m.lastSync = make(map[watchKey]time.Time)
+	m.restarts = make(map[watchKey]chan struct{})
 }
 
 // watch runs the watch loop for a specific resource
-func (m *WatchManager) watch(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespaced bool) {
+func (m *WatchManager) watch(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespaced bool, restart <-chan struct{}) {
 	// Ensure cleanup when goroutine exits
 	defer m.cleanupWatch(contextName, gvr)
 
@@ -100,8 +261,9 @@ func (m *WatchManager) watch(ctx context.Context, contextName string, gvr schema
 		"group", gvr.Group,
 	)
 
-	backoff := time.Second
-	maxBackoff := 5 * time.Minute
+	initialBackoff := m.Backoff.Initial
+	maxBackoff := max(m.Backoff.Max, initialBackoff)
+	backoff := initialBackoff
 
 	for {
 		select {
@@ -114,27 +276,58 @@ func (m *WatchManager) watch(ctx context.Context, contextName string, gvr schema
 		default:
 		}
 
-		err := m.runWatch(ctx, contextName, gvr, namespaced)
+		err := m.runWatch(ctx, contextName, gvr, namespaced, restart)
+		if errors.Is(err, errWatchRestarted) {
+			m.logger.Info("restarting watch",
+				"context", contextName,
+				"resource", gvr.Resource,
+			)
+			backoff = initialBackoff
+			continue
+		}
 		if err != nil {
 			if ctx.Err() != nil {
 				return // Context cancelled
 			}
-			m.logger.Warn("watch error, will retry",
-				"context", contextName,
-				"resource", gvr.Resource,
-				"error", err,
-				"backoff", backoff,
-			)
+			if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+				// Credentials may have expired (exec/OIDC tokens); rebuild the client before retrying
+				m.clientManager.InvalidateClient(contextName)
+			}
+			if apierrors.IsForbidden(err) {
+				// RBAC denials won't fix themselves quickly; retry slowly and say why
+				if backoff < maxBackoff {
+					m.logger.Warn("watch forbidden by RBAC, check permissions or impersonation settings",
+						"context", contextName,
+						"resource", gvr.Resource,
+						"error", err,
+					)
+				}
+				backoff = maxBackoff
+			} else if apierrors.IsUnauthorized(err) {
+				m.logger.Warn("watch unauthorized, recreating client",
+					"context", contextName,
+					"resource", gvr.Resource,
+					"error", err,
+					"backoff", backoff,
+				)
+			} else {
+				m.logger.Warn("watch error, will retry",
+					"context", contextName,
+					"resource", gvr.Resource,
+					"error", err,
+					"backoff", backoff,
+				)
+			}
 
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(backoff):
+			case <-time.After(wait.Jitter(backoff, watchBackoffJitter)):
 			}
 
 			backoff = min(backoff*2, maxBackoff)
 		} else {
-			backoff = time.Second // Reset backoff on success
+			backoff = initialBackoff // Reset backoff on success
 		}
 	}
 }
@@ -149,10 +342,24 @@ func (m *WatchManager) cleanupWatch(contextName string, gvr schema.GroupVersionR
 	// Only delete if the entry exists (StopWatching may have already removed it)
 	if _, exists := m.watches[key]; exists {
 		delete(m.watches, key)
+		delete(m.lastSync, key)
+		delete(m.restarts, key)
 		m.store.SetWatching(contextName, gvr, false)
 	}
 }
 
+// recordSync records a successful list of a watched resource
+func (m *WatchManager) recordSync(contextName string, gvr schema.GroupVersionResource) {
+	key := watchKey{context: contextName, gvr: gvr}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.watches[key]; exists {
+		m.lastSync[key] = time.Now()
+	}
+}
+
 // pruneObject removes large fields that aren't needed for completion
 // This significantly reduces memory usage for secrets, configmaps, etc.
 func pruneObject(obj *unstructured.Unstructured) {
@@ -209,7 +416,7 @@ func pruneContainerList(spec map[string]interface{}, key string) {
 }
 
 // runWatch performs a single watch iteration (list + watch)
-func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespaced bool) error {
+func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespaced bool, restart <-chan struct{}) error {
 	client, err := m.clientManager.GetClient(contextName)
 	if err != nil {
 		return fmt.Errorf("failed to get client: %w", err)
@@ -228,19 +435,36 @@ func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr sch
 		resourceClient = client.DynamicClient.Resource(gvr)
 	}
 
-	// Initial list to populate the store
-	list, err := resourceClient.List(ctx, metav1.ListOptions{})
+	// This list satisfies any restart requested before it
+	select {
+	case <-restart:
+	default:
+	}
+
+	// Initial list to populate the store, waiting for a free list slot first
+	select {
+	case m.listSemaphore <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	listCtx := ctx
+	if client.Timeout > 0 {
+		var cancel context.CancelFunc
+		listCtx, cancel = context.WithTimeout(ctx, client.Timeout)
+		defer cancel()
+	}
+	list, err := resourceClient.List(listCtx, metav1.ListOptions{})
 	if err != nil {
+		<-m.listSemaphore
 		return fmt.Errorf("failed to list resources: %w", err)
 	}
 
-	// Clear existing resources and add new ones
-	m.store.Clear(contextName, gvr)
-	for i := range list.Items {
-		pruneObject(&list.Items[i])
-		m.store.Add(contextName, gvr, &list.Items[i])
-	}
+	// Swap in the listed resources. After a watch reconnect most objects are
+	// unchanged and keep their stored, already-pruned copy.
+	m.store.Replace(contextName, gvr, list.Items, "", pruneObject)
 	m.store.SetWatching(contextName, gvr, true)
+	<-m.listSemaphore
+	m.recordSync(contextName, gvr)
 
 	m.logger.Info("initial list complete",
 		"context", contextName,
@@ -263,6 +487,8 @@ func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr sch
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-restart:
+			return errWatchRestarted
 		case event, ok := <-watcher.ResultChan():
 			if !ok {
 				return fmt.Errorf("watch channel closed")
@@ -275,6 +501,7 @@ func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr sch
 
 			switch event.Type {
 			case watch.Added:
+				m.eventsAdded.Add(1)
 				pruneObject(obj)
 				m.store.Add(contextName, gvr, obj)
 				m.logger.Debug("resource added",
@@ -284,9 +511,13 @@ func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr sch
 					"namespace", obj.GetNamespace(),
 				)
 			case watch.Modified:
-				// Update store directly - skip expensive diff for memory efficiency
+				// Most modifications of busy resources only touch fields removed by pruning
+				// (or just the resourceVersion); skip the store write for those
+				m.eventsModified.Add(1)
 				pruneObject(obj)
-				m.store.Add(contextName, gvr, obj)
+				if !m.store.AddIfChanged(contextName, gvr, obj) {
+					continue
+				}
 				m.logger.Debug("resource modified",
 					"context", contextName,
 					"resource", gvr.Resource,
@@ -294,6 +525,7 @@ func (m *WatchManager) runWatch(ctx context.Context, contextName string, gvr sch
 					"namespace", obj.GetNamespace(),
 				)
 			case watch.Deleted:
+				m.eventsDeleted.Add(1)
 				m.store.Delete(contextName, gvr, obj.GetNamespace(), obj.GetName())
 				m.logger.Debug("resource deleted",
 					"context", contextName,
@@ -334,6 +566,38 @@ func (m *WatchManager) WatchedResources() map[string][]schema.GroupVersionResour
 	return result
 }
 
+// EventCounts returns the number of watch events received per event type
+func (m *WatchManager) EventCounts() map[string]int64 {
+	return map[string]int64{
+		"added":    m.eventsAdded.Load(),
+		"modified": m.eventsModified.Load(),
+		"deleted":  m.eventsDeleted.Load(),
+	}
+}
+
+// ResetEventCounts zeroes the watch event counters
+func (m *WatchManager) ResetEventCounts() {
+	m.eventsAdded.Store(0)
+	m.eventsModified.Store(0)
+	m.eventsDeleted.Store(0)
+}
+
+// LastSync returns the time of the last successful list per context and resource.
+// Watches that haven't completed a list yet are omitted.
+func (m *WatchManager) LastSync() map[string]map[string]time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]map[string]time.Time)
+	for key, synced := range m.lastSync {
+		if result[key.context] == nil {
+			result[key.context] = make(map[string]time.Time)
+		}
+		result[key.context][key.gvr.Resource] = synced
+	}
+	return result
+}
+
 // ActiveContexts returns a list of contexts that have active watches
 func (m *WatchManager) ActiveContexts() []string {
 	m.mu.RLock()
@@ -358,6 +622,8 @@ func (m *WatchManager) StopContext(contextName string) {
 			m.store.SetWatching(key.context, key.gvr, false)
 			m.store.Clear(key.context, key.gvr)
 			delete(m.watches, key)
+			delete(m.lastSync, key)
+			delete(m.restarts, key)
 		}
 	}
 	delete(m.contexts, contextName)
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd socket activation
+const listenFdsStart = 3
+
+// IsSocketActivated reports whether the process was started by systemd socket activation
+func IsSocketActivated() bool {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return false
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	return err == nil && fds > 0
+}
+
+// activationListener returns the listener inherited from systemd socket activation.
+// Only the first passed socket is used; the environment is cleared so child processes
+// don't try to reuse it.
+func activationListener() (net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	f := os.NewFile(uintptr(listenFdsStart), "LISTEN_FD_3")
+	if f == nil {
+		return nil, fmt.Errorf("invalid socket activation file descriptor")
+	}
+	defer func() { _ = f.Close() }()
+
+	listener, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use socket activation listener: %w", err)
+	}
+	return listener, nil
+}
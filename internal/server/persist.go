@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pslijkhuis/kfzf/internal/config"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// watchesFile is the file in the cache directory holding watches to restore on startup
+const watchesFile = "watches.json"
+
+// persistedWatch is a watched resource type saved across restarts
+type persistedWatch struct {
+	Context  string `json:"context"`
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+}
+
+// watchesPath returns the path of the persisted watches file. Named instances
+// keep their own file.
+func watchesPath(instance string) string {
+	return config.InstancePath(filepath.Join(config.CachePath(), watchesFile), instance)
+}
+
+// saveWatches writes the currently active watches to the cache directory
+func (s *Server) saveWatches() error {
+	var watches []persistedWatch
+	for contextName, gvrs := range s.watchManager.WatchedResources() {
+		for _, gvr := range gvrs {
+			watches = append(watches, persistedWatch{
+				Context:  contextName,
+				Group:    gvr.Group,
+				Version:  gvr.Version,
+				Resource: gvr.Resource,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(watches, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watches: %w", err)
+	}
+
+	path := watchesPath(s.config.Server.Instance)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write watches: %w", err)
+	}
+
+	return nil
+}
+
+// restoreWatches re-establishes watches saved by saveWatches. Restored contexts are
+// tracked like any other initialized context, so idle cleanup still applies to them.
+func (s *Server) restoreWatches(ctx context.Context) error {
+	data, err := os.ReadFile(watchesPath(s.config.Server.Instance))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read watches: %w", err)
+	}
+
+	var watches []persistedWatch
+	if err := json.Unmarshal(data, &watches); err != nil {
+		return fmt.Errorf("failed to decode watches: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, contextName := range s.clientManager.ListContexts() {
+		known[contextName] = true
+	}
+
+	restored := 0
+	for _, w := range watches {
+		// Skip contexts that were removed from the kubeconfig since
+		if !known[w.Context] {
+			continue
+		}
+		s.initializeContextWatches(ctx, w.Context)
+
+		gvr := schema.GroupVersionResource{Group: w.Group, Version: w.Version, Resource: w.Resource}
+		namespaced, ok := s.cachedNamespaced(w.Context, gvr)
+		if !ok {
+			namespaced = isKnownNamespaced(gvr.Resource)
+		}
+		if err := s.watchManager.StartWatching(ctx, w.Context, gvr, namespaced); err != nil {
+			s.logger.Warn("failed to restore watch",
+				"context", w.Context,
+				"resource", gvr.Resource,
+				"error", err,
+			)
+			continue
+		}
+		restored++
+	}
+
+	s.logger.Info("restored watches", "count", restored)
+	return nil
+}
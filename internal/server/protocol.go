@@ -19,6 +19,15 @@ const (
 	RequestTypeStopWatch      RequestType = "stop_watch"
 	RequestTypeRecordRecent   RequestType = "record_recent"
 	RequestTypeGetRecent      RequestType = "get_recent"
+	RequestTypeAPIResources   RequestType = "api_resources"
+	RequestTypeResourceTypes  RequestType = "resource_types"
+	RequestTypeWatchList      RequestType = "watch_list"
+	RequestTypeStopContext    RequestType = "stop_context"
+	RequestTypeDebugDump      RequestType = "debug_dump"
+	RequestTypeSetLogLevel    RequestType = "set_log_level"
+	RequestTypeContexts       RequestType = "contexts"
+	RequestTypeResetStats     RequestType = "reset_stats"
+	RequestTypeStream         RequestType = "stream"
 )
 
 // Request represents a client request to the server
@@ -29,17 +38,52 @@ type Request struct {
 	Context      string `json:"context,omitempty"`
 	Namespace    string `json:"namespace,omitempty"`
 	ResourceType string `json:"resource_type,omitempty"`
+	Limit        int    `json:"limit,omitempty"`      // Max results per resource type (0 = unlimited)
+	NamesOnly    bool   `json:"names_only,omitempty"` // Emit only resource names, without columns
+	Dedupe       bool   `json:"dedupe,omitempty"`     // Collapse resources with the same name into one row
+	Wide         bool   `json:"wide,omitempty"`       // Use the wide column set where configured
+	// Query keeps only resources whose name contains its characters in order, best match
+	// first, capped at 100 results unless Limit is set. It lets the shell skip sending huge
+	// lists to fzf.
+	Query string `json:"query,omitempty"`
+	// Color asks for ANSI colored columns, if the server enables them (server.color). Set
+	// by frontends displaying the output with fzf --ansi.
+	Color bool `json:"color,omitempty"`
+	// NoWatch lists the resource type once instead of watching it, unless it is already watched
+	NoWatch bool `json:"no_watch,omitempty"`
+	// ServerColumns lists the resource type with server-side printing, so the columns
+	// match kubectl get exactly, instead of using the configured columns
+	ServerColumns bool `json:"server_columns,omitempty"`
+	// Template is a Go text/template executed per resource instead of the configured columns
+	// (see fzf.TemplateData for the available fields)
+	Template string `json:"template,omitempty"`
+	// Format selects machine-readable output with a header row: "csv" or "tsv"
+	// (default: padded columns)
+	Format string `json:"format,omitempty"`
 
 	// For containers request
 	PodName string `json:"pod_name,omitempty"`
 
+	// For labels request: append occurrence counts, optionally ordering by them
+	Counts      bool `json:"counts,omitempty"`
+	SortByCount bool `json:"sort_by_count,omitempty"`
+
 	// For field_values request
 	FieldName string `json:"field_name,omitempty"`
 
 	// For watch requests
 	ResourceTypes []string `json:"resource_types,omitempty"`
 
-	// For record_recent request
+	// For refresh requests: re-list watched resources instead of dropping caches
+	Soft bool `json:"soft,omitempty"`
+	// For refresh requests: only rebuild clients (e.g. after credential rotation), keeping
+	// watches and caches
+	ClientsOnly bool `json:"clients_only,omitempty"`
+
+	// For set_log_level request (debug, info, warn or error)
+	LogLevel string `json:"log_level,omitempty"`
+
+	// For record_recent and debug_dump requests
 	ResourceName string `json:"resource_name,omitempty"`
 }
 
@@ -47,20 +91,135 @@ type Request struct {
 type Response struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty"`
+	// Warning flags a likely user mistake without failing the request
+	Warning string `json:"warning,omitempty"`
 
 	// For complete responses
 	Output string `json:"output,omitempty"`
+	// Syncing is set while a completed resource type's initial list is still running,
+	// so empty output means "not loaded yet" rather than "no resources"
+	Syncing bool `json:"syncing,omitempty"`
 
 	// For status responses
 	Status *StatusInfo `json:"status,omitempty"`
+
+	// For api_resources responses
+	APIResources []APIResource `json:"api_resources,omitempty"`
+
+	// For watch_list responses
+	Watches []WatchInfo `json:"watches,omitempty"`
+
+	// For contexts responses
+	Contexts []ContextInfo `json:"contexts,omitempty"`
+}
+
+// StreamEvent is a change to a resource, pushed one JSON object per line on a stream
+// connection after the initial Response
+type StreamEvent struct {
+	// Type is added, modified or deleted, or error when the stream ends
+	Type      string `json:"type"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Object is the cached object (pruned like all cached objects), omitted for deletions
+	Object map[string]interface{} `json:"object,omitempty"`
+	// Error explains why the server ended the stream
+	Error string `json:"error,omitempty"`
+}
+
+// ContextInfo describes a kubeconfig context
+type ContextInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Current   bool   `json:"current"`
+}
+
+// WatchInfo describes an active watch
+type WatchInfo struct {
+	Context  string `json:"context"`
+	Resource string `json:"resource"`
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version"`
+	// Synced is set once the initial list has populated the cache
+	Synced bool `json:"synced"`
+}
+
+// APIResource describes a discovered resource type
+type APIResource struct {
+	Name       string   `json:"name"`
+	ShortNames []string `json:"short_names,omitempty"`
+	Kind       string   `json:"kind"`
+	Group      string   `json:"group,omitempty"`
+	Version    string   `json:"version"`
+	Namespaced bool     `json:"namespaced"`
+	// Columns are the server-rendered columns, once a complete request with
+	// ServerColumns has fetched them
+	Columns []string `json:"columns,omitempty"`
 }
 
 // StatusInfo contains server status information
 type StatusInfo struct {
+	CurrentContext   string                       `json:"current_context"`
+	KubeconfigPath   string                       `json:"kubeconfig_path"`
 	Uptime           string                       `json:"uptime"`
 	ResourceCount    int                          `json:"resource_count"`
 	WatchedResources map[string][]string          `json:"watched_resources"`
 	ResourceStats    map[string]map[string]int    `json:"resource_stats"`
+	// Whether the default resources have completed their initial list, per initialized context
+	DefaultsSynced map[string]bool `json:"defaults_synced,omitempty"`
+	// Time of the last successful list (RFC 3339), per context and resource
+	LastSync map[string]map[string]string `json:"last_sync,omitempty"`
+	// Time of the last change from a list or watch event (RFC 3339), per context and resource
+	LastUpdated map[string]map[string]string `json:"last_updated,omitempty"`
+	// API server version and reachability, per active context
+	Clusters map[string]ClusterInfo `json:"clusters,omitempty"`
+	// Request counts per request type and watch event counts per event type,
+	// since StatsSince (server start or the last stats reset)
+	Requests   map[string]int64 `json:"requests,omitempty"`
+	Events     map[string]int64 `json:"events,omitempty"`
+	StatsSince string           `json:"stats_since,omitempty"`
+	// Approximate request latency per request type, over the same period
+	Latency map[string]LatencyInfo `json:"latency,omitempty"`
+	// Discovery and completion result cache effectiveness, over the same period
+	Cache *CacheInfo `json:"cache,omitempty"`
+	// Daemon health: goroutines, sampled heap size and cached Kubernetes clients
+	Runtime *RuntimeInfo `json:"runtime,omitempty"`
+}
+
+// LatencyInfo holds approximate latency percentiles of a request type, rounded up
+// to histogram bucket bounds (e.g. "5ms"; ">5s" beyond the last bucket)
+type LatencyInfo struct {
+	P50 string `json:"p50"`
+	P95 string `json:"p95"`
+}
+
+// CacheInfo holds cache hit and miss counts
+type CacheInfo struct {
+	DiscoveryHits   int64 `json:"discovery_hits"`
+	DiscoveryMisses int64 `json:"discovery_misses"`
+	ResultHits      int64 `json:"result_hits"`
+	ResultMisses    int64 `json:"result_misses"`
+}
+
+// RuntimeInfo describes the daemon process
+type RuntimeInfo struct {
+	Goroutines    int    `json:"goroutines"`
+	HeapAlloc     uint64 `json:"heap_alloc_bytes"`
+	CachedClients int    `json:"cached_clients"`
+}
+
+// ClusterInfo describes the API server behind a context
+type ClusterInfo struct {
+	ServerVersion string `json:"server_version,omitempty"`
+	Reachable     bool   `json:"reachable"`
+	Error         string `json:"error,omitempty"`
+	// DiscoveryError is the last failed API discovery, until discovery succeeds again
+	DiscoveryError    string `json:"discovery_error,omitempty"`
+	DiscoveryFailedAt string `json:"discovery_failed_at,omitempty"` // RFC3339
+}
+
+// EncodeToken encodes an auth token as the first newline-delimited frame (TCP mode only)
+func EncodeToken(token string) []byte {
+	return append([]byte(token), '\n')
 }
 
 // EncodeRequest encodes a request to JSON with newline delimiter
@@ -11,6 +11,10 @@ type RecentResources struct {
 	maxKeys  int
 	items    map[string][]string // key: "context/namespace/resourceType" -> list of names
 	keyOrder []string            // Track key insertion order for LRU eviction
+
+	// defaultNamespace resolves an empty namespace to the context's default namespace,
+	// so recents recorded with and without an explicit namespace line up (nil: no resolution)
+	defaultNamespace func(context string) string
 }
 
 // NewRecentResources creates a new RecentResources tracker
@@ -28,7 +32,7 @@ func (r *RecentResources) Add(context, namespace, resourceType, name string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	key := context + "/" + namespace + "/" + resourceType
+	key := r.key(context, namespace, resourceType)
 
 	// Check if this is a new key
 	list, exists := r.items[key]
@@ -91,6 +95,14 @@ func (r *RecentResources) Add(context, namespace, resourceType, name string) {
 	}
 }
 
+// key returns the items key for a context, namespace and resource type
+func (r *RecentResources) key(context, namespace, resourceType string) string {
+	if namespace == "" && r.defaultNamespace != nil {
+		namespace = r.defaultNamespace(context)
+	}
+	return context + "/" + namespace + "/" + resourceType
+}
+
 // moveKeyToEnd moves a key to the end of the order slice (most recently used)
 func (r *RecentResources) moveKeyToEnd(key string) {
 	for i, k := range r.keyOrder {
@@ -108,7 +120,7 @@ func (r *RecentResources) Get(context, namespace, resourceType string) []string
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	key := context + "/" + namespace + "/" + resourceType
+	key := r.key(context, namespace, resourceType)
 	if items, ok := r.items[key]; ok {
 		// Return a copy to avoid race conditions
 		result := make([]string, len(items))
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	resultCacheTTL        = 2 * time.Second // Bounds how stale the AGE column of a cached result can get
+	maxResultCacheEntries = 256             // Maximum number of cached completion results
+)
+
+// resultKey identifies a completion query
+type resultKey struct {
+	context      string
+	gvr          schema.GroupVersionResource
+	namespace    string
+	resourceType string
+	limit        int
+	namesOnly    bool
+	dedupe       bool
+	wide         bool
+	color        bool
+	template     string
+	format       string
+	query        string
+}
+
+type resultEntry struct {
+	version uint64
+	output  string
+	created time.Time
+}
+
+// ResultCache holds formatted completion output per query, valid while the store
+// version of the queried resource type is unchanged. Entries also expire after a
+// short TTL since formatted output contains ages.
+type ResultCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[resultKey]resultEntry
+}
+
+// NewResultCache creates a new result cache
+func NewResultCache(ttl time.Duration, maxEntries int) *ResultCache {
+	return &ResultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[resultKey]resultEntry),
+	}
+}
+
+// Get returns the cached output for key if it was stored at the given store version
+func (c *ResultCache) Get(key resultKey, version uint64) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if entry.version != version || time.Since(entry.created) > c.ttl {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.output, true
+}
+
+// Put stores output for key at the given store version
+func (c *ResultCache) Put(key resultKey, version uint64, output string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		c.evictExpired()
+		if len(c.entries) >= c.maxEntries {
+			// Still full of fresh entries; start over rather than track LRU order
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = resultEntry{version: version, output: output, created: time.Now()}
+}
+
+// evictExpired removes entries older than the TTL. Must be called with the lock held.
+func (c *ResultCache) evictExpired() {
+	for key, entry := range c.entries {
+		if time.Since(entry.created) > c.ttl {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -2,25 +2,96 @@ package server
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
 	"os"
+	"os/user"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/pslijkhuis/kfzf/internal/config"
 	"github.com/pslijkhuis/kfzf/internal/fzf"
 	"github.com/pslijkhuis/kfzf/internal/k8s"
 	"github.com/pslijkhuis/kfzf/internal/store"
+	"golang.org/x/sync/singleflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
 )
 
-const maxConcurrentConnections = 50 // Maximum concurrent connection handlers
+const (
+	maxConcurrentConnections = 50               // Maximum concurrent connection handlers
+	prewarmTimeout           = 10 * time.Second // Maximum time to block startup for prewarm
+	contextPollInterval      = 2 * time.Second  // How often to check for current-context changes
+	maxPooledBufferSize      = 4 << 20          // Larger output buffers are not returned to the pool
+	shutdownDrainTimeout     = 5 * time.Second  // Maximum time to wait for in-flight requests on shutdown
+	discoveryFailureTTL      = 10 * time.Second // How long a failed discovery is returned before retrying
+	staleWatchMinObjects     = 100              // Smaller resource sets may legitimately not change for long
+	streamBuffer             = 1024             // Events a stream may fall behind before it is closed
+	streamWriteTimeout       = 10 * time.Second // Maximum time to write an event to a stream client
+	streamTouchInterval      = time.Minute      // How often an open stream marks its context as in use
+	clusterProbeTimeout      = 2 * time.Second  // Maximum time to wait for an API server's version in status
+)
+
+// bufferPool holds output buffers shared by the formatting handlers
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns a buffer to the pool. Oversized buffers are dropped so a single
+// huge completion doesn't pin memory.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// defaultResource is a resource watched by default for every initialized context
+type defaultResource struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// defaultResources are the common resources watched by default
+var defaultResources = []defaultResource{
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}, true},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}, true},
+	// EndpointSlices back the ENDPOINTS column of services
+	{fzf.EndpointSlicesGVR, true},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}, true},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}, true},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}, false},
+	{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}, false},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, true},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, true},
+	{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, true},
+}
 
 // Server is the kfzf daemon that handles completion requests
 type Server struct {
@@ -30,19 +101,35 @@ type Server struct {
 	store         *store.Store
 	formatter     *fzf.Formatter
 	logger        *slog.Logger
+	// logLevel is the level of the logger's handler, adjustable at runtime (nil if fixed)
+	logLevel *slog.LevelVar
 
 	listener  net.Listener
 	startTime time.Time
 
+	// Shared secret required as the first frame on TCP connections (empty for unix socket)
+	authToken string
+
 	shutdown bool
 
 	// Semaphore for limiting concurrent connections
 	connSemaphore chan struct{}
 
-	// Cache discovered resources per context with access times
-	resourceCache       map[string][]k8s.ResourceInfo
-	resourceCacheAccess map[string]time.Time
-	resourceCacheMu     sync.RWMutex
+	// Cache discovered resources per context with access times (expired after Server.DiscoveryTTL)
+	discoveryCache       map[string][]k8s.ResourceInfo
+	discoveryCacheAccess map[string]time.Time
+	// Whether the cached resources came from full (rather than targeted) discovery
+	discoveryCacheComplete map[string]bool
+	// API server version per context, cached alongside discovery
+	discoveryCacheVersion map[string]string
+	// Last discovery failure per context, cleared on success. Within discoveryFailureTTL
+	// the error is returned without contacting the API server again.
+	discoveryFailures map[string]discoveryFailure
+	discoveryCacheMu  sync.RWMutex
+	// Deduplicates concurrent discovery calls per context
+	discoveryGroup singleflight.Group
+	// Resource types per context ("context/type") whose ambiguity was already logged
+	ambiguityLogged sync.Map
 
 	// Track which contexts have been initialized with default watches
 	initializedContexts       map[string]bool
@@ -51,18 +138,55 @@ type Server struct {
 
 	// Track recently accessed resources for suggestions
 	recentResources *RecentResources
+
+	// Server-rendered column definitions per context and resource type, from the last
+	// complete request with ServerColumns
+	tableColumns   map[tableColumnsKey][]metav1.TableColumnDefinition
+	tableColumnsMu sync.RWMutex
+
+	// Cache formatted completion output while the underlying resources are unchanged
+	results *ResultCache
+
+	// Request counters, reported in status
+	stats Stats
+}
+
+// ClientOptions returns the options Kubernetes clients are created with: the configured
+// impersonation and per-context limits. Other commands talking to the API server (e.g.
+// doctor) use them too, so they see the cluster like the server does.
+func ClientOptions(cfg *config.Config) k8s.ClientOptions {
+	return k8s.ClientOptions{
+		Impersonate: rest.ImpersonationConfig{
+			UserName: cfg.Server.Impersonate.User,
+			Groups:   cfg.Server.Impersonate.Groups,
+		},
+		Limits: func(contextName string) k8s.ClientLimits {
+			ctxCfg := cfg.ContextConfig(contextName)
+			return k8s.ClientLimits{QPS: ctxCfg.QPS, Burst: ctxCfg.Burst, Timeout: ctxCfg.Timeout}
+		},
+	}
 }
 
-// NewServer creates a new server instance
-func NewServer(cfg *config.Config, logger *slog.Logger) (*Server, error) {
-	clientManager, err := k8s.NewClientManager()
+// NewServer creates a new server instance. logLevel is the level the logger's
+// handler was created with; when non-nil it can be changed at runtime.
+func NewServer(cfg *config.Config, logger *slog.Logger, logLevel *slog.LevelVar) (*Server, error) {
+	clientManager, err := k8s.NewClientManager(ClientOptions(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client manager: %w", err)
 	}
 
 	resourceStore := store.NewStore()
+	resourceStore.SetTombstoneTTL(cfg.Server.DeleteGracePeriod)
 	watchManager := k8s.NewWatchManager(clientManager, resourceStore, logger)
+	if cfg.Server.WatchBackoff > 0 {
+		watchManager.Backoff.Initial = cfg.Server.WatchBackoff
+	}
+	if cfg.Server.WatchMaxBackoff > 0 {
+		watchManager.Backoff.Max = cfg.Server.WatchMaxBackoff
+	}
 	formatter := fzf.NewFormatter(cfg)
+	recentResources := NewRecentResources(20) // Track last 20 resources per type
+	recentResources.defaultNamespace = clientManager.GetContextNamespace
 
 	return &Server{
 		config:                    cfg,
@@ -71,18 +195,135 @@ func NewServer(cfg *config.Config, logger *slog.Logger) (*Server, error) {
 		store:                     resourceStore,
 		formatter:                 formatter,
 		logger:                    logger,
+		logLevel:                  logLevel,
 		connSemaphore:             make(chan struct{}, maxConcurrentConnections),
-		resourceCache:             make(map[string][]k8s.ResourceInfo),
-		resourceCacheAccess:       make(map[string]time.Time),
+		discoveryCache:            make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:      make(map[string]time.Time),
+		discoveryCacheComplete:    make(map[string]bool),
+		discoveryCacheVersion:     make(map[string]string),
+		discoveryFailures:         make(map[string]discoveryFailure),
+		tableColumns:              make(map[tableColumnsKey][]metav1.TableColumnDefinition),
 		initializedContexts:       make(map[string]bool),
 		initializedContextsAccess: make(map[string]time.Time),
-		recentResources:           NewRecentResources(20), // Track last 20 resources per type
+		recentResources:           recentResources,
+		results:                   NewResultCache(resultCacheTTL, maxResultCacheEntries),
 	}, nil
 }
 
 // Start starts the server and listens for connections
 func (s *Server) Start(ctx context.Context) error {
 	socketPath := s.config.Server.SocketPath
+	tcpMode := s.config.Server.ListenAddr != ""
+	activated := IsSocketActivated()
+
+	switch {
+	case activated:
+		if err := s.listenActivated(); err != nil {
+			return err
+		}
+	case tcpMode:
+		if err := s.listenTCP(); err != nil {
+			return err
+		}
+	default:
+		if err := s.listenUnix(socketPath); err != nil {
+			return err
+		}
+	}
+
+	s.startTime = time.Now()
+
+	if activated {
+		s.logger.Info("server started via socket activation", "address", s.listener.Addr().String(), "tls", s.listener.Addr().Network() != "unix" && s.config.Server.TLS.CertFile != "")
+	} else if tcpMode {
+		s.logger.Info("server started", "address", s.listener.Addr().String(), "tls", s.config.Server.TLS.CertFile != "")
+	} else {
+		s.logger.Info("server started", "socket", socketPath)
+	}
+
+	// Start watching common resources for current context
+	if s.config.Server.Prewarm {
+		// Block until the default set is listed; clients queue on the listener meanwhile
+		s.prewarm(ctx)
+	} else {
+		go s.startDefaultWatches(ctx)
+	}
+
+	// Bring back the watches that were active before the last shutdown
+	go func() {
+		if err := s.restoreWatches(ctx); err != nil {
+			s.logger.Warn("failed to restore watches", "error", err)
+		}
+	}()
+
+	// Accept connections
+	go s.acceptConnections(ctx)
+
+	// Start periodic cleanup of unused resources
+	go s.periodicCleanup(ctx)
+
+	// Warm up new contexts as soon as the kubeconfig current-context changes
+	go s.watchCurrentContext(ctx)
+
+	// Reload kubeconfig on file changes (context edits, credential rotation)
+	go func() {
+		if err := s.clientManager.WatchKubeconfig(ctx, s.logger); err != nil {
+			s.logger.Warn("kubeconfig watcher disabled", "error", err)
+		}
+	}()
+
+	// Wait for context cancellation
+	<-ctx.Done()
+
+	s.logger.Info("shutting down server")
+	s.shutdown = true
+	// Stop accepting, then let in-flight requests finish before the cache is dropped
+	_ = s.listener.Close()
+	// The socket file belongs to systemd when socket-activated
+	if !tcpMode && !activated {
+		_ = os.Remove(socketPath)
+	}
+	if !s.drainConnections(shutdownDrainTimeout) {
+		s.logger.Warn("shutdown drain timed out, closing in-flight connections",
+			"in_flight", len(s.connSemaphore),
+		)
+	}
+
+	// Watches outlive ctx until StopAll, so this still sees every one of them
+	if err := s.saveWatches(); err != nil {
+		s.logger.Warn("failed to save watches", "error", err)
+	}
+	s.watchManager.StopAll()
+
+	return nil
+}
+
+// drainConnections waits until no connection handlers are running, by taking every
+// connection slot. It returns false if handlers are still running after timeout.
+func (s *Server) drainConnections(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for range cap(s.connSemaphore) {
+		select {
+		case s.connSemaphore <- struct{}{}:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
+
+// listenUnix creates the unix socket listener
+func (s *Server) listenUnix(socketPath string) error {
+	mode, err := s.config.Server.SocketFileMode()
+	if err != nil {
+		return err
+	}
+	gid := -1
+	if group := s.config.Server.SocketGroup; group != "" {
+		if gid, err = lookupGroupID(group); err != nil {
+			return err
+		}
+	}
 
 	// Ensure socket directory exists
 	socketDir := filepath.Dir(socketPath)
@@ -101,36 +342,100 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	s.listener = listener
-	s.startTime = time.Now()
 
-	// Set socket permissions
-	if err := os.Chmod(socketPath, 0600); err != nil {
+	// Set socket ownership and permissions
+	if gid != -1 {
+		if err := os.Chown(socketPath, -1, gid); err != nil {
+			s.logger.Warn("failed to set socket group", "group", s.config.Server.SocketGroup, "error", err)
+		}
+	}
+	if err := os.Chmod(socketPath, mode); err != nil {
 		s.logger.Warn("failed to set socket permissions", "error", err)
 	}
 
-	s.logger.Info("server started", "socket", socketPath)
+	return nil
+}
 
-	// Start watching common resources for current context
-	go s.startDefaultWatches(ctx)
+// lookupGroupID resolves a group name or numeric group ID
+func lookupGroupID(group string) (int, error) {
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		if g, err = user.LookupGroupId(group); err != nil {
+			return 0, fmt.Errorf("unknown socket group %q", group)
+		}
+	}
+	return strconv.Atoi(g.Gid)
+}
 
-	// Accept connections
-	go s.acceptConnections(ctx)
+// listenActivated uses the listener passed in by systemd socket activation.
+// Inherited TCP sockets still require the shared-secret token, and use TLS when a
+// certificate is configured, like a listener of listenTCP.
+func (s *Server) listenActivated() error {
+	listener, err := activationListener()
+	if err != nil {
+		return err
+	}
 
-	// Start periodic cleanup of unused resources
-	go s.periodicCleanup(ctx)
+	if listener.Addr().Network() != "unix" {
+		token := s.config.Server.AuthToken()
+		if token == "" {
+			_ = listener.Close()
+			return fmt.Errorf("tcp listener requires server.token or KFZF_TOKEN to be set")
+		}
+		if listener, err = s.wrapTLS(listener); err != nil {
+			return err
+		}
+		s.authToken = token
+	}
 
-	// Wait for context cancellation
-	<-ctx.Done()
+	s.listener = listener
+	return nil
+}
 
-	s.logger.Info("shutting down server")
-	s.shutdown = true
-	s.watchManager.StopAll()
-	_ = s.listener.Close()
-	_ = os.Remove(socketPath)
+// listenTCP creates the TCP listener, wrapped in TLS when a certificate is configured.
+// A shared-secret token is mandatory since the listener is not protected by file permissions.
+func (s *Server) listenTCP() error {
+	srvCfg := s.config.Server
 
+	token := srvCfg.AuthToken()
+	if token == "" {
+		return fmt.Errorf("tcp listener requires server.token or KFZF_TOKEN to be set")
+	}
+
+	listener, err := net.Listen("tcp", srvCfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", srvCfg.ListenAddr, err)
+	}
+
+	if listener, err = s.wrapTLS(listener); err != nil {
+		return err
+	}
+
+	s.listener = listener
+	s.authToken = token
 	return nil
 }
 
+// wrapTLS wraps a TCP listener in TLS when a certificate is configured, and closes it
+// if the certificate can't be loaded
+func (s *Server) wrapTLS(listener net.Listener) (net.Listener, error) {
+	tlsCfg := s.config.Server.TLS
+	if tlsCfg.CertFile == "" && tlsCfg.KeyFile == "" {
+		s.logger.Warn("tcp listener running without TLS, token will be sent in plain text")
+		return listener, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+	if err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return tls.NewListener(listener, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
 // startDefaultWatches starts watches for commonly used resources for current context
 func (s *Server) startDefaultWatches(ctx context.Context) {
 	currentContext := s.clientManager.GetCurrentContext()
@@ -141,6 +446,58 @@ func (s *Server) startDefaultWatches(ctx context.Context) {
 	s.initializeContextWatches(ctx, currentContext)
 }
 
+// prewarm starts the default watches for the current context and waits until
+// their initial lists have populated the store (bounded by prewarmTimeout)
+func (s *Server) prewarm(ctx context.Context) {
+	currentContext := s.clientManager.GetCurrentContext()
+	if currentContext == "" {
+		s.logger.Warn("no current context set")
+		return
+	}
+
+	start := time.Now()
+	s.initializeContextWatches(ctx, currentContext)
+
+	synced := s.waitForDefaults(currentContext, prewarmTimeout)
+
+	s.logger.Info("prewarm complete",
+		"context", currentContext,
+		"synced", synced,
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+}
+
+// defaultsSynced reports whether the initial lists of all default resources have
+// completed for a context
+func (s *Server) defaultsSynced(contextName string) bool {
+	for _, res := range defaultResources {
+		if !s.store.IsWatching(contextName, res.gvr) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDefaultResource reports whether a resource is watched by default
+func isDefaultResource(gvr schema.GroupVersionResource) bool {
+	for _, res := range defaultResources {
+		if res.gvr == gvr {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForDefaults waits until the default resources of a context are synced,
+// returning false if the timeout expires first
+func (s *Server) waitForDefaults(contextName string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for _, res := range defaultResources {
+		s.waitForSync(contextName, res.gvr, time.Until(deadline))
+	}
+	return s.defaultsSynced(contextName)
+}
+
 // initializeContextWatches starts default watches for a specific context if not already initialized
 func (s *Server) initializeContextWatches(ctx context.Context, contextName string) {
 	s.initializedContextsMu.Lock()
@@ -157,22 +514,6 @@ func (s *Server) initializeContextWatches(ctx context.Context, contextName strin
 
 	s.logger.Info("initializing watches for new context", "context", contextName)
 
-	// Common resources to watch by default
-	defaultResources := []struct {
-		gvr        schema.GroupVersionResource
-		namespaced bool
-	}{
-		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}, true},
-		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}, true},
-		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}, true},
-		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}, true},
-		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}, false},
-		{schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}, false},
-		{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, true},
-		{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, true},
-		{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, true},
-	}
-
 	for _, res := range defaultResources {
 		if err := s.watchManager.StartWatching(ctx, contextName, res.gvr, res.namespaced); err != nil {
 			s.logger.Warn("failed to start watch",
@@ -225,6 +566,20 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
 
 	reader := bufio.NewReader(conn)
+
+	// TCP connections must authenticate with the shared token as the first frame
+	if s.authToken != "" {
+		token, err := reader.ReadBytes('\n')
+		if err != nil {
+			return // Client disconnected before authenticating (e.g., health checks)
+		}
+		if subtle.ConstantTimeCompare(bytes.TrimSuffix(token, []byte{'\n'}), []byte(s.authToken)) != 1 {
+			s.logger.Warn("rejected connection with invalid token", "remote", conn.RemoteAddr().String())
+			s.sendError(conn, "unauthorized")
+			return
+		}
+	}
+
 	data, err := reader.ReadBytes('\n')
 	if err != nil {
 		// EOF is expected when client disconnects without sending data (e.g., health checks)
@@ -241,6 +596,16 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 		return
 	}
 
+	s.stats.CountRequest(req.Type)
+
+	// Streams keep the connection open until the client disconnects
+	if req.Type == RequestTypeStream {
+		s.handleStream(ctx, conn, reader, req)
+		return
+	}
+
+	start := time.Now()
+
 	var resp *Response
 
 	switch req.Type {
@@ -249,7 +614,7 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	case RequestTypeContainers:
 		resp = s.handleContainers(req)
 	case RequestTypePorts:
-		resp = s.handlePorts(req)
+		resp = s.handlePorts(ctx, req)
 	case RequestTypeLabels:
 		resp = s.handleLabels(ctx, req)
 	case RequestTypeFieldValues:
@@ -257,7 +622,7 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	case RequestTypeStatus:
 		resp = s.handleStatus()
 	case RequestTypeRefresh:
-		resp = s.handleRefresh()
+		resp = s.handleRefresh(ctx, req)
 	case RequestTypeWatch:
 		resp = s.handleWatch(ctx, req)
 	case RequestTypeStopWatch:
@@ -266,9 +631,26 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 		resp = s.handleRecordRecent(req)
 	case RequestTypeGetRecent:
 		resp = s.handleGetRecent(req)
+	case RequestTypeAPIResources:
+		resp = s.handleAPIResources(req)
+	case RequestTypeResourceTypes:
+		resp = s.handleResourceTypes(req)
+	case RequestTypeWatchList:
+		resp = s.handleWatchList(req)
+	case RequestTypeStopContext:
+		resp = s.handleStopContext(req)
+	case RequestTypeDebugDump:
+		resp = s.handleDebugDump(req)
+	case RequestTypeSetLogLevel:
+		resp = s.handleSetLogLevel(req)
+	case RequestTypeContexts:
+		resp = s.handleContexts()
+	case RequestTypeResetStats:
+		resp = s.handleResetStats()
 	default:
 		resp = &Response{Success: false, Error: "unknown request type"}
 	}
+	s.stats.ObserveLatency(req.Type, time.Since(start))
 
 	respData, err := EncodeResponse(resp)
 	if err != nil {
@@ -279,58 +661,623 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	_, _ = conn.Write(respData)
 }
 
-// handleComplete handles a completion request
-func (s *Server) handleComplete(ctx context.Context, req *Request) *Response {
-	contextName := req.Context
-	if contextName == "" {
-		contextName = s.clientManager.GetCurrentContext()
-	}
+// handleComplete handles a completion request
+func (s *Server) handleComplete(ctx context.Context, req *Request) *Response {
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.clientManager.GetCurrentContext()
+	}
+
+	// Initialize default watches for this context if it's a new context
+	// This runs in the background to not block the current request.
+	// A no-watch completion must not start any watches, so it skips this.
+	if !req.NoWatch {
+		go s.initializeContextWatches(ctx, contextName)
+	}
+
+	// Use the explicitly provided namespace, or empty string to get all namespaces
+	namespace := req.Namespace
+
+	// A comma-separated type list ("po,svc", as typed after kubectl get) completes each type
+	if len(req.ResourceTypes) == 0 && strings.Contains(req.ResourceType, ",") {
+		req.ResourceTypes = strings.Split(req.ResourceType, ",")
+	}
+	if len(req.ResourceTypes) > 0 {
+		if req.ServerColumns {
+			return &Response{Success: false, Error: "server columns need a single resource type"}
+		}
+		return s.handleCompleteMulti(ctx, contextName, namespace, req.ResourceTypes, req)
+	}
+
+	resourceType := k8s.NormalizeResourceName(req.ResourceType)
+
+	if req.ServerColumns {
+		return s.handleCompleteTable(ctx, contextName, namespace, resourceType, req)
+	}
+	if req.NoWatch {
+		return s.handleCompleteOnce(ctx, contextName, namespace, resourceType, req)
+	}
+
+	gvr, namespaced, err := s.prepareCompletion(ctx, contextName, resourceType)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	// Repeated queries against unchanged resources reuse the previous output.
+	// The version is read before listing, so a concurrent change only causes a miss.
+	key := resultKey{context: contextName, gvr: *gvr, namespace: namespace, resourceType: resourceType, limit: req.Limit, namesOnly: req.NamesOnly, dedupe: req.Dedupe, wide: req.Wide, color: req.Color, template: req.Template, format: req.Format, query: req.Query}
+	version := s.store.Version(contextName, *gvr)
+	if gvr.Resource == "services" {
+		// Service rows show their endpoints, so endpoint changes invalidate them too
+		version += s.store.Version(contextName, fzf.EndpointSlicesGVR)
+	}
+	output, ok := s.results.Get(key, version)
+	s.stats.CountResult(ok)
+	if !ok {
+		resources := matchQuery(s.listSorted(contextName, *gvr, namespace, namespaced), req.Query)
+		var counts []int
+		if req.Dedupe {
+			resources, counts = dedupeByName(resources)
+		}
+		resources = limitResources(resources, resultLimit(req))
+		output, err = s.formatResources(s.requestFormatter(contextName, req), resources, counts, resourceType, namespace, namespaced, req)
+		if err != nil {
+			return &Response{Success: false, Error: err.Error()}
+		}
+		s.results.Put(key, version, output)
+	}
+
+	return &Response{
+		Success: true,
+		Output:  output,
+		Warning: namespaceWarning(resourceType, namespace, namespaced),
+		Syncing: !s.store.IsWatching(contextName, *gvr),
+	}
+}
+
+// handleCompleteOnce completes a resource type from a single list instead of the watch
+// cache, for one-off completions of large or sensitive resources that aren't worth
+// keeping in memory. A type that is already watched is served from the cache.
+// The output isn't cached: the next request lists again.
+func (s *Server) handleCompleteOnce(ctx context.Context, contextName, namespace, resourceType string, req *Request) *Response {
+	gvr, namespaced, err := s.resolveGVR(contextName, resourceType)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	resources, err := s.listOnce(ctx, contextName, *gvr, namespace, namespaced)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	resources = matchQuery(resources, req.Query)
+	var counts []int
+	if req.Dedupe {
+		resources, counts = dedupeByName(resources)
+	}
+	resources = limitResources(resources, resultLimit(req))
+	output, err := s.formatResources(s.requestFormatter(contextName, req), resources, counts, resourceType, namespace, namespaced, req)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	return &Response{
+		Success: true,
+		Output:  output,
+		Warning: namespaceWarning(resourceType, namespace, namespaced),
+	}
+}
+
+// tableColumnsKey identifies the server-rendered columns of a resource type
+type tableColumnsKey struct {
+	context string
+	gvr     schema.GroupVersionResource
+}
+
+// handleCompleteTable completes a resource type from a list rendered by the API server,
+// so the columns are exactly those of kubectl get, including for custom resources with
+// additional printer columns. This costs a list call per request, which is why the
+// configured columns backed by the watch cache remain the default. Like completions,
+// the output for a watched type is reused while its cached resources are unchanged.
+func (s *Server) handleCompleteTable(ctx context.Context, contextName, namespace, resourceType string, req *Request) *Response {
+	gvr, namespaced, err := s.resolveGVR(contextName, resourceType)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	key := resultKey{context: contextName, gvr: *gvr, namespace: namespace, resourceType: resourceType, limit: req.Limit, wide: req.Wide, format: "server-columns"}
+	watched := s.store.IsWatching(contextName, *gvr)
+	version := s.store.Version(contextName, *gvr)
+	if watched {
+		if output, ok := s.results.Get(key, version); ok {
+			s.stats.CountResult(true)
+			return &Response{Success: true, Output: output, Warning: namespaceWarning(resourceType, namespace, namespaced)}
+		}
+		s.stats.CountResult(false)
+	}
+
+	client, err := s.clientManager.GetClient(contextName)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+	listNamespace := namespace
+	if !namespaced {
+		listNamespace = ""
+	}
+	table, err := k8s.ListTable(ctx, client, *gvr, listNamespace, req.Limit)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	s.tableColumnsMu.Lock()
+	s.tableColumns[tableColumnsKey{context: contextName, gvr: *gvr}] = table.ColumnDefinitions
+	s.tableColumnsMu.Unlock()
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	s.formatter.WithWide(req.Wide).FormatTableTo(buf, table, namespace == "" && namespaced, req.Limit)
+	output := buf.String()
+	if watched {
+		s.results.Put(key, version, output)
+	}
+
+	return &Response{
+		Success: true,
+		Output:  output,
+		Warning: namespaceWarning(resourceType, namespace, namespaced),
+	}
+}
+
+// cachedTableColumns returns the names of the server-rendered columns kubectl get shows
+// for a resource type, if a complete request with ServerColumns has fetched them
+func (s *Server) cachedTableColumns(contextName string, gvr schema.GroupVersionResource) []string {
+	s.tableColumnsMu.RLock()
+	definitions := s.tableColumns[tableColumnsKey{context: contextName, gvr: gvr}]
+	s.tableColumnsMu.RUnlock()
+
+	var names []string
+	for _, def := range fzf.TableColumns(definitions, false) {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+// listOnce returns resources in display order from a single list, or from the cache when
+// the resource type is already watched
+func (s *Server) listOnce(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string, namespaced bool) ([]*store.Resource, error) {
+	if s.store.IsWatching(contextName, gvr) {
+		return s.listSorted(contextName, gvr, namespace, namespaced), nil
+	}
+	if !namespaced {
+		namespace = ""
+	}
+	resources, err := s.watchManager.ListOnce(ctx, contextName, gvr, namespace)
+	if err != nil {
+		return nil, err
+	}
+	sortResources(resources)
+	return resources, nil
+}
+
+// formatResources formats sorted resources of a single type in the output requested
+// by req. counts, when set, holds the per-row counts of deduplicated resources.
+func (s *Server) formatResources(formatter *fzf.Formatter, resources []*store.Resource, counts []int, resourceType, namespace string, namespaced bool, req *Request) (string, error) {
+	switch {
+	case req.Template != "":
+		return s.formatTemplate(formatter, resources, resourceType, req.Template)
+	case req.Format != "":
+		return s.formatRecords(formatter, resources, resourceType, req.Format)
+	case req.NamesOnly:
+		return s.formatNames(resources), nil
+	case counts != nil:
+		return s.formatCompletionCounts(formatter, resources, resourceType, counts), nil
+	case s.config.Server.GroupByNamespace && namespace == "" && namespaced && req.Query == "":
+		return formatter.FormatGrouped(resources, resourceType), nil
+	default:
+		return s.formatCompletion(formatter, resources, resourceType), nil
+	}
+}
+
+// requestFormatter returns the formatter for a completion request: the context's formatter
+// with the request's column set, colored if both the request and the config ask for it,
+// and highlighting the query's matches if the config asks for that too
+func (s *Server) requestFormatter(contextName string, req *Request) *fzf.Formatter {
+	formatter := s.formatterFor(contextName).WithWide(req.Wide).WithColor(req.Color && s.config.Server.Color)
+	if s.config.Server.HighlightMatches {
+		formatter = formatter.WithHighlight(req.Query)
+	}
+	return formatter
+}
+
+// formatterFor returns the formatter for a context, resolving cross-resource
+// fields against that context's cached resources
+func (s *Server) formatterFor(contextName string) *fzf.Formatter {
+	return s.formatter.WithLookup(func(gvr schema.GroupVersionResource, namespace string) []*store.Resource {
+		return s.store.List(contextName, gvr, namespace)
+	})
+}
+
+// formatCompletion formats resources for a completion response
+func (s *Server) formatCompletion(formatter *fzf.Formatter, resources []*store.Resource, resourceType string) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	formatter.FormatTo(buf, resources, resourceType)
+	return buf.String()
+}
+
+// formatTemplate formats resources with a user-supplied text/template, one line per resource
+func (s *Server) formatTemplate(formatter *fzf.Formatter, resources []*store.Resource, resourceType, text string) (string, error) {
+	tmpl, err := template.New("complete").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := formatter.FormatTemplateTo(buf, resources, resourceType, tmpl); err != nil {
+		return "", fmt.Errorf("template failed: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// formatRecords formats resources as CSV or TSV with a header row. CSV quotes values as
+// needed; TSV values have tabs and newlines replaced like the padded output.
+func (s *Server) formatRecords(formatter *fzf.Formatter, resources []*store.Resource, resourceType, format string) (string, error) {
+	records := formatter.Records(resources, resourceType)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	switch format {
+	case "csv":
+		w := csv.NewWriter(buf)
+		if err := w.WriteAll(records); err != nil {
+			return "", err
+		}
+	case "tsv":
+		fzf.WriteTSV(buf, records)
+	default:
+		return "", fmt.Errorf("unknown format %q (expected csv or tsv)", format)
+	}
+	return buf.String(), nil
+}
+
+// formatCompletionCounts formats resources like formatCompletion, appending a "(Nx)"
+// column to rows that stand for several resources with the same name
+func (s *Server) formatCompletionCounts(formatter *fzf.Formatter, resources []*store.Resource, resourceType string, counts []int) string {
+	out := getBuffer()
+	defer putBuffer(out)
+	tmp := getBuffer()
+	defer putBuffer(tmp)
+
+	formatter.FormatTo(tmp, resources, resourceType)
+	if tmp.Len() == 0 {
+		return ""
+	}
+	rest := tmp.Bytes()
+	for i := 0; ; i++ {
+		line, next, more := bytes.Cut(rest, []byte{'\n'})
+		out.Write(line)
+		writeCount(out, counts, i)
+		if !more {
+			break
+		}
+		out.WriteByte('\n')
+		rest = next
+	}
+	return out.String()
+}
+
+// writeCount appends the "(Nx)" column for row i when it stands for several resources
+func writeCount(buf *bytes.Buffer, counts []int, i int) {
+	if i < len(counts) && counts[i] > 1 {
+		buf.WriteString("\t(")
+		buf.WriteString(strconv.Itoa(counts[i]))
+		buf.WriteString("x)")
+	}
+}
+
+// dedupeByName collapses resources with the same name, which are adjacent after
+// sortResources, into the first of them. It returns the kept resources and how many
+// resources each of them stands for.
+func dedupeByName(resources []*store.Resource) ([]*store.Resource, []int) {
+	counts := make([]int, 0, len(resources))
+	kept := resources[:0]
+	for _, res := range resources {
+		if len(kept) > 0 && kept[len(kept)-1].Name == res.Name {
+			counts[len(counts)-1]++
+			continue
+		}
+		kept = append(kept, res)
+		counts = append(counts, 1)
+	}
+	return kept, counts
+}
+
+// formatNames formats just the names of resources, one per line
+func (s *Server) formatNames(resources []*store.Resource) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	s.formatter.FormatNamesTo(buf, resources)
+	return buf.String()
+}
+
+// handleCompleteMulti completes several resource types in one request.
+// Each line is prefixed with its type ("pods/nginx ...") so the result can be passed to kubectl.
+func (s *Server) handleCompleteMulti(ctx context.Context, contextName, namespace string, resourceTypes []string, req *Request) *Response {
+	var types, warnings []string
+	var syncing bool
+	sets := make(map[string][]*store.Resource, len(resourceTypes))
+	var counts map[string][]int
+	if req.Dedupe && !req.NamesOnly {
+		counts = make(map[string][]int, len(resourceTypes))
+	}
+	for _, rt := range resourceTypes {
+		resourceType := k8s.NormalizeResourceName(strings.TrimSpace(rt))
+		// "po,pods" or a trailing comma would otherwise list a type twice or fail
+		if resourceType == "" || slices.Contains(types, resourceType) {
+			continue
+		}
+
+		var gvr *schema.GroupVersionResource
+		var namespaced bool
+		var err error
+		if req.NoWatch {
+			gvr, namespaced, err = s.resolveGVR(contextName, resourceType)
+		} else {
+			gvr, namespaced, err = s.prepareCompletion(ctx, contextName, resourceType)
+		}
+		if err != nil {
+			return &Response{Success: false, Error: err.Error()}
+		}
+		var resources []*store.Resource
+		if req.NoWatch {
+			resources, err = s.listOnce(ctx, contextName, *gvr, namespace, namespaced)
+			if err != nil {
+				return &Response{Success: false, Error: err.Error()}
+			}
+		} else {
+			if !s.store.IsWatching(contextName, *gvr) {
+				syncing = true
+			}
+			resources = s.listSorted(contextName, *gvr, namespace, namespaced)
+		}
+		if warning := namespaceWarning(resourceType, namespace, namespaced); warning != "" {
+			warnings = append(warnings, warning)
+		}
+		types = append(types, resourceType)
+		resources = matchQuery(resources, req.Query)
+		if req.Dedupe {
+			var typeCounts []int
+			resources, typeCounts = dedupeByName(resources)
+			if counts != nil {
+				counts[resourceType] = typeCounts
+			}
+		}
+		sets[resourceType] = limitResources(resources, resultLimit(req))
+	}
+
+	return &Response{
+		Success: true,
+		Output:  s.formatCompletionMulti(s.requestFormatter(contextName, req), types, sets, counts, req.NamesOnly),
+		Warning: strings.Join(warnings, "; "),
+		Syncing: syncing,
+	}
+}
+
+// formatCompletionMulti formats the resources of several types, prefixing each line with its type.
+// counts, when set, holds the per-row counts of deduplicated resources of each type.
+func (s *Server) formatCompletionMulti(formatter *fzf.Formatter, resourceTypes []string, sets map[string][]*store.Resource, counts map[string][]int, namesOnly bool) string {
+	out := getBuffer()
+	defer putBuffer(out)
+	tmp := getBuffer()
+	defer putBuffer(tmp)
+
+	for _, resourceType := range resourceTypes {
+		tmp.Reset()
+		if namesOnly {
+			formatter.FormatNamesTo(tmp, sets[resourceType])
+		} else {
+			formatter.FormatTo(tmp, sets[resourceType], resourceType)
+		}
+		if tmp.Len() == 0 {
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteByte('\n')
+		}
+		rest := tmp.Bytes()
+		for i := 0; ; i++ {
+			line, next, more := bytes.Cut(rest, []byte{'\n'})
+			out.WriteString(resourceType)
+			out.WriteByte('/')
+			out.Write(line)
+			writeCount(out, counts[resourceType], i)
+			if !more {
+				break
+			}
+			out.WriteByte('\n')
+			rest = next
+		}
+	}
+	return out.String()
+}
+
+// sortResources sorts by name, breaking ties by namespace then creation time so the
+// order is stable across requests (store iteration order is random)
+func sortResources(resources []*store.Resource) {
+	// slices.SortFunc is faster than sort.Slice
+	slices.SortFunc(resources, func(a, b *store.Resource) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return a.CreationTimestamp.Compare(b.CreationTimestamp)
+	})
+}
+
+// defaultQueryLimit caps the results of a query when the request sets no limit
+const defaultQueryLimit = 100
 
-	// Initialize default watches for this context if it's a new context
-	// This runs in the background to not block the current request
-	go s.initializeContextWatches(ctx, contextName)
+// matchQuery returns the sorted resources whose name matches query as a subsequence,
+// best match first, or all of them when query is empty. Resources with the same name
+// stay adjacent, so they can still be deduplicated.
+func matchQuery(resources []*store.Resource, query string) []*store.Resource {
+	if query == "" {
+		return resources
+	}
+	return fzf.MatchResources(resources, query)
+}
 
-	// Use the explicitly provided namespace, or empty string to get all namespaces
-	namespace := req.Namespace
+// resultLimit returns the maximum number of results per resource type for a request.
+// Query results are capped at defaultQueryLimit unless a limit is given.
+func resultLimit(req *Request) int {
+	if req.Query != "" && req.Limit == 0 {
+		return defaultQueryLimit
+	}
+	return req.Limit
+}
 
-	resourceType := k8s.NormalizeResourceName(req.ResourceType)
+// limitResources truncates sorted resources to at most limit entries (0 = unlimited)
+func limitResources(resources []*store.Resource, limit int) []*store.Resource {
+	if limit > 0 && len(resources) > limit {
+		return resources[:limit]
+	}
+	return resources
+}
 
+// prepareCompletion resolves a resource type and makes sure it is watched and synced
+func (s *Server) prepareCompletion(ctx context.Context, contextName, resourceType string) (*schema.GroupVersionResource, bool, error) {
 	// Get or discover the GVR for this resource type
 	gvr, namespaced, err := s.resolveGVR(contextName, resourceType)
 	if err != nil {
-		return &Response{Success: false, Error: err.Error()}
+		return nil, false, err
 	}
 
 	// Ensure we're watching this resource
 	if !s.watchManager.IsWatching(contextName, *gvr) {
 		if err := s.watchManager.StartWatching(ctx, contextName, *gvr, namespaced); err != nil {
-			return &Response{Success: false, Error: fmt.Sprintf("failed to start watch: %v", err)}
+			return nil, false, fmt.Errorf("failed to start watch: %v", err)
 		}
 	}
-	
+
 	// Wait for data to be populated
-	s.waitForSync(contextName, *gvr, 1*time.Second)
+	s.waitForSync(contextName, *gvr, s.syncTimeout(*gvr))
 
-	// Get resources from store
-	// If namespace is empty and resource is namespaced, return ALL namespaced resources
+	return gvr, namespaced, nil
+}
+
+// syncTimeout returns how long a completion waits for the initial list of a resource:
+// the ready gate timeout for default resources when server.readyGate is set, otherwise
+// a short wait after which possibly partial results are returned
+func (s *Server) syncTimeout(gvr schema.GroupVersionResource) time.Duration {
+	if s.config.Server.ReadyGate && isDefaultResource(gvr) {
+		return s.config.Server.ReadyGateTimeout
+	}
+	return 1 * time.Second
+}
+
+// badWaitingReasons are waiting reasons that need attention rather than time
+var badWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"InvalidImageName":           true,
+}
+
+// containerState formats a container status as a colored state: green when running and
+// ready or exited successfully, yellow while starting, red when failing
+func containerState(cs map[string]interface{}) string {
+	state, _ := cs["state"].(map[string]interface{})
+	ready, _ := cs["ready"].(bool)
+
+	if _, ok := state["running"]; ok {
+		if ready {
+			return "\033[32mrunning\033[0m"
+		}
+		return "\033[33mrunning (not ready)\033[0m"
+	}
+	if waiting, ok := state["waiting"].(map[string]interface{}); ok {
+		reason, _ := waiting["reason"].(string)
+		if reason == "" {
+			reason = "waiting"
+		}
+		if badWaitingReasons[reason] {
+			return "\033[31m" + reason + "\033[0m"
+		}
+		return "\033[33m" + reason + "\033[0m"
+	}
+	if terminated, ok := state["terminated"].(map[string]interface{}); ok {
+		reason, _ := terminated["reason"].(string)
+		if reason == "" {
+			reason = "terminated"
+		}
+		// Exit codes decode as int64 or float64 depending on the source
+		if fmt.Sprint(terminated["exitCode"]) == "0" {
+			return "\033[32m" + reason + "\033[0m"
+		}
+		return "\033[31m" + reason + "\033[0m"
+	}
+	return "\033[33munknown\033[0m"
+}
+
+// listSorted returns cached resources in display order.
+// If namespace is empty and resource is namespaced, return ALL namespaced resources.
+func (s *Server) listSorted(contextName string, gvr schema.GroupVersionResource, namespace string, namespaced bool) []*store.Resource {
 	var resources []*store.Resource
 	if namespaced {
-		resources = s.store.ListNamespaced(contextName, *gvr, namespace)
+		resources = s.store.ListNamespaced(contextName, gvr, namespace)
 	} else {
-		resources = s.store.ListClusterScoped(contextName, *gvr)
+		resources = s.store.ListClusterScoped(contextName, gvr)
 	}
 
-	// Sort by name using slices.SortFunc (faster than sort.Slice)
-	slices.SortFunc(resources, func(a, b *store.Resource) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+	sortResources(resources)
 
-	output := s.formatter.Format(resources, resourceType)
+	return resources
+}
 
-	return &Response{
-		Success: true,
-		Output:  output,
+// maxPodCandidates bounds the candidates listed for an ambiguous pod name
+const maxPodCandidates = 10
+
+// findPod returns a cached pod by name. A name that isn't found exactly (after trimming
+// whitespace) is resolved as a prefix if it matches a single pod in the namespace;
+// otherwise the error lists the candidates.
+func (s *Server) findPod(contextName, namespace, name string) (*store.Resource, error) {
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("pod_name is required")
+	}
+
+	if pod := s.store.Get(contextName, podsGVR, namespace, name); pod != nil && pod.Object != nil {
+		return pod, nil
+	}
+
+	var matches []*store.Resource
+	for _, pod := range s.store.ListNamespaced(contextName, podsGVR, namespace) {
+		if strings.HasPrefix(pod.Name, name) && pod.Object != nil {
+			matches = append(matches, pod)
+		}
 	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.New("pod not found in cache")
+	case 1:
+		return matches[0], nil
+	}
+
+	sortResources(matches)
+	candidates := make([]string, 0, min(len(matches), maxPodCandidates))
+	for _, pod := range matches[:min(len(matches), maxPodCandidates)] {
+		candidates = append(candidates, pod.Name)
+	}
+	if len(matches) > maxPodCandidates {
+		candidates = append(candidates, fmt.Sprintf("... (%d more)", len(matches)-maxPodCandidates))
+	}
+	return nil, fmt.Errorf("pod %q is ambiguous, matches: %s", name, strings.Join(candidates, ", "))
 }
 
 // handleContainers returns container names for a pod from cache
@@ -347,12 +1294,9 @@ func (s *Server) handleContainers(req *Request) *Response {
 		return &Response{Success: false, Error: "pod_name is required"}
 	}
 
-	// Get the pod from the store
-	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
-	pod := s.store.Get(contextName, podsGVR, namespace, podName)
-
-	if pod == nil || pod.Object == nil {
-		return &Response{Success: false, Error: "pod not found in cache"}
+	pod, err := s.findPod(contextName, namespace, podName)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
 	}
 
 	type containerInfo struct {
@@ -388,11 +1332,31 @@ func (s *Server) handleContainers(req *Request) *Response {
 		return &Response{Success: false, Error: "no containers found"}
 	}
 
-	// Format: name<tab>type (init containers shown with "init" indicator)
-	var buf strings.Builder
-	buf.Grow(len(containers) * 32) // Pre-allocate reasonable capacity
+	// Current state per container, when enabled and the pod reports it
+	statuses := make(map[string]map[string]interface{})
+	if status, ok := pod.Object.Object["status"].(map[string]interface{}); ok && s.config.Server.ContainerStates {
+		for _, key := range []string{"containerStatuses", "initContainerStatuses"} {
+			list, _ := status[key].([]interface{})
+			for _, cs := range list {
+				if csMap, ok := cs.(map[string]interface{}); ok {
+					if name, ok := csMap["name"].(string); ok {
+						statuses[name] = csMap
+					}
+				}
+			}
+		}
+	}
+
+	// Format: name<tab>state<tab>type (init containers shown with "init" indicator).
+	// The name stays the first field so the shell can select it.
+	buf := getBuffer()
+	defer putBuffer(buf)
 	for _, c := range containers {
 		buf.WriteString(c.name)
+		if cs, ok := statuses[c.name]; ok {
+			buf.WriteByte('\t')
+			buf.WriteString(containerState(cs))
+		}
 		if c.isInit {
 			buf.WriteString("\t\033[33m(init)\033[0m\n")
 		} else {
@@ -407,7 +1371,7 @@ func (s *Server) handleContainers(req *Request) *Response {
 }
 
 // handlePorts returns container ports for a pod or service from cache
-func (s *Server) handlePorts(req *Request) *Response {
+func (s *Server) handlePorts(ctx context.Context, req *Request) *Response {
 	contextName := req.Context
 	if contextName == "" {
 		contextName = s.clientManager.GetCurrentContext()
@@ -426,21 +1390,96 @@ func (s *Server) handlePorts(req *Request) *Response {
 		return s.handleServicePorts(contextName, namespace, podName)
 	}
 
-	// Get the pod from the store
-	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
-	pod := s.store.Get(contextName, podsGVR, namespace, podName)
+	// For workloads, collect the ports of all pods they select
+	if gvr, ok := workloadGVRs[k8s.NormalizeResourceName(resourceType)]; ok {
+		// The workload and its pods may not be watched yet (replicasets aren't by default), so
+		// watch them first
+		podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+		for _, watched := range []schema.GroupVersionResource{gvr, podsGVR} {
+			if s.store.IsWatching(contextName, watched) {
+				continue
+			}
+			if _, _, err := s.prepareCompletion(ctx, contextName, watched.Resource); err != nil {
+				return &Response{Success: false, Error: err.Error()}
+			}
+		}
+		return s.handleWorkloadPorts(contextName, namespace, gvr, podName)
+	}
 
-	if pod == nil || pod.Object == nil {
-		return &Response{Success: false, Error: "pod not found in cache"}
+	pod, err := s.findPod(contextName, namespace, podName)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
 	}
 
-	type portInfo struct {
-		containerPort int64
-		protocol      string
-		containerName string
-		portName      string
+	return formatContainerPorts(podContainerPorts(pod))
+}
+
+// workloadGVRs are the workloads whose ports are aggregated from their pods
+var workloadGVRs = map[string]schema.GroupVersionResource{
+	"deployments":  {Group: "apps", Version: "v1", Resource: "deployments"},
+	"statefulsets": {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"daemonsets":   {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	"replicasets":  {Group: "apps", Version: "v1", Resource: "replicasets"},
+}
+
+// containerPort is a port declared by a pod's container
+type containerPort struct {
+	containerPort int64
+	protocol      string
+	containerName string
+	portName      string
+}
+
+// handleWorkloadPorts returns the distinct container ports of all cached pods matched
+// by a workload's selector, so suggestions stay complete while single pods restart
+func (s *Server) handleWorkloadPorts(contextName, namespace string, gvr schema.GroupVersionResource, name string) *Response {
+	workload := s.store.Get(contextName, gvr, namespace, name)
+	if workload == nil || workload.Object == nil {
+		return &Response{Success: false, Error: gvr.Resource + " not found in cache"}
 	}
-	var ports []portInfo
+
+	selectorMap, ok, _ := unstructured.NestedMap(workload.Object.Object, "spec", "selector")
+	if !ok {
+		return &Response{Success: false, Error: "no pod selector found"}
+	}
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, &labelSelector); err != nil {
+		return &Response{Success: false, Error: fmt.Sprintf("invalid pod selector: %v", err)}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return &Response{Success: false, Error: fmt.Sprintf("invalid pod selector: %v", err)}
+	}
+
+	podsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	seen := make(map[string]bool)
+	var ports []containerPort
+	for _, pod := range s.store.ListNamespaced(contextName, podsGVR, namespace) {
+		if pod.Object == nil || !selector.Matches(labels.Set(pod.Object.GetLabels())) {
+			continue
+		}
+		for _, p := range podContainerPorts(pod) {
+			key := strconv.FormatInt(p.containerPort, 10) + "/" + p.protocol
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ports = append(ports, p)
+		}
+	}
+
+	slices.SortFunc(ports, func(a, b containerPort) int {
+		if c := cmp.Compare(a.containerPort, b.containerPort); c != 0 {
+			return c
+		}
+		return strings.Compare(a.protocol, b.protocol)
+	})
+	return formatContainerPorts(ports)
+}
+
+// podContainerPorts returns the ports declared by a pod's containers
+func podContainerPorts(pod *store.Resource) []containerPort {
+	var ports []containerPort
 
 	// Extract container ports from spec.containers
 	if spec, ok := pod.Object.Object["spec"].(map[string]interface{}); ok {
@@ -454,7 +1493,7 @@ func (s *Server) handlePorts(req *Request) *Response {
 					if portsList, ok := container["ports"].([]interface{}); ok {
 						for _, p := range portsList {
 							if port, ok := p.(map[string]interface{}); ok {
-								pi := portInfo{containerName: containerName}
+								pi := containerPort{containerName: containerName}
 								pi.containerPort = extractInt64(port["containerPort"])
 								if proto, ok := port["protocol"].(string); ok {
 									pi.protocol = proto
@@ -475,19 +1514,24 @@ func (s *Server) handlePorts(req *Request) *Response {
 		}
 	}
 
+	return ports
+}
+
+// formatContainerPorts formats container ports for a ports response
+func formatContainerPorts(ports []containerPort) *Response {
 	if len(ports) == 0 {
 		return &Response{Success: false, Error: "no ports found"}
 	}
 
 	// Format: port<tab>protocol<tab>container<tab>name
-	var buf strings.Builder
-	buf.Grow(len(ports) * 48) // Pre-allocate reasonable capacity
+	buf := getBuffer()
+	defer putBuffer(buf)
 	for _, p := range ports {
 		portName := p.portName
 		if portName == "" {
 			portName = "-"
 		}
-		fmt.Fprintf(&buf, "%d\t%s\t%s\t%s\n", p.containerPort, p.protocol, p.containerName, portName)
+		fmt.Fprintf(buf, "%d\t%s\t%s\t%s\n", p.containerPort, p.protocol, p.containerName, portName)
 	}
 
 	return &Response{
@@ -542,8 +1586,8 @@ func (s *Server) handleServicePorts(contextName, namespace, serviceName string)
 	}
 
 	// Format: port<tab>targetPort<tab>protocol<tab>name
-	var buf strings.Builder
-	buf.Grow(len(ports) * 48)
+	buf := getBuffer()
+	defer putBuffer(buf)
 	for _, p := range ports {
 		portName := p.name
 		if portName == "" {
@@ -553,7 +1597,7 @@ func (s *Server) handleServicePorts(contextName, namespace, serviceName string)
 		if targetPort == "" || targetPort == "<nil>" {
 			targetPort = "-"
 		}
-		fmt.Fprintf(&buf, "%d\t%s\t%s\t%s\n", p.port, targetPort, p.protocol, portName)
+		fmt.Fprintf(buf, "%d\t%s\t%s\t%s\n", p.port, targetPort, p.protocol, portName)
 	}
 
 	return &Response{
@@ -599,11 +1643,20 @@ func (s *Server) handleLabels(ctx context.Context, req *Request) *Response {
 		resources = s.store.ListClusterScoped(contextName, *gvr)
 	}
 
-	// Collect unique label key=value pairs
-	// Use map of maps to avoid repeated string concatenations
-	labelsMap := make(map[string]map[string]struct{})
-	count := 0
+	// Collect label key=value pairs with the number of resources carrying each
+	labelsMap := collectLabels(resources)
+
+	return &Response{
+		Success: true,
+		Output:  formatLabels(labelsMap, req.Counts, req.SortByCount),
+		Warning: namespaceWarning(resourceType, namespace, namespaced),
+	}
+}
 
+// collectLabels counts the resources carrying each label key=value pair.
+// Uses a map of maps to avoid repeated string concatenations.
+func collectLabels(resources []*store.Resource) map[string]map[string]int {
+	labelsMap := make(map[string]map[string]int)
 	for _, res := range resources {
 		if res.Object == nil {
 			continue
@@ -613,38 +1666,49 @@ func (s *Server) handleLabels(ctx context.Context, req *Request) *Response {
 				for key, value := range labels {
 					if strVal, ok := value.(string); ok {
 						if _, exists := labelsMap[key]; !exists {
-							labelsMap[key] = make(map[string]struct{})
-						}
-						if _, exists := labelsMap[key][strVal]; !exists {
-							labelsMap[key][strVal] = struct{}{}
-							count++
+							labelsMap[key] = make(map[string]int)
 						}
+						labelsMap[key][strVal]++
 					}
 				}
 			}
 		}
 	}
+	return labelsMap
+}
+
+// formatLabels renders label pairs one per line, sorted by key=value.
+// With counts each line is "key=value\t<count>"; byCount orders by count descending.
+func formatLabels(labelsMap map[string]map[string]int, counts, byCount bool) string {
+	type labelCount struct {
+		label string
+		count int
+	}
 
-	// Convert to sorted list
-	labelList := make([]string, 0, count)
+	var labelList []labelCount
 	for key, values := range labelsMap {
-		for val := range values {
-			labelList = append(labelList, key+"="+val)
+		for val, n := range values {
+			labelList = append(labelList, labelCount{label: key + "=" + val, count: n})
 		}
 	}
-	slices.Sort(labelList)
+	slices.SortFunc(labelList, func(a, b labelCount) int {
+		if byCount && a.count != b.count {
+			return b.count - a.count
+		}
+		return strings.Compare(a.label, b.label)
+	})
 
-	var buf strings.Builder
-	buf.Grow(len(labelList) * 32)
-	for _, label := range labelList {
-		buf.WriteString(label)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for _, l := range labelList {
+		buf.WriteString(l.label)
+		if counts {
+			buf.WriteByte('\t')
+			buf.WriteString(strconv.Itoa(l.count))
+		}
 		buf.WriteByte('\n')
 	}
-
-	return &Response{
-		Success: true,
-		Output:  buf.String(),
-	}
+	return buf.String()
 }
 
 // handleFieldValues returns unique values for a field (for field selector completion)
@@ -721,8 +1785,8 @@ func (s *Server) handleFieldValues(ctx context.Context, req *Request) *Response
 	}
 	slices.Sort(values)
 
-	var buf strings.Builder
-	buf.Grow(len(values) * (len(fieldName) + 16))
+	buf := getBuffer()
+	defer putBuffer(buf)
 	for _, v := range values {
 		buf.WriteString(fieldName)
 		buf.WriteByte('=')
@@ -730,90 +1794,336 @@ func (s *Server) handleFieldValues(ctx context.Context, req *Request) *Response
 		buf.WriteByte('\n')
 	}
 
-	return &Response{
-		Success: true,
-		Output:  buf.String(),
+	return &Response{
+		Success: true,
+		Output:  buf.String(),
+		Warning: namespaceWarning(resourceType, namespace, namespaced),
+	}
+}
+
+// extractInt64 extracts an int64 from interface{} (handles both int64 and float64)
+func extractInt64(val interface{}) int64 {
+	if i, ok := val.(int64); ok {
+		return i
+	}
+	if f, ok := val.(float64); ok {
+		return int64(f)
+	}
+	return 0
+}
+
+// getNestedString extracts a string value from nested map using dot notation
+// Optimized to avoid strings.Split allocations
+func getNestedString(obj map[string]interface{}, path string) string {
+	if path == "" || obj == nil {
+		return ""
+	}
+
+	// Remove leading dot
+	if len(path) > 0 && path[0] == '.' {
+		path = path[1:]
+	}
+
+	var current interface{} = obj
+	start := 0
+	for i := 0; i <= len(path); i++ {
+		if i == len(path) || path[i] == '.' {
+			if start == i {
+				start = i + 1
+				continue // Empty key
+			}
+			
+			key := path[start:i]
+			start = i + 1
+
+			// Traverse
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			
+			val, exists := m[key]
+			if !exists {
+				return ""
+			}
+			current = val
+		}
+	}
+
+	if s, ok := current.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// handleStatus handles a status request
+func (s *Server) handleStatus() *Response {
+	watched := s.watchManager.WatchedResources()
+	watchedStrings := make(map[string][]string)
+	lastUpdated := make(map[string]map[string]string)
+	for ctx, gvrs := range watched {
+		for _, gvr := range gvrs {
+			watchedStrings[ctx] = append(watchedStrings[ctx], gvr.Resource)
+			if updated := s.store.LastUpdated(ctx, gvr); !updated.IsZero() {
+				if lastUpdated[ctx] == nil {
+					lastUpdated[ctx] = make(map[string]string)
+				}
+				lastUpdated[ctx][gvr.Resource] = updated.Format(time.RFC3339)
+			}
+		}
+	}
+
+	statsSince := s.stats.ResetAt()
+	if statsSince.IsZero() {
+		statsSince = s.startTime
+	}
+
+	runtimeInfo := s.stats.Runtime()
+
+	var currentContext, kubeconfigPath string
+	if s.clientManager != nil {
+		currentContext = s.clientManager.GetCurrentContext()
+		kubeconfigPath = s.clientManager.KubeconfigPath()
+		runtimeInfo.CachedClients = s.clientManager.ClientCount()
+	}
+
+	lastSync := make(map[string]map[string]string)
+	for contextName, resources := range s.watchManager.LastSync() {
+		lastSync[contextName] = make(map[string]string, len(resources))
+		for resource, synced := range resources {
+			lastSync[contextName][resource] = synced.Format(time.RFC3339)
+		}
+	}
+
+	s.initializedContextsMu.RLock()
+	defaultsSynced := make(map[string]bool, len(s.initializedContexts))
+	for contextName := range s.initializedContexts {
+		defaultsSynced[contextName] = s.defaultsSynced(contextName)
+	}
+	s.initializedContextsMu.RUnlock()
+
+	return &Response{
+		Success: true,
+		Status: &StatusInfo{
+			CurrentContext:   currentContext,
+			KubeconfigPath:   kubeconfigPath,
+			Uptime:           time.Since(s.startTime).Round(time.Second).String(),
+			ResourceCount:    s.store.Count(),
+			WatchedResources: watchedStrings,
+			ResourceStats:    s.store.Stats(),
+			DefaultsSynced:   defaultsSynced,
+			LastSync:         lastSync,
+			LastUpdated:      lastUpdated,
+			Clusters:         s.clusterInfo(),
+			Requests:         s.stats.Requests(),
+			Events:           s.watchManager.EventCounts(),
+			StatsSince:       statsSince.Format(time.RFC3339),
+			Latency:          s.stats.Latencies(),
+			Cache:            s.stats.Cache(),
+			Runtime:          runtimeInfo,
+		},
+	}
+}
+
+// handleWatchList returns the active watches, optionally for a single context,
+// sorted by context then resource
+func (s *Server) handleWatchList(req *Request) *Response {
+	var watches []WatchInfo
+	for contextName, gvrs := range s.watchManager.WatchedResources() {
+		if req.Context != "" && contextName != req.Context {
+			continue
+		}
+		for _, gvr := range gvrs {
+			watches = append(watches, WatchInfo{
+				Context:  contextName,
+				Resource: gvr.Resource,
+				Group:    gvr.Group,
+				Version:  gvr.Version,
+				Synced:   s.store.IsWatching(contextName, gvr),
+			})
+		}
+	}
+
+	slices.SortFunc(watches, func(a, b WatchInfo) int {
+		if c := strings.Compare(a.Context, b.Context); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Resource, b.Resource); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Group, b.Group)
+	})
+
+	return &Response{Success: true, Watches: watches}
+}
+
+// handleResetStats zeroes the request and watch event counters
+func (s *Server) handleResetStats() *Response {
+	s.stats.Reset()
+	s.watchManager.ResetEventCounts()
+	return &Response{Success: true}
+}
+
+// handleContexts returns the contexts of the loaded kubeconfig, sorted by name
+func (s *Server) handleContexts() *Response {
+	currentContext := s.clientManager.GetCurrentContext()
+
+	names := s.clientManager.ListContexts()
+	slices.Sort(names)
+
+	contexts := make([]ContextInfo, 0, len(names))
+	for _, name := range names {
+		contexts = append(contexts, ContextInfo{
+			Name:      name,
+			Namespace: s.clientManager.GetContextNamespace(name),
+			Current:   name == currentContext,
+		})
+	}
+
+	return &Response{Success: true, Contexts: contexts}
+}
+
+// handleDebugDump returns the cached object for a resource as indented JSON.
+// This is the pruned representation kept in the store, not the object as served by the API.
+func (s *Server) handleDebugDump(req *Request) *Response {
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.clientManager.GetCurrentContext()
+	}
+
+	if req.ResourceName == "" {
+		return &Response{Success: false, Error: "resource_name is required"}
+	}
+
+	gvr, namespaced, err := s.resolveGVR(contextName, k8s.NormalizeResourceName(req.ResourceType))
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	var res *store.Resource
+	switch {
+	case !namespaced:
+		res = s.store.Get(contextName, *gvr, "", req.ResourceName)
+	case req.Namespace != "":
+		res = s.store.Get(contextName, *gvr, req.Namespace, req.ResourceName)
+	default:
+		// No namespace given: look the name up across all namespaces
+		var matches []string
+		for _, r := range s.store.ListNamespaced(contextName, *gvr, "") {
+			if r.Name == req.ResourceName {
+				res = r
+				matches = append(matches, r.Namespace)
+			}
+		}
+		if len(matches) > 1 {
+			slices.Sort(matches)
+			return &Response{Success: false, Error: fmt.Sprintf("%s %q exists in several namespaces (%s), use -n", gvr.Resource, req.ResourceName, strings.Join(matches, ", "))}
+		}
+	}
+
+	if res == nil || res.Object == nil {
+		return &Response{Success: false, Error: fmt.Sprintf("%s %q not found in cache", gvr.Resource, req.ResourceName)}
 	}
-}
 
-// extractInt64 extracts an int64 from interface{} (handles both int64 and float64)
-func extractInt64(val interface{}) int64 {
-	if i, ok := val.(int64); ok {
-		return i
-	}
-	if f, ok := val.(float64); ok {
-		return int64(f)
+	data, err := json.MarshalIndent(res.Object.Object, "", "  ")
+	if err != nil {
+		return &Response{Success: false, Error: fmt.Sprintf("failed to encode object: %v", err)}
 	}
-	return 0
+
+	return &Response{Success: true, Output: string(data) + "\n"}
 }
 
-// getNestedString extracts a string value from nested map using dot notation
-// Optimized to avoid strings.Split allocations
-func getNestedString(obj map[string]interface{}, path string) string {
-	if path == "" || obj == nil {
-		return ""
+// handleAPIResources returns the resource types discovered for a context, sorted by name
+func (s *Server) handleAPIResources(req *Request) *Response {
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.clientManager.GetCurrentContext()
 	}
 
-	// Remove leading dot
-	if len(path) > 0 && path[0] == '.' {
-		path = path[1:]
+	resources, err := s.getResourceInfo(contextName, true)
+	if err != nil {
+		return &Response{Success: false, Error: fmt.Sprintf("failed to discover resources: %v", err)}
+	}
+
+	apiResources := make([]APIResource, 0, len(resources))
+	for _, r := range resources {
+		apiResources = append(apiResources, APIResource{
+			Name:       r.GVR.Resource,
+			ShortNames: r.ShortNames,
+			Kind:       r.Kind,
+			Group:      r.GVR.Group,
+			Version:    r.GVR.Version,
+			Namespaced: r.Namespaced,
+			Columns:    s.cachedTableColumns(contextName, r.GVR),
+		})
+	}
+	slices.SortFunc(apiResources, func(a, b APIResource) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Group, b.Group)
+	})
+
+	return &Response{Success: true, APIResources: apiResources}
+}
+
+// handleResourceTypes returns discovered resource type names for shell completion,
+// one per line and sorted, with short names as a tab-separated hint
+func (s *Server) handleResourceTypes(req *Request) *Response {
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.clientManager.GetCurrentContext()
 	}
 
-	var current interface{} = obj
-	start := 0
-	for i := 0; i <= len(path); i++ {
-		if i == len(path) || path[i] == '.' {
-			if start == i {
-				start = i + 1
-				continue // Empty key
-			}
-			
-			key := path[start:i]
-			start = i + 1
+	resources, err := s.getResourceInfo(contextName, true)
+	if err != nil {
+		return &Response{Success: false, Error: fmt.Sprintf("failed to discover resources: %v", err)}
+	}
 
-			// Traverse
-			m, ok := current.(map[string]interface{})
-			if !ok {
-				return ""
-			}
-			
-			val, exists := m[key]
-			if !exists {
-				return ""
+	// The same resource name can be served by several groups (e.g. events)
+	shortNames := make(map[string][]string)
+	for _, r := range resources {
+		hints := shortNames[r.GVR.Resource]
+		for _, short := range r.ShortNames {
+			if !slices.Contains(hints, short) {
+				hints = append(hints, short)
 			}
-			current = val
 		}
+		shortNames[r.GVR.Resource] = hints
 	}
 
-	if s, ok := current.(string); ok {
-		return s
+	names := make([]string, 0, len(shortNames))
+	for name := range shortNames {
+		names = append(names, name)
 	}
-	return ""
-}
+	slices.Sort(names)
 
-// handleStatus handles a status request
-func (s *Server) handleStatus() *Response {
-	watched := s.watchManager.WatchedResources()
-	watchedStrings := make(map[string][]string)
-	for ctx, gvrs := range watched {
-		for _, gvr := range gvrs {
-			watchedStrings[ctx] = append(watchedStrings[ctx], gvr.Resource)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for _, name := range names {
+		buf.WriteString(name)
+		if len(shortNames[name]) > 0 {
+			buf.WriteString("\t")
+			buf.WriteString(strings.Join(shortNames[name], ","))
 		}
+		buf.WriteString("\n")
 	}
 
-	return &Response{
-		Success: true,
-		Status: &StatusInfo{
-			Uptime:           time.Since(s.startTime).Round(time.Second).String(),
-			ResourceCount:    s.store.Count(),
-			WatchedResources: watchedStrings,
-			ResourceStats:    s.store.Stats(),
-		},
-	}
+	return &Response{Success: true, Output: buf.String()}
 }
 
 // handleRefresh handles a refresh request
-func (s *Server) handleRefresh() *Response {
+func (s *Server) handleRefresh(ctx context.Context, req *Request) *Response {
+	if req.ClientsOnly {
+		return s.handleRefreshClients()
+	}
+	if req.Soft {
+		return s.handleSoftRefresh(ctx, req.Context)
+	}
+	if req.Context != "" {
+		return s.handleRefreshContext(ctx, req.Context)
+	}
+
 	if err := s.clientManager.RefreshConfig(); err != nil {
 		return &Response{Success: false, Error: err.Error()}
 	}
@@ -822,10 +2132,17 @@ func (s *Server) handleRefresh() *Response {
 	s.watchManager.StopAll()
 
 	// Clear resource cache
-	s.resourceCacheMu.Lock()
-	s.resourceCache = make(map[string][]k8s.ResourceInfo)
-	s.resourceCacheAccess = make(map[string]time.Time)
-	s.resourceCacheMu.Unlock()
+	s.discoveryCacheMu.Lock()
+	s.discoveryCache = make(map[string][]k8s.ResourceInfo)
+	s.discoveryCacheAccess = make(map[string]time.Time)
+	s.discoveryCacheComplete = make(map[string]bool)
+	s.discoveryCacheVersion = make(map[string]string)
+	s.discoveryFailures = make(map[string]discoveryFailure)
+	s.discoveryCacheMu.Unlock()
+
+	s.tableColumnsMu.Lock()
+	s.tableColumns = make(map[tableColumnsKey][]metav1.TableColumnDefinition)
+	s.tableColumnsMu.Unlock()
 
 	// Clear initialized contexts tracking
 	s.initializedContextsMu.Lock()
@@ -839,6 +2156,86 @@ func (s *Server) handleRefresh() *Response {
 	return &Response{Success: true}
 }
 
+// handleSoftRefresh reloads the kubeconfig and re-lists watched resources in place,
+// for one context or all watched contexts, so completions keep working meanwhile
+func (s *Server) handleSoftRefresh(ctx context.Context, contextName string) *Response {
+	// The clients are kept: dropping them would rebuild every client (and re-run its
+	// credential plugin) for the re-list, and the watches keep using the old ones anyway
+	if err := s.clientManager.ReloadConfig(); err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	contexts := s.watchManager.ActiveContexts()
+	if contextName != "" {
+		contexts = []string{contextName}
+	}
+
+	var errs []string
+	for _, c := range contexts {
+		if _, err := s.watchManager.Resync(ctx, c); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c, err))
+		}
+	}
+	if len(errs) > 0 {
+		return &Response{Success: false, Error: strings.Join(errs, "; ")}
+	}
+
+	return &Response{Success: true}
+}
+
+// handleRefreshClients rebuilds the Kubernetes clients without stopping watches or
+// clearing caches, for credential rotation. Watches switch to the new clients when
+// they next reconnect.
+func (s *Server) handleRefreshClients() *Response {
+	err := s.clientManager.RefreshClientsOnly()
+
+	// Discovery failures may have been caused by the old credentials
+	s.discoveryCacheMu.Lock()
+	s.discoveryFailures = make(map[string]discoveryFailure)
+	s.discoveryCacheMu.Unlock()
+
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+	return &Response{Success: true}
+}
+
+// handleRefreshContext refreshes a single context, leaving other contexts warm
+func (s *Server) handleRefreshContext(ctx context.Context, contextName string) *Response {
+	if err := s.clientManager.RefreshContext(contextName); err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	// Stop this context's watches and clear its cached data
+	s.watchManager.StopContext(contextName)
+
+	s.discoveryCacheMu.Lock()
+	delete(s.discoveryCache, contextName)
+	delete(s.discoveryCacheAccess, contextName)
+	delete(s.discoveryCacheComplete, contextName)
+	delete(s.discoveryCacheVersion, contextName)
+	delete(s.discoveryFailures, contextName)
+	s.discoveryCacheMu.Unlock()
+
+	s.tableColumnsMu.Lock()
+	for key := range s.tableColumns {
+		if key.context == contextName {
+			delete(s.tableColumns, key)
+		}
+	}
+	s.tableColumnsMu.Unlock()
+
+	s.initializedContextsMu.Lock()
+	delete(s.initializedContexts, contextName)
+	delete(s.initializedContextsAccess, contextName)
+	s.initializedContextsMu.Unlock()
+
+	// Re-create the default watches with the new credentials
+	go s.initializeContextWatches(ctx, contextName)
+
+	return &Response{Success: true}
+}
+
 // handleWatch handles a watch request
 func (s *Server) handleWatch(ctx context.Context, req *Request) *Response {
 	contextName := req.Context
@@ -887,6 +2284,61 @@ func (s *Server) handleStopWatch(req *Request) *Response {
 	return &Response{Success: true}
 }
 
+// handleSetLogLevel changes the server's log level without a restart
+func (s *Server) handleSetLogLevel(req *Request) *Response {
+	if s.logLevel == nil {
+		return &Response{Success: false, Error: "log level cannot be changed at runtime"}
+	}
+
+	level, err := ParseLogLevel(req.LogLevel)
+	if err != nil {
+		return &Response{Success: false, Error: err.Error()}
+	}
+
+	previous := s.logLevel.Level()
+	s.logLevel.Set(level)
+	s.logger.Info("log level changed", "from", previous, "to", level)
+
+	return &Response{Success: true, Output: fmt.Sprintf("log level set to %s (was %s)\n", level, previous)}
+}
+
+// ParseLogLevel parses a log level name (debug, info, warn or error)
+func ParseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+	}
+}
+
+// handleStopContext stops every watch for a context and clears its cached data,
+// the manual counterpart to cleanupOldContexts
+func (s *Server) handleStopContext(req *Request) *Response {
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.clientManager.GetCurrentContext()
+	}
+
+	// StopContext also clears the context's data from the store
+	s.watchManager.StopContext(contextName)
+
+	s.initializedContextsMu.Lock()
+	delete(s.initializedContexts, contextName)
+	delete(s.initializedContextsAccess, contextName)
+	s.initializedContextsMu.Unlock()
+
+	s.logger.Info("stopped all watches for context", "context", contextName)
+
+	return &Response{Success: true}
+}
+
 // handleRecordRecent records a recently accessed resource
 func (s *Server) handleRecordRecent(req *Request) *Response {
 	contextName := req.Context
@@ -941,74 +2393,369 @@ func (s *Server) periodicCleanup(ctx context.Context) {
 				s.logger.Info("cleaned up unused clients", "count", removed)
 			}
 
-			// Cleanup resource cache entries older than 1 hour
-			s.cleanupResourceCache(1 * time.Hour)
+			// Discovery rarely changes and is expensive, so it has its own (longer) TTL
+			s.cleanupDiscoveryCache(s.config.Server.DiscoveryTTL)
 
 			// Cleanup contexts not accessed in the last hour
 			s.cleanupOldContexts(1 * time.Hour)
+
+			if timeout := s.config.Server.StaleWatchTimeout; timeout > 0 {
+				s.restartStaleWatches(timeout)
+			}
+		}
+	}
+}
+
+// restartStaleWatches restarts synced watches of large resource sets that haven't
+// changed within timeout. On a busy cluster that means the watch most likely stopped
+// delivering events without failing.
+func (s *Server) restartStaleWatches(timeout time.Duration) {
+	for contextName, gvrs := range s.watchManager.WatchedResources() {
+		for _, gvr := range gvrs {
+			if !s.store.IsWatching(contextName, gvr) {
+				continue // Initial list still in progress
+			}
+			lastUpdated := s.store.LastUpdated(contextName, gvr)
+			if time.Since(lastUpdated) < timeout || s.store.CountOf(contextName, gvr) < staleWatchMinObjects {
+				continue
+			}
+			s.logger.Warn("watch looks stale, restarting",
+				"context", contextName,
+				"resource", gvr.Resource,
+				"lastUpdated", lastUpdated.Format(time.RFC3339),
+			)
+			s.watchManager.RestartWatch(contextName, gvr)
+		}
+	}
+}
+
+// watchCurrentContext polls the kubeconfig current-context and proactively initializes
+// watches for a newly selected context (e.g., after kubectx), so completions are warm.
+// GetCurrentContext only re-reads the kubeconfig when its modification time changes.
+func (s *Server) watchCurrentContext(ctx context.Context) {
+	ticker := time.NewTicker(contextPollInterval)
+	defer ticker.Stop()
+
+	lastContext := s.clientManager.GetCurrentContext()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			currentContext := s.clientManager.GetCurrentContext()
+			if currentContext == "" || currentContext == lastContext {
+				continue
+			}
+			s.logger.Info("current context changed", "from", lastContext, "to", currentContext)
+			lastContext = currentContext
+			s.initializeContextWatches(ctx, currentContext)
 		}
 	}
 }
 
 // resolveGVR resolves a resource type name to a GVR
 func (s *Server) resolveGVR(contextName, resourceType string) (*schema.GroupVersionResource, bool, error) {
+	// A configured preference (bare name -> resource.group) overrides the built-in choice
+	lookup := resourceType
+	preferred, hasPreference := s.config.Server.ResourcePreference[resourceType]
+	if hasPreference {
+		lookup = preferred
+	}
+
 	// Try preferred GVR first
-	if gvr := k8s.GetPreferredGVR(resourceType); gvr != nil {
-		// Determine if namespaced from known resources
-		namespaced := isKnownNamespaced(resourceType)
+	if gvr := k8s.GetPreferredGVR(lookup); gvr != nil {
+		if namespaced, ok := s.scopeOverride(resourceType, *gvr); ok {
+			return gvr, namespaced, nil
+		}
+		// Prefer discovery data when it has already run, else the static list
+		namespaced, ok := s.cachedNamespaced(contextName, *gvr)
+		if !ok {
+			namespaced = isKnownNamespaced(resourceType)
+		}
 		return gvr, namespaced, nil
 	}
 
 	// Fall back to discovery
-	resources, err := s.getResourceInfo(contextName)
+	resources, err := s.getResourceInfo(contextName, false)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to discover resources: %w", err)
 	}
 
-	resInfo := k8s.FindResource(resources, resourceType)
+	resInfo := k8s.FindResource(resources, lookup)
+	if resInfo == nil && s.config.Server.TargetedDiscovery {
+		// Targeted discovery only covers known groups, retry with every group
+		resources, err = s.getResourceInfo(contextName, true)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to discover resources: %w", err)
+		}
+		resInfo = k8s.FindResource(resources, lookup)
+	}
 	if resInfo == nil {
-		return nil, false, fmt.Errorf("unknown resource type: %s", resourceType)
+		return nil, false, fmt.Errorf("unknown resource type: %s", lookup)
 	}
 
+	s.logAmbiguity(contextName, resourceType, resources, resInfo.GVR, hasPreference)
+
+	if namespaced, ok := s.scopeOverride(resourceType, resInfo.GVR); ok {
+		return &resInfo.GVR, namespaced, nil
+	}
 	return &resInfo.GVR, resInfo.Namespaced, nil
 }
 
-// getResourceInfo gets cached or discovers resource info for a context
-func (s *Server) getResourceInfo(contextName string) ([]k8s.ResourceInfo, error) {
+// logAmbiguity logs, once per context and resource type, that a name matched resources of
+// several API groups and which one it resolved to: at Info when server.resourcePreference
+// chose it, otherwise as a warning, since the choice may not be the intended one
+func (s *Server) logAmbiguity(contextName, resourceType string, resources []k8s.ResourceInfo, resolved schema.GroupVersionResource, preferred bool) {
+	matches := k8s.FindAllResources(resources, resourceType)
+	if len(matches) < 2 {
+		return
+	}
+	if _, logged := s.ambiguityLogged.LoadOrStore(contextName+"/"+resourceType, true); logged {
+		return
+	}
+
+	qualified := func(gvr schema.GroupVersionResource) string {
+		if gvr.Group == "" {
+			return gvr.Resource
+		}
+		return gvr.Resource + "." + gvr.Group
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = qualified(m.GVR)
+	}
+	attrs := []any{"context", contextName, "resource", resourceType, "resolved", qualified(resolved), "matches", strings.Join(names, ", ")}
+	if preferred {
+		s.logger.Info("resolved ambiguous resource type by server.resourcePreference", attrs...)
+	} else {
+		s.logger.Warn("ambiguous resource type, set server.resourcePreference to choose", attrs...)
+	}
+}
+
+// scopeOverride returns the scope configured for a resource type, looked up by the
+// requested name, the plural resource name and its resource.group form
+func (s *Server) scopeOverride(resourceType string, gvr schema.GroupVersionResource) (bool, bool) {
+	names := []string{resourceType, gvr.Resource}
+	if gvr.Group != "" {
+		names = append(names, gvr.Resource+"."+gvr.Group)
+	}
+	return s.config.NamespacedOverride(names...)
+}
+
+// cachedNamespaced reports whether a GVR is namespaced according to cached discovery data,
+// without triggering discovery. The second result is false when the GVR isn't cached.
+func (s *Server) cachedNamespaced(contextName string, gvr schema.GroupVersionResource) (bool, bool) {
+	s.discoveryCacheMu.RLock()
+	defer s.discoveryCacheMu.RUnlock()
+
+	for _, r := range s.discoveryCache[contextName] {
+		if r.GVR == gvr {
+			return r.Namespaced, true
+		}
+	}
+	return false, false
+}
+
+// getResourceInfo gets cached or discovers resource info for a context.
+// When complete is set, results from targeted discovery are not accepted.
+func (s *Server) getResourceInfo(contextName string, complete bool) ([]k8s.ResourceInfo, error) {
 	// Fast path: check cache with read lock
-	s.resourceCacheMu.RLock()
-	cached, ok := s.resourceCache[contextName]
-	s.resourceCacheMu.RUnlock()
+	s.discoveryCacheMu.RLock()
+	cached, ok := s.discoveryCache[contextName]
+	if complete && !s.discoveryCacheComplete[contextName] {
+		ok = false
+	}
+	s.discoveryCacheMu.RUnlock()
+	s.stats.CountDiscovery(ok)
 
 	if ok {
 		// Update access time - needs lock
-		s.resourceCacheMu.Lock()
-		s.resourceCacheAccess[contextName] = time.Now()
-		s.resourceCacheMu.Unlock()
+		s.discoveryCacheMu.Lock()
+		s.discoveryCacheAccess[contextName] = time.Now()
+		s.discoveryCacheMu.Unlock()
 		return cached, nil
 	}
 
+	// A context that just failed discovery is likely still broken; don't retry yet
+	if err := s.recentDiscoveryFailure(contextName); err != nil {
+		return nil, err
+	}
+
 	// Slow path: discovery
-	// Note: We don't lock here to allow concurrent discovery and not block other requests.
+	// Note: We don't hold the cache lock here to allow concurrent discovery and not block
+	// other requests. Concurrent callers for the same context share a single discovery call.
+	targeted := !complete && s.config.Server.TargetedDiscovery
+	key := contextName
+	if targeted {
+		key += "\x00targeted"
+	}
+	v, err, _ := s.discoveryGroup.Do(key, func() (interface{}, error) {
+		resources, err := s.discover(contextName, targeted)
+		if err != nil {
+			s.discoveryCacheMu.Lock()
+			s.discoveryFailures[contextName] = discoveryFailure{err: err, at: time.Now()}
+			s.discoveryCacheMu.Unlock()
+		}
+		return resources, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]k8s.ResourceInfo), nil
+}
+
+// discover runs resource discovery for a context and saves the result to the cache
+func (s *Server) discover(contextName string, targeted bool) ([]k8s.ResourceInfo, error) {
 	client, err := s.clientManager.GetClient(contextName)
 	if err != nil {
 		return nil, err
 	}
 
-	resources, err := k8s.DiscoverResources(client)
+	var resources []k8s.ResourceInfo
+	if targeted {
+		resources, err = k8s.DiscoverGroups(client, s.discoveryGroups(), s.logger)
+	} else {
+		resources, err = k8s.DiscoverResources(client, s.logger)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Save to cache
-	s.resourceCacheMu.Lock()
-	s.resourceCache[contextName] = resources
-	s.resourceCacheAccess[contextName] = time.Now()
-	s.resourceCacheMu.Unlock()
+	s.discoveryCacheMu.Lock()
+	s.discoveryCache[contextName] = resources
+	s.discoveryCacheAccess[contextName] = time.Now()
+	s.discoveryCacheComplete[contextName] = !targeted
+	delete(s.discoveryFailures, contextName)
+	s.discoveryCacheMu.Unlock()
 
 	return resources, nil
 }
 
+// discoveryFailure is a failed discovery of a context
+type discoveryFailure struct {
+	err error
+	at  time.Time
+}
+
+// recentDiscoveryFailure returns the error of a context's last discovery if it failed
+// within discoveryFailureTTL
+func (s *Server) recentDiscoveryFailure(contextName string) error {
+	s.discoveryCacheMu.RLock()
+	failure, ok := s.discoveryFailures[contextName]
+	s.discoveryCacheMu.RUnlock()
+
+	if !ok || time.Since(failure.at) >= discoveryFailureTTL {
+		return nil
+	}
+	retryIn := (discoveryFailureTTL - time.Since(failure.at)).Round(time.Second)
+	return fmt.Errorf("%w (retrying in %s)", failure.err, retryIn)
+}
+
+// probeServerVersion asks a context's API server for its version, giving up after
+// clusterProbeTimeout, so the answer shows whether the cluster is reachable right now.
+// A successful answer is cached with the discovery results; on failure the last known
+// version is returned with the error.
+func (s *Server) probeServerVersion(contextName string) (string, error) {
+	v, err, _ := s.discoveryGroup.Do(contextName+"\x00version", func() (interface{}, error) {
+		client, err := s.clientManager.GetClient(contextName)
+		if err != nil {
+			return "", err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), clusterProbeTimeout)
+		defer cancel()
+		version, err := k8s.ServerVersion(ctx, client)
+		if err != nil {
+			return "", err
+		}
+
+		s.discoveryCacheMu.Lock()
+		s.discoveryCacheVersion[contextName] = version
+		s.discoveryCacheAccess[contextName] = time.Now()
+		s.discoveryCacheMu.Unlock()
+		return version, nil
+	})
+	if err != nil {
+		s.discoveryCacheMu.RLock()
+		version := s.discoveryCacheVersion[contextName]
+		s.discoveryCacheMu.RUnlock()
+		return version, err
+	}
+	return v.(string), nil
+}
+
+// clusterInfo reports the server version and reachability of each active context,
+// probing them concurrently
+func (s *Server) clusterInfo() map[string]ClusterInfo {
+	contexts := s.watchManager.ActiveContexts()
+
+	// Contexts whose discovery failed are reported even when nothing is watched there
+	s.discoveryCacheMu.RLock()
+	failures := maps.Clone(s.discoveryFailures)
+	s.discoveryCacheMu.RUnlock()
+	for contextName := range failures {
+		if !slices.Contains(contexts, contextName) {
+			contexts = append(contexts, contextName)
+		}
+	}
+	if len(contexts) == 0 {
+		return nil
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	clusters := make(map[string]ClusterInfo, len(contexts))
+	for _, contextName := range contexts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info := ClusterInfo{Reachable: true}
+			version, err := s.probeServerVersion(contextName)
+			if err != nil {
+				info = ClusterInfo{Error: err.Error()}
+			}
+			info.ServerVersion = version
+			if failure, ok := failures[contextName]; ok {
+				info.DiscoveryError = failure.err.Error()
+				info.DiscoveryFailedAt = failure.at.Format(time.RFC3339)
+			}
+
+			mu.Lock()
+			clusters[contextName] = info
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return clusters
+}
+
+// discoveryGroups returns the API groups used by targeted discovery:
+// the groups of the default resources plus those of configured resources
+func (s *Server) discoveryGroups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	add := func(group string) {
+		if !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
+	}
+
+	for _, group := range []string{"", "apps", "batch", "networking.k8s.io"} {
+		add(group)
+	}
+	for name := range s.config.Resources {
+		add(k8s.ResourceGroup(name))
+	}
+	for _, preferred := range s.config.Server.ResourcePreference {
+		add(k8s.ResourceGroup(preferred))
+	}
+
+	return groups
+}
+
 // waitForSync waits for a resource to be synced (watched) in the store
 func (s *Server) waitForSync(contextName string, gvr schema.GroupVersionResource, timeout time.Duration) {
 	// Fast check first
@@ -1034,21 +2781,33 @@ func (s *Server) waitForSync(contextName string, gvr schema.GroupVersionResource
 	}
 }
 
-// cleanupResourceCache removes resource cache entries older than maxAge
-func (s *Server) cleanupResourceCache(maxAge time.Duration) {
-	s.resourceCacheMu.Lock()
-	defer s.resourceCacheMu.Unlock()
+// cleanupDiscoveryCache removes discovery cache entries not accessed within maxAge
+func (s *Server) cleanupDiscoveryCache(maxAge time.Duration) {
+	s.discoveryCacheMu.Lock()
+	defer s.discoveryCacheMu.Unlock()
 
 	cutoff := time.Now().Add(-maxAge)
-	for contextName, lastAccess := range s.resourceCacheAccess {
+	for contextName, lastAccess := range s.discoveryCacheAccess {
 		if lastAccess.Before(cutoff) {
-			delete(s.resourceCache, contextName)
-			delete(s.resourceCacheAccess, contextName)
+			delete(s.discoveryCache, contextName)
+			delete(s.discoveryCacheAccess, contextName)
+			delete(s.discoveryCacheComplete, contextName)
+			delete(s.discoveryCacheVersion, contextName)
 			s.logger.Debug("cleaned up resource cache", "context", contextName)
 		}
 	}
 }
 
+// touchContext marks an initialized context as accessed, so cleanupOldContexts keeps it
+func (s *Server) touchContext(contextName string) {
+	s.initializedContextsMu.Lock()
+	defer s.initializedContextsMu.Unlock()
+
+	if s.initializedContexts[contextName] {
+		s.initializedContextsAccess[contextName] = time.Now()
+	}
+}
+
 // cleanupOldContexts removes context data for contexts not accessed within maxAge
 func (s *Server) cleanupOldContexts(maxAge time.Duration) {
 	s.initializedContextsMu.Lock()
@@ -1083,6 +2842,14 @@ func (s *Server) sendError(conn net.Conn, msg string) {
 	_, _ = conn.Write(data)
 }
 
+// namespaceWarning explains that a namespace was ignored for a cluster-scoped resource type
+func namespaceWarning(resourceType, namespace string, namespaced bool) string {
+	if namespaced || namespace == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s is cluster-scoped, ignoring namespace %q", resourceType, namespace)
+}
+
 // isKnownNamespaced returns whether a resource type is namespaced
 func isKnownNamespaced(resourceType string) bool {
 	clusterScoped := map[string]bool{
@@ -1,10 +1,29 @@
 package server
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pslijkhuis/kfzf/internal/config"
+	"github.com/pslijkhuis/kfzf/internal/fzf"
+	"github.com/pslijkhuis/kfzf/internal/k8s"
 	"github.com/pslijkhuis/kfzf/internal/store"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -73,6 +92,193 @@ func TestHandleServicePorts(t *testing.T) {
 	}
 }
 
+// TestFindPod tests exact, trimmed, unique-prefix and ambiguous pod lookups
+func TestFindPod(t *testing.T) {
+	s := &Server{store: store.NewStore()}
+
+	podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	for _, name := range []string{"web", "web-7d4b9-abcde", "web-7d4b9-fghij", "api-5f6c8-klmno"} {
+		s.store.Add("test-context", podGVR, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+			},
+		})
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		// An exact match wins over prefixes
+		{"web", "web", ""},
+		{" api-5f6c8-klmno\n", "api-5f6c8-klmno", ""},
+		{"api", "api-5f6c8-klmno", ""},
+		{"web-7d4b9", "", "web-7d4b9-abcde, web-7d4b9-fghij"},
+		{"db", "", "not found"},
+	}
+	for _, tt := range tests {
+		pod, err := s.findPod("test-context", "default", tt.name)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("findPod(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("findPod(%q) error = %v", tt.name, err)
+			continue
+		}
+		if pod.Name != tt.want {
+			t.Errorf("findPod(%q) = %s, want %s", tt.name, pod.Name, tt.want)
+		}
+	}
+}
+
+// TestHandleContainers_States tests per-container state markers from the pod status
+func TestHandleContainers_States(t *testing.T) {
+	cfg := config.DefaultConfig()
+	s := &Server{
+		config: cfg,
+		store:  store.NewStore(),
+	}
+
+	podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	container := func(name string) interface{} {
+		return map[string]interface{}{"name": name}
+	}
+	status := func(name string, ready bool, state map[string]interface{}) interface{} {
+		return map[string]interface{}{"name": name, "ready": ready, "state": state}
+	}
+	pod := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+			"spec": map[string]interface{}{
+				"containers":     []interface{}{container("app"), container("sidecar"), container("proxy"), container("new")},
+				"initContainers": []interface{}{container("migrate")},
+			},
+			"status": map[string]interface{}{
+				"containerStatuses": []interface{}{
+					status("app", true, map[string]interface{}{"running": map[string]interface{}{}}),
+					status("sidecar", false, map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}),
+					status("proxy", false, map[string]interface{}{"running": map[string]interface{}{}}),
+				},
+				"initContainerStatuses": []interface{}{
+					status("migrate", false, map[string]interface{}{"terminated": map[string]interface{}{"reason": "Completed", "exitCode": int64(0)}}),
+				},
+			},
+		},
+	}
+	s.store.Add("test-context", podGVR, pod)
+
+	// States are opt-in
+	resp := s.handleContainers(&Request{Context: "test-context", Namespace: "default", PodName: "web"})
+	if !resp.Success {
+		t.Fatalf("handleContainers failed: %s", resp.Error)
+	}
+	if want := "app\nsidecar\nproxy\nnew\nmigrate\t\033[33m(init)\033[0m\n"; resp.Output != want {
+		t.Errorf("Output = %q, want %q", resp.Output, want)
+	}
+
+	cfg.Server.ContainerStates = true
+	resp = s.handleContainers(&Request{Context: "test-context", Namespace: "default", PodName: "web"})
+	if !resp.Success {
+		t.Fatalf("handleContainers failed: %s", resp.Error)
+	}
+
+	expected := []string{
+		"app\t\033[32mrunning\033[0m",
+		"sidecar\t\033[31mCrashLoopBackOff\033[0m",
+		"proxy\t\033[33mrunning (not ready)\033[0m",
+		"new",
+		"migrate\t\033[32mCompleted\033[0m\t\033[33m(init)\033[0m",
+	}
+	lines := strings.Split(strings.TrimSuffix(resp.Output, "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(expected), resp.Output)
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+
+	failed := containerState(map[string]interface{}{"state": map[string]interface{}{"terminated": map[string]interface{}{"reason": "Error", "exitCode": float64(1)}}})
+	if failed != "\033[31mError\033[0m" {
+		t.Errorf("containerState(exit 1) = %q, want red Error", failed)
+	}
+}
+
+// TestHandleWorkloadPorts tests collecting distinct ports across the pods a deployment selects
+func TestHandleWorkloadPorts(t *testing.T) {
+	s := &Server{
+		config: config.DefaultConfig(),
+		store:  store.NewStore(),
+	}
+
+	deployGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	s.store.Add("test-context", deployGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		},
+	}})
+
+	pod := func(name, app string, ports ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": "default", "labels": map[string]interface{}{"app": app}},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "app", "ports": ports}},
+			},
+		}}
+	}
+	port := func(n int64, name string) interface{} {
+		return map[string]interface{}{"containerPort": n, "name": name}
+	}
+	s.store.Add("test-context", podGVR, pod("web-1", "web", port(8080, "http")))
+	// A newer pod adds a metrics port; 8080 must not be listed twice
+	s.store.Add("test-context", podGVR, pod("web-2", "web", port(9090, "metrics"), port(8080, "http")))
+	s.store.Add("test-context", podGVR, pod("db-1", "db", port(5432, "pg")))
+	s.store.SetWatching("test-context", deployGVR, true)
+	s.store.SetWatching("test-context", podGVR, true)
+
+	resp := s.handlePorts(context.Background(), &Request{Context: "test-context", Namespace: "default", ResourceType: "deploy", PodName: "web"})
+	if !resp.Success {
+		t.Fatalf("handlePorts failed: %s", resp.Error)
+	}
+	expected := "8080\tTCP\tapp\thttp\n9090\tTCP\tapp\tmetrics\n"
+	if resp.Output != expected {
+		t.Errorf("Output = %q, want %q", resp.Output, expected)
+	}
+
+	resp = s.handlePorts(context.Background(), &Request{Context: "test-context", Namespace: "default", ResourceType: "deployments", PodName: "missing"})
+	if resp.Success {
+		t.Error("handlePorts should fail for a deployment not in the cache")
+	}
+}
+
+// TestHandleWorkloadPorts_Unwatched tests that asking for a workload's ports watches the
+// workload type and its pods
+func TestHandleWorkloadPorts_Unwatched(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+
+	resp := s.handlePorts(context.Background(), &Request{Context: "prod", Namespace: "default", ResourceType: "rs", PodName: "web"})
+	if resp.Success {
+		t.Error("handlePorts should fail for a replicaset not in the cache")
+	}
+	for _, gvr := range []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "replicasets"},
+		{Version: "v1", Resource: "pods"},
+	} {
+		if !s.watchManager.IsWatching("prod", gvr) {
+			t.Errorf("%s should be watched", gvr.Resource)
+		}
+	}
+}
+
 func TestHandleServicePorts_NotFound(t *testing.T) {
 	cfg := config.DefaultConfig()
 	s := &Server{
@@ -215,6 +421,34 @@ func TestRecentResources(t *testing.T) {
 	}
 }
 
+// TestRecentResources_DefaultNamespace tests that an empty namespace means the context's default
+func TestRecentResources_DefaultNamespace(t *testing.T) {
+	recent := NewRecentResources(10)
+	recent.defaultNamespace = func(context string) string {
+		if context == "dev" {
+			return "team-a"
+		}
+		return "default"
+	}
+
+	recent.Add("prod", "", "pods", "pod-a")
+	recent.Add("prod", "default", "pods", "pod-b")
+	recent.Add("dev", "", "pods", "pod-c")
+
+	if got := recent.Get("prod", "default", "pods"); len(got) != 2 || got[0] != "pod-b" || got[1] != "pod-a" {
+		t.Errorf("Get(prod, default) = %v, want [pod-b pod-a]", got)
+	}
+	if got := recent.Get("prod", "", "pods"); len(got) != 2 {
+		t.Errorf("Get(prod, \"\") = %v, want the same as the default namespace", got)
+	}
+	if got := recent.Get("dev", "team-a", "pods"); len(got) != 1 || got[0] != "pod-c" {
+		t.Errorf("Get(dev, team-a) = %v, want [pod-c]", got)
+	}
+	if got := recent.Get("dev", "default", "pods"); len(got) != 0 {
+		t.Errorf("Get(dev, default) = %v, want none", got)
+	}
+}
+
 // TestCRDAutoDetection tests auto-detection of Custom Resource Definitions
 func TestCRDAutoDetection(t *testing.T) {
 	// This test documents expected behavior for CRD detection
@@ -300,15 +534,1463 @@ func TestRolloutPreview(t *testing.T) {
 	}
 }
 
-func containsString(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstr(s, substr))
+// TestHandleConnection_Token tests shared-secret authentication on TCP connections
+func TestHandleConnection_Token(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		wantError string
+	}{
+		{"valid token", "s3cret", "unknown request type"},
+		{"invalid token", "wrong", "unauthorized"},
+		{"empty token", "", "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{
+				config:    config.DefaultConfig(),
+				store:     store.NewStore(),
+				logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+				authToken: "s3cret",
+			}
+
+			serverConn, clientConn := net.Pipe()
+			defer func() { _ = clientConn.Close() }()
+			go s.handleConnection(context.Background(), serverConn)
+
+			reqData, _ := EncodeRequest(&Request{Type: "bogus"})
+			go func() {
+				_, _ = clientConn.Write(append(EncodeToken(tt.token), reqData...))
+			}()
+
+			respData, err := bufio.NewReader(clientConn).ReadBytes('\n')
+			if err != nil {
+				t.Fatalf("failed to read response: %v", err)
+			}
+			resp, err := DecodeResponse(respData)
+			if err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error != tt.wantError {
+				t.Errorf("Error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
 }
 
-func containsSubstr(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+// TestHandleAPIResources tests listing discovered resource types from the discovery cache
+func TestHandleAPIResources(t *testing.T) {
+	s := &Server{
+		config:                 config.DefaultConfig(),
+		store:                  store.NewStore(),
+		discoveryCache:         make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:   make(map[string]time.Time),
+		discoveryCacheComplete: make(map[string]bool),
+	}
+	s.discoveryCache["test-context"] = []k8s.ResourceInfo{
+		{GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}},
+		{GVR: schema.GroupVersionResource{Version: "v1", Resource: "nodes"}, Kind: "Node", ShortNames: []string{"no"}},
+		{GVR: schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}, Kind: "Application", Namespaced: true},
+	}
+	s.discoveryCacheComplete["test-context"] = true
+
+	resp := s.handleAPIResources(&Request{Context: "test-context"})
+	if !resp.Success {
+		t.Fatalf("handleAPIResources failed: %s", resp.Error)
+	}
+
+	want := []string{"applications", "deployments", "nodes"}
+	if len(resp.APIResources) != len(want) {
+		t.Fatalf("Got %d resources, want %d", len(resp.APIResources), len(want))
+	}
+	for i, name := range want {
+		if resp.APIResources[i].Name != name {
+			t.Errorf("APIResources[%d].Name = %s, want %s", i, resp.APIResources[i].Name, name)
 		}
 	}
-	return false
+	if resp.APIResources[0].Group != "argoproj.io" || !resp.APIResources[0].Namespaced {
+		t.Errorf("unexpected applications entry: %+v", resp.APIResources[0])
+	}
+}
+
+// TestHandleResourceTypes tests resource type completion from the discovery cache
+func TestHandleResourceTypes(t *testing.T) {
+	s := &Server{
+		config:                 config.DefaultConfig(),
+		store:                  store.NewStore(),
+		discoveryCache:         make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:   make(map[string]time.Time),
+		discoveryCacheComplete: make(map[string]bool),
+	}
+	s.discoveryCache["test-context"] = []k8s.ResourceInfo{
+		{GVR: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, ShortNames: []string{"po"}},
+		{GVR: schema.GroupVersionResource{Version: "v1", Resource: "events"}, ShortNames: []string{"ev"}},
+		{GVR: schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"}, ShortNames: []string{"ev"}},
+		{GVR: schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}},
+	}
+	s.discoveryCacheComplete["test-context"] = true
+
+	resp := s.handleResourceTypes(&Request{Context: "test-context"})
+	if !resp.Success {
+		t.Fatalf("handleResourceTypes failed: %s", resp.Error)
+	}
+
+	want := "applications\nevents\tev\npods\tpo\n"
+	if resp.Output != want {
+		t.Errorf("Output = %q, want %q", resp.Output, want)
+	}
+}
+
+// TestNamespaceWarning tests the warning for namespaces passed with cluster-scoped resources
+func TestNamespaceWarning(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		namespace    string
+		namespaced   bool
+		wantWarning  bool
+	}{
+		{"cluster-scoped with namespace", "nodes", "kube-system", false, true},
+		{"cluster-scoped without namespace", "nodes", "", false, false},
+		{"namespaced with namespace", "pods", "kube-system", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := namespaceWarning(tt.resourceType, tt.namespace, tt.namespaced)
+			if (got != "") != tt.wantWarning {
+				t.Errorf("namespaceWarning() = %q, wantWarning %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
+
+// TestResolveGVR_DiscoveredNamespaced tests that cached discovery data overrides the static scope list
+func TestResolveGVR_DiscoveredNamespaced(t *testing.T) {
+	s := &Server{
+		config:                 config.DefaultConfig(),
+		store:                  store.NewStore(),
+		discoveryCache:         make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:   make(map[string]time.Time),
+		discoveryCacheComplete: make(map[string]bool),
+	}
+
+	// Without discovery data the static list is used
+	_, namespaced, err := s.resolveGVR("test-context", "ingresses")
+	if err != nil {
+		t.Fatalf("resolveGVR failed: %v", err)
+	}
+	if !namespaced {
+		t.Error("ingresses should default to namespaced")
+	}
+
+	s.discoveryCache["test-context"] = []k8s.ResourceInfo{
+		{GVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}, Namespaced: false},
+	}
+
+	_, namespaced, err = s.resolveGVR("test-context", "ingresses")
+	if err != nil {
+		t.Fatalf("resolveGVR failed: %v", err)
+	}
+	if namespaced {
+		t.Error("discovery data should take precedence over the static list")
+	}
+}
+
+// TestResolveGVR_NamespacedOverride tests that a configured scope wins over discovery in both branches
+func TestResolveGVR_NamespacedOverride(t *testing.T) {
+	cfg := config.DefaultConfig()
+	clusterScoped, namespaced := false, true
+	cfg.Resources["ingresses"] = config.ResourceConfig{Namespaced: &clusterScoped}
+	cfg.Resources["widgets.example.com"] = config.ResourceConfig{Namespaced: &namespaced}
+
+	s := &Server{
+		config:                 cfg,
+		store:                  store.NewStore(),
+		discoveryCache:         make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:   make(map[string]time.Time),
+		discoveryCacheComplete: make(map[string]bool),
+	}
+	s.discoveryCache["test-context"] = []k8s.ResourceInfo{
+		{GVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}, Namespaced: true},
+		{GVR: schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}, Kind: "Widget", Namespaced: false},
+	}
+	s.discoveryCacheAccess["test-context"] = time.Now()
+	s.discoveryCacheComplete["test-context"] = true
+
+	tests := []struct {
+		resourceType   string
+		wantNamespaced bool
+	}{
+		{"ingresses", false}, // preferred GVR branch
+		{"widgets", true},    // discovery branch, configured as resource.group
+		{"pods", true},       // no override
+	}
+	for _, tt := range tests {
+		_, got, err := s.resolveGVR("test-context", tt.resourceType)
+		if err != nil {
+			t.Fatalf("resolveGVR(%s) failed: %v", tt.resourceType, err)
+		}
+		if got != tt.wantNamespaced {
+			t.Errorf("resolveGVR(%s) namespaced = %t, want %t", tt.resourceType, got, tt.wantNamespaced)
+		}
+	}
+
+	// A scope-only entry keeps the default columns
+	if cols := cfg.GetResourceConfig("ingresses").Columns; len(cols) == 0 || cols[0].Name != "NAME" {
+		t.Errorf("GetResourceConfig(ingresses) columns = %v, want the _default columns", cols)
+	}
+}
+
+// TestResolveGVR_ResourcePreference tests that configured preferences pick the group for ambiguous names
+func TestResolveGVR_ResourcePreference(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.ResourcePreference = map[string]string{"events": "events.events.k8s.io"}
+	var logs strings.Builder
+	s := &Server{
+		config:                 cfg,
+		store:                  store.NewStore(),
+		logger:                 slog.New(slog.NewTextHandler(&logs, nil)),
+		discoveryCache:         make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:   make(map[string]time.Time),
+		discoveryCacheComplete: make(map[string]bool),
+	}
+	s.discoveryCache["test-context"] = []k8s.ResourceInfo{
+		{GVR: schema.GroupVersionResource{Version: "v1", Resource: "events"}, Namespaced: true},
+		{GVR: schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"}, Namespaced: true},
+		{GVR: schema.GroupVersionResource{Group: "a.example.com", Version: "v1", Resource: "widgets"}, Namespaced: true},
+		{GVR: schema.GroupVersionResource{Group: "b.example.com", Version: "v1", Resource: "widgets"}, Namespaced: true},
+	}
+
+	for range 2 {
+		gvr, _, err := s.resolveGVR("test-context", "events")
+		if err != nil {
+			t.Fatalf("resolveGVR failed: %v", err)
+		}
+		if gvr.Group != "events.k8s.io" {
+			t.Errorf("Group = %q, want events.k8s.io", gvr.Group)
+		}
+	}
+	if _, _, err := s.resolveGVR("test-context", "widgets"); err != nil {
+		t.Fatalf("resolveGVR failed: %v", err)
+	}
+
+	// Each ambiguity is logged once: at Info when the preference chose, else as a warning
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2: %q", len(lines), logs.String())
+	}
+	if !strings.Contains(lines[0], "level=INFO") || !strings.Contains(lines[0], `matches="events, events.events.k8s.io"`) {
+		t.Errorf("preference log = %q, want an Info line listing both events", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=WARN") || !strings.Contains(lines[1], "resource=widgets") {
+		t.Errorf("ambiguity log = %q, want a warning for widgets", lines[1])
+	}
+}
+
+// TestLimitResources tests capping completion results
+func TestLimitResources(t *testing.T) {
+	resources := []*store.Resource{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{0, 3},
+		{2, 2},
+		{5, 3},
+	}
+
+	for _, tt := range tests {
+		if got := limitResources(resources, tt.limit); len(got) != tt.want {
+			t.Errorf("limitResources(%d) returned %d items, want %d", tt.limit, len(got), tt.want)
+		}
+	}
+}
+
+// TestSortResources tests the stable name/namespace/creation order
+func TestSortResources(t *testing.T) {
+	now := time.Now()
+	resources := []*store.Resource{
+		{Name: "web", Namespace: "prod", CreationTimestamp: now},
+		{Name: "api", Namespace: "prod", CreationTimestamp: now},
+		{Name: "web", Namespace: "dev", CreationTimestamp: now},
+		{Name: "web", Namespace: "dev", CreationTimestamp: now.Add(-time.Hour)},
+	}
+
+	sortResources(resources)
+
+	want := []struct {
+		name, namespace string
+		created         time.Time
+	}{
+		{"api", "prod", now},
+		{"web", "dev", now.Add(-time.Hour)},
+		{"web", "dev", now},
+		{"web", "prod", now},
+	}
+	for i, w := range want {
+		r := resources[i]
+		if r.Name != w.name || r.Namespace != w.namespace || !r.CreationTimestamp.Equal(w.created) {
+			t.Errorf("resources[%d] = %s/%s@%v, want %s/%s@%v", i, r.Namespace, r.Name, r.CreationTimestamp, w.namespace, w.name, w.created)
+		}
+	}
+}
+
+// TestHandleStopContext tests that stopping a context clears its cached data only
+func TestHandleStopContext(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st := store.NewStore()
+	s := &Server{
+		config:                    config.DefaultConfig(),
+		store:                     st,
+		logger:                    logger,
+		watchManager:              k8s.NewWatchManager(nil, st, logger),
+		initializedContexts:       map[string]bool{"old": true, "keep": true},
+		initializedContextsAccess: map[string]time.Time{"old": time.Now(), "keep": time.Now()},
+	}
+
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	for _, contextName := range []string{"old", "keep"} {
+		st.Add(contextName, podGVR, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+			},
+		})
+	}
+
+	resp := s.handleStopContext(&Request{Context: "old"})
+	if !resp.Success {
+		t.Fatalf("handleStopContext failed: %s", resp.Error)
+	}
+
+	if got := st.List("old", podGVR, ""); len(got) != 0 {
+		t.Errorf("store still has %d resources for stopped context", len(got))
+	}
+	if got := st.List("keep", podGVR, ""); len(got) != 1 {
+		t.Errorf("store has %d resources for other context, want 1", len(got))
+	}
+	if s.initializedContexts["old"] {
+		t.Error("stopped context should no longer be marked initialized")
+	}
+}
+
+// TestHandleStatus_DefaultsSynced tests the combined default set synced signal
+func TestHandleStatus_DefaultsSynced(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st := store.NewStore()
+	s := &Server{
+		config:              config.DefaultConfig(),
+		store:               st,
+		logger:              logger,
+		watchManager:        k8s.NewWatchManager(nil, st, logger),
+		initializedContexts: map[string]bool{"ready": true, "partial": true},
+		startTime:           time.Now(),
+	}
+
+	for _, res := range defaultResources {
+		st.SetWatching("ready", res.gvr, true)
+	}
+	st.SetWatching("partial", defaultResources[0].gvr, true)
+
+	resp := s.handleStatus()
+	if !resp.Success {
+		t.Fatalf("handleStatus failed: %s", resp.Error)
+	}
+	if !resp.Status.DefaultsSynced["ready"] {
+		t.Error("context with all default resources synced should report synced")
+	}
+	if synced, ok := resp.Status.DefaultsSynced["partial"]; !ok || synced {
+		t.Errorf("partially synced context: got synced=%t present=%t, want false true", synced, ok)
+	}
+
+	if s.waitForDefaults("partial", 20*time.Millisecond) {
+		t.Error("waitForDefaults should time out for a partially synced context")
+	}
+	if !s.waitForDefaults("ready", time.Second) {
+		t.Error("waitForDefaults should succeed for a synced context")
+	}
+}
+
+// TestClusterInfo_Probe tests that reachability comes from a live probe rather than
+// the cached version, which is still reported for an unreachable cluster
+func TestClusterInfo_Probe(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+	s.discoveryCacheVersion = map[string]string{"prod": "v1.30.2"}
+	if err := s.watchManager.StartWatching(context.Background(), "prod", schema.GroupVersionResource{Version: "v1", Resource: "pods"}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	info, ok := s.clusterInfo()["prod"]
+	if !ok {
+		t.Fatal("active context missing from clusterInfo")
+	}
+	if info.Reachable || info.Error == "" {
+		t.Errorf("clusterInfo = %+v, want unreachable with an error", info)
+	}
+	if info.ServerVersion != "v1.30.2" {
+		t.Errorf("ServerVersion = %q, want the last known v1.30.2", info.ServerVersion)
+	}
+	if elapsed := time.Since(start); elapsed > clusterProbeTimeout+time.Second {
+		t.Errorf("probe took %s, want at most about %s", elapsed, clusterProbeTimeout)
+	}
+}
+
+// TestHandleDebugDump tests dumping a cached object and resolving its namespace
+func TestHandleDebugDump(t *testing.T) {
+	st := store.NewStore()
+	s := &Server{
+		config:                 config.DefaultConfig(),
+		store:                  st,
+		discoveryCache:         make(map[string][]k8s.ResourceInfo),
+		discoveryCacheAccess:   make(map[string]time.Time),
+		discoveryCacheComplete: make(map[string]bool),
+	}
+
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	for _, ns := range []string{"default", "prod"} {
+		st.Add("test-context", podGVR, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "web", "namespace": ns},
+			},
+		})
+	}
+	st.Add("test-context", podGVR, &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "api", "namespace": "prod"},
+			"spec":     map[string]interface{}{"nodeName": "worker-1"},
+		},
+	})
+
+	resp := s.handleDebugDump(&Request{Context: "test-context", ResourceType: "po", ResourceName: "api"})
+	if !resp.Success {
+		t.Fatalf("handleDebugDump failed: %s", resp.Error)
+	}
+	if !containsString(resp.Output, `"nodeName": "worker-1"`) {
+		t.Errorf("Output missing cached field: %s", resp.Output)
+	}
+
+	if resp := s.handleDebugDump(&Request{Context: "test-context", ResourceType: "pods", ResourceName: "web"}); resp.Success {
+		t.Error("expected an error for a name in several namespaces")
+	}
+	if resp := s.handleDebugDump(&Request{Context: "test-context", ResourceType: "pods", Namespace: "prod", ResourceName: "web"}); !resp.Success {
+		t.Errorf("handleDebugDump with namespace failed: %s", resp.Error)
+	}
+	if resp := s.handleDebugDump(&Request{Context: "test-context", ResourceType: "pods", ResourceName: "missing"}); resp.Success {
+		t.Error("expected an error for an uncached object")
+	}
+}
+
+// TestHandleSetLogLevel tests changing the log level at runtime
+func TestHandleSetLogLevel(t *testing.T) {
+	levelVar := new(slog.LevelVar)
+	s := &Server{
+		logger:   slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: levelVar})),
+		logLevel: levelVar,
+	}
+
+	resp := s.handleSetLogLevel(&Request{LogLevel: "debug"})
+	if !resp.Success {
+		t.Fatalf("handleSetLogLevel failed: %s", resp.Error)
+	}
+	if levelVar.Level() != slog.LevelDebug {
+		t.Errorf("level = %v, want debug", levelVar.Level())
+	}
+	if !s.logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("logger should emit debug records after the change")
+	}
+
+	if resp := s.handleSetLogLevel(&Request{LogLevel: "verbose"}); resp.Success {
+		t.Error("expected an error for an unknown level")
+	}
+	if levelVar.Level() != slog.LevelDebug {
+		t.Error("an invalid level should leave the current level unchanged")
+	}
+
+	fixed := &Server{logger: s.logger}
+	if resp := fixed.handleSetLogLevel(&Request{LogLevel: "info"}); resp.Success {
+		t.Error("expected an error without a level var")
+	}
+}
+
+func containsString(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstr(s, substr))
+}
+
+func containsSubstr(s, substr string) bool {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+// TestFormatLabels tests label collection with occurrence counts
+func TestFormatLabels(t *testing.T) {
+	withLabels := func(labels map[string]interface{}) *store.Resource {
+		return &store.Resource{Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": labels},
+		}}}
+	}
+	resources := []*store.Resource{
+		withLabels(map[string]interface{}{"app": "web", "tier": "frontend"}),
+		withLabels(map[string]interface{}{"app": "web"}),
+		withLabels(map[string]interface{}{"app": "api"}),
+		{Object: nil},
+	}
+	labelsMap := collectLabels(resources)
+
+	tests := []struct {
+		name            string
+		counts, byCount bool
+		want            string
+	}{
+		{"plain", false, false, "app=api\napp=web\ntier=frontend\n"},
+		{"counts", true, false, "app=api\t1\napp=web\t2\ntier=frontend\t1\n"},
+		{"sorted by count", true, true, "app=web\t2\napp=api\t1\ntier=frontend\t1\n"},
+	}
+
+	for _, tt := range tests {
+		if got := formatLabels(labelsMap, tt.counts, tt.byCount); got != tt.want {
+			t.Errorf("%s: formatLabels() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestFormatCompletionMulti tests type-prefixed output with pooled buffers
+func TestFormatCompletionMulti(t *testing.T) {
+	s, resources := benchmarkServer(2)
+	sets := map[string][]*store.Resource{"pods": resources, "services": nil, "deployments": resources[:1]}
+
+	// Run twice so the second call reuses pooled buffers
+	for i := 0; i < 2; i++ {
+		got := s.formatCompletionMulti(s.formatterFor("test"), []string{"pods", "services", "deployments"}, sets, nil, false)
+		lines := strings.Split(got, "\n")
+		if len(lines) != 3 {
+			t.Fatalf("got %d lines, want 3: %q", len(lines), got)
+		}
+		for j, prefix := range []string{"pods/web-0 ", "pods/web-1 ", "deployments/web-0 "} {
+			if !strings.HasPrefix(lines[j], prefix) {
+				t.Errorf("line %d = %q, want prefix %q", j, lines[j], prefix)
+			}
+		}
+	}
+
+	want := "pods/web-0\npods/web-1\ndeployments/web-0"
+	if got := s.formatCompletionMulti(s.formatterFor("test"), []string{"pods", "services", "deployments"}, sets, nil, true); got != want {
+		t.Errorf("names only: got %q, want %q", got, want)
+	}
+	if got := s.formatNames(resources); got != "web-0\nweb-1" {
+		t.Errorf("formatNames() = %q, want %q", got, "web-0\nweb-1")
+	}
+}
+
+// TestFormatCompletion_ServiceEndpoints tests that service rows show the endpoints of their own context
+func TestFormatCompletion_ServiceEndpoints(t *testing.T) {
+	cfg := config.DefaultConfig()
+	st := store.NewStore()
+	s := &Server{config: cfg, store: st, formatter: fzf.NewFormatter(cfg)}
+
+	svcGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}
+	svc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec":     map[string]interface{}{"type": "ClusterIP", "clusterIP": "10.0.0.1"},
+	}}
+	st.Add("prod", svcGVR, svc)
+	st.Add("dev", svcGVR, svc.DeepCopy())
+	st.Add("prod", fzf.EndpointSlicesGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "web-abcde",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"kubernetes.io/service-name": "web"},
+		},
+		"endpoints": []interface{}{
+			map[string]interface{}{"addresses": []interface{}{"10.1.0.1"}},
+			map[string]interface{}{"addresses": []interface{}{"10.1.0.2"}},
+		},
+	}})
+
+	for _, tt := range []struct {
+		context  string
+		expected string
+	}{
+		{"prod", "2"},
+		{"dev", "<none>"},
+	} {
+		output := s.formatCompletion(s.formatterFor(tt.context), st.List(tt.context, svcGVR, ""), "services")
+		fields := strings.Fields(output)
+		if len(fields) == 0 || fields[len(fields)-1] != tt.expected {
+			t.Errorf("%s: got %q, want ENDPOINTS %q", tt.context, output, tt.expected)
+		}
+	}
+}
+
+// TestDedupeByName tests collapsing same-named resources across namespaces
+func TestDedupeByName(t *testing.T) {
+	s, _ := benchmarkServer(0)
+	var resources []*store.Resource
+	for _, r := range [][2]string{{"api", "dev"}, {"api", "prod"}, {"api", "staging"}, {"web", "dev"}} {
+		resources = append(resources, &store.Resource{
+			Name:      r[0],
+			Namespace: r[1],
+			Object: &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": r[0], "namespace": r[1]},
+			}},
+		})
+	}
+
+	kept, counts := dedupeByName(resources)
+	if len(kept) != 2 || kept[0].Namespace != "dev" || kept[1].Name != "web" {
+		t.Fatalf("dedupeByName() kept %d resources, want api/dev and web/dev", len(kept))
+	}
+	if len(counts) != 2 || counts[0] != 3 || counts[1] != 1 {
+		t.Errorf("dedupeByName() counts = %v, want [3 1]", counts)
+	}
+
+	lines := strings.Split(s.formatCompletionCounts(s.formatterFor("test"), kept, "pods", counts), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[0], "api ") || !strings.HasSuffix(lines[0], "\t(3x)") {
+		t.Errorf("line 0 = %q, want api row with (3x) count", lines[0])
+	}
+	if strings.Contains(lines[1], "(") {
+		t.Errorf("line 1 = %q, want no count for a unique name", lines[1])
+	}
+
+	multi := s.formatCompletionMulti(s.formatterFor("test"), []string{"pods"}, map[string][]*store.Resource{"pods": kept}, map[string][]int{"pods": counts}, false)
+	if first, _, _ := strings.Cut(multi, "\n"); !strings.HasPrefix(first, "pods/api ") || !strings.HasSuffix(first, "\t(3x)") {
+		t.Errorf("multi line 0 = %q, want pods/api row with (3x) count", first)
+	}
+}
+
+// TestStats tests request counting and resetting the counters
+func TestStats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st := store.NewStore()
+	s := &Server{
+		config:       config.DefaultConfig(),
+		store:        st,
+		logger:       logger,
+		watchManager: k8s.NewWatchManager(nil, st, logger),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.stats.CountRequest(RequestTypeComplete)
+		}()
+	}
+	wg.Wait()
+	s.stats.CountRequest(RequestTypeStatus)
+	s.stats.CountRequest("bogus")
+	s.stats.CountRequest("")
+
+	requests := s.stats.Requests()
+	if requests["complete"] != 10 || requests["status"] != 1 || requests["other"] != 2 || len(requests) != 3 {
+		t.Errorf("Requests() = %v, want complete=10 status=1 other=2", requests)
+	}
+	if !s.stats.ResetAt().IsZero() {
+		t.Error("ResetAt() should be zero before the first reset")
+	}
+
+	if resp := s.handleResetStats(); !resp.Success {
+		t.Fatalf("handleResetStats failed: %s", resp.Error)
+	}
+	for requestType, count := range s.stats.Requests() {
+		if count != 0 {
+			t.Errorf("after reset %s = %d, want 0", requestType, count)
+		}
+	}
+	if s.stats.ResetAt().IsZero() {
+		t.Error("ResetAt() should be set after a reset")
+	}
+	for event, count := range s.watchManager.EventCounts() {
+		if count != 0 {
+			t.Errorf("after reset %s events = %d, want 0", event, count)
+		}
+	}
+}
+
+// TestStats_Latency tests latency percentiles from the bucketed histogram
+func TestStats_Latency(t *testing.T) {
+	var st Stats
+
+	// 90 fast requests and 10 slow ones: p50 is fast, p95 lands in the slow bucket
+	for i := 0; i < 90; i++ {
+		st.ObserveLatency(RequestTypeComplete, 800*time.Microsecond)
+	}
+	for i := 0; i < 10; i++ {
+		st.ObserveLatency(RequestTypeComplete, 40*time.Millisecond)
+	}
+	st.ObserveLatency(RequestTypeStatus, time.Minute)
+	// Exactly on a bound belongs to that bucket
+	st.ObserveLatency(RequestTypePorts, time.Millisecond)
+
+	latencies := st.Latencies()
+	want := map[string]LatencyInfo{
+		"complete": {P50: "1ms", P95: "50ms"},
+		"status":   {P50: ">5s", P95: ">5s"},
+		"ports":    {P50: "1ms", P95: "1ms"},
+	}
+	for requestType, expected := range want {
+		if got := latencies[requestType]; got != expected {
+			t.Errorf("Latencies()[%s] = %+v, want %+v", requestType, got, expected)
+		}
+	}
+
+	st.Reset()
+	if latencies := st.Latencies(); len(latencies) != 0 {
+		t.Errorf("Latencies() after reset = %v, want none", latencies)
+	}
+}
+
+// TestStats_Cache tests cache hit/miss counting and reset
+func TestStats_Cache(t *testing.T) {
+	var st Stats
+
+	st.CountDiscovery(true)
+	st.CountDiscovery(true)
+	st.CountDiscovery(false)
+	st.CountResult(false)
+
+	want := CacheInfo{DiscoveryHits: 2, DiscoveryMisses: 1, ResultMisses: 1}
+	if got := *st.Cache(); got != want {
+		t.Errorf("Cache() = %+v, want %+v", got, want)
+	}
+
+	st.Reset()
+	if got := *st.Cache(); got != (CacheInfo{}) {
+		t.Errorf("Cache() after reset = %+v, want zero", got)
+	}
+}
+
+// TestStats_Runtime tests that heap samples are reused within memStatsInterval
+func TestStats_Runtime(t *testing.T) {
+	var st Stats
+
+	first := st.Runtime()
+	if first.Goroutines < 1 || first.HeapAlloc == 0 {
+		t.Fatalf("Runtime() = %+v, want goroutines and heap size", first)
+	}
+	sampled := st.memSampled
+
+	// Allocate so a fresh sample would differ
+	garbage := make([][]byte, 0, 64)
+	for i := 0; i < 64; i++ {
+		garbage = append(garbage, make([]byte, 64<<10))
+	}
+	if second := st.Runtime(); second.HeapAlloc != first.HeapAlloc || st.memSampled != sampled {
+		t.Errorf("Runtime() resampled the heap within memStatsInterval")
+	}
+	_ = garbage
+}
+
+// TestDrainConnections tests waiting for in-flight connection handlers on shutdown
+func TestDrainConnections(t *testing.T) {
+	s := &Server{connSemaphore: make(chan struct{}, 3)}
+
+	// One handler in flight
+	s.connSemaphore <- struct{}{}
+	if s.drainConnections(20 * time.Millisecond) {
+		t.Fatal("drainConnections() should time out while a handler is running")
+	}
+
+	s = &Server{connSemaphore: make(chan struct{}, 3)}
+	s.connSemaphore <- struct{}{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-s.connSemaphore // handler finishes
+	}()
+	if !s.drainConnections(time.Second) {
+		t.Error("drainConnections() should succeed once the handler finishes")
+	}
+}
+
+// TestSyncTimeout_ReadyGate tests that only default resources wait for the ready gate
+func TestSyncTimeout_ReadyGate(t *testing.T) {
+	cfg := config.DefaultConfig()
+	s := &Server{config: cfg}
+
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	crd := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+	if got := s.syncTimeout(pods); got != time.Second {
+		t.Errorf("syncTimeout(pods) without gate = %v, want 1s", got)
+	}
+
+	cfg.Server.ReadyGate = true
+	cfg.Server.ReadyGateTimeout = 7 * time.Second
+	if got := s.syncTimeout(pods); got != 7*time.Second {
+		t.Errorf("syncTimeout(pods) with gate = %v, want 7s", got)
+	}
+	if got := s.syncTimeout(crd); got != time.Second {
+		t.Errorf("syncTimeout(widgets) with gate = %v, want 1s", got)
+	}
+}
+
+// TestGetResourceInfo_RecentFailure tests that a recent discovery failure is returned
+// without retrying, and retried once it expires
+func TestGetResourceInfo_RecentFailure(t *testing.T) {
+	s := &Server{
+		config:            config.DefaultConfig(),
+		discoveryCache:    make(map[string][]k8s.ResourceInfo),
+		discoveryFailures: map[string]discoveryFailure{"broken": {err: errors.New("connection refused"), at: time.Now()}},
+	}
+
+	// No client manager is set, so a retry would panic
+	_, err := s.getResourceInfo("broken", false)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("getResourceInfo error = %v, want cached connection refused", err)
+	}
+
+	s.discoveryFailures["broken"] = discoveryFailure{err: errors.New("connection refused"), at: time.Now().Add(-discoveryFailureTTL)}
+	if err := s.recentDiscoveryFailure("broken"); err != nil {
+		t.Errorf("expired failure should be retried, got %v", err)
+	}
+}
+
+// TestFormatRecords tests CSV and TSV output with a header row and unpadded values
+func TestFormatRecords(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Resources["widgets"] = config.ResourceConfig{Columns: []config.ColumnConfig{
+		{Name: "NAME", Field: ".metadata.name", Width: 30},
+		{Name: "NOTE", Field: ".metadata.annotations.note", Width: 30},
+	}}
+	s := &Server{config: cfg, store: store.NewStore(), formatter: fzf.NewFormatter(cfg)}
+
+	resources := []*store.Resource{{
+		Name: "w1",
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "w1", "annotations": map[string]interface{}{"note": "a, \"b\"\tc"}},
+		}},
+	}}
+
+	csvOut, err := s.formatRecords(s.formatterFor("test-context"), resources, "widgets", "csv")
+	if err != nil {
+		t.Fatalf("formatRecords(csv) error = %v", err)
+	}
+	if want := "NAME,NOTE\nw1,\"a, \"\"b\"\"\tc\"\n"; csvOut != want {
+		t.Errorf("csv = %q, want %q", csvOut, want)
+	}
+
+	tsvOut, err := s.formatRecords(s.formatterFor("test-context"), resources, "widgets", "tsv")
+	if err != nil {
+		t.Fatalf("formatRecords(tsv) error = %v", err)
+	}
+	if want := "NAME\tNOTE\nw1\ta, \"b\" c\n"; tsvOut != want {
+		t.Errorf("tsv = %q, want %q", tsvOut, want)
+	}
+
+	if _, err := s.formatRecords(s.formatterFor("test-context"), resources, "widgets", "xml"); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
+
+func TestListOnce_Watched(t *testing.T) {
+	s := &Server{store: store.NewStore()}
+
+	podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	for _, name := range []string{"web", "api"} {
+		s.store.Add("test-context", podGVR, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+			},
+		})
+	}
+	s.store.SetWatching("test-context", podGVR, true)
+
+	// A watched type is served from the cache; there is no watch manager to list with
+	resources, err := s.listOnce(context.Background(), "test-context", podGVR, "default", true)
+	if err != nil {
+		t.Fatalf("listOnce() error = %v", err)
+	}
+	if len(resources) != 2 || resources[0].Name != "api" || resources[1].Name != "web" {
+		t.Errorf("listOnce() = %v, want api, web", resources)
+	}
+}
+
+// TestListOnce_Paginated tests that a one-off list follows continue tokens to the last page
+func TestListOnce_Paginated(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RawQuery)
+		mu.Unlock()
+		name, continueToken := "web", "page2"
+		if r.URL.Query().Get("continue") == "page2" {
+			name, continueToken = "api", ""
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"continue":%q},"items":[{"kind":"Pod","apiVersion":"v1","metadata":{"name":%q,"namespace":"default"}}]}`, continueToken, name)
+	}))
+	defer api.Close()
+
+	setTestKubeconfig(t, api.URL)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	resources, err := s.listOnce(context.Background(), "prod", podGVR, "default", true)
+	if err != nil {
+		t.Fatalf("listOnce() error = %v", err)
+	}
+	if len(resources) != 2 || resources[0].Name != "api" || resources[1].Name != "web" {
+		t.Errorf("listOnce() = %v, want api, web", resources)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 || !strings.Contains(requests[0], "limit=500") || !strings.Contains(requests[1], "continue=page2") {
+		t.Errorf("requests = %v, want two pages of 500", requests)
+	}
+	if s.store.IsWatching("prod", podGVR) {
+		t.Error("listOnce() should not watch the type")
+	}
+}
+
+// setUnreachableKubeconfig points HOME at a temporary directory and KUBECONFIG at a
+// kubeconfig with a single "prod" context whose API server is unreachable
+func setUnreachableKubeconfig(t *testing.T) {
+	t.Helper()
+	setTestKubeconfig(t, "https://127.0.0.1:1")
+}
+
+// setTestKubeconfig points HOME at a temporary directory and KUBECONFIG at a kubeconfig
+// with a single "prod" context whose API server is at serverURL
+func setTestKubeconfig(t *testing.T, serverURL string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	data := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster: {server: %q}
+users:
+- name: user
+contexts:
+- name: prod
+  context: {cluster: local, user: user}
+current-context: prod
+`, serverURL)
+	if err := os.WriteFile(kubeconfig, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+}
+
+// newUnreachableServer returns a server for the kubeconfig of setUnreachableKubeconfig
+// (or setTestKubeconfig). Watches can be started but never sync, and retry only after
+// an hour, so tests fill the store themselves.
+func newUnreachableServer(t *testing.T) *Server {
+	t.Helper()
+	clientManager, err := k8s.NewClientManager(k8s.ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClientManager failed: %v", err)
+	}
+	logger := slog.New(slog.DiscardHandler)
+	st := store.NewStore()
+	watchManager := k8s.NewWatchManager(clientManager, st, logger)
+	watchManager.Backoff = k8s.WatchBackoff{Initial: time.Hour, Max: time.Hour}
+	return &Server{
+		config:                    config.DefaultConfig(),
+		clientManager:             clientManager,
+		watchManager:              watchManager,
+		store:                     st,
+		logger:                    logger,
+		initializedContexts:       map[string]bool{"prod": true},
+		initializedContextsAccess: make(map[string]time.Time),
+	}
+}
+
+// TestWatchesRoundTrip tests that watches saved at shutdown are restored on the next
+// start, including after the server context was cancelled
+func TestWatchesRoundTrip(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newUnreachableServer(t)
+	if err := s.watchManager.StartWatching(ctx, "prod", widgets, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.watchManager.StartWatching(ctx, "removed", widgets, true); err != nil {
+		t.Fatal(err)
+	}
+	// Shutdown cancels the context before saving
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if err := s.saveWatches(); err != nil {
+		t.Fatalf("saveWatches failed: %v", err)
+	}
+	s.watchManager.StopAll()
+
+	restored := newUnreachableServer(t)
+	defer restored.watchManager.StopAll()
+	if err := restored.restoreWatches(context.Background()); err != nil {
+		t.Fatalf("restoreWatches failed: %v", err)
+	}
+	if !restored.watchManager.IsWatching("prod", widgets) {
+		t.Error("saved watch was not restored")
+	}
+	if restored.watchManager.IsWatching("removed", widgets) {
+		t.Error("watch of a context missing from the kubeconfig should be skipped")
+	}
+}
+
+// TestHandleStream tests a stream over a connection: the response, the snapshot of the
+// cached resources, live changes, and the end of the stream on disconnect or when the
+// watch stops
+func TestHandleStream(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+
+	podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	pod := func(name, namespace string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+		}}
+	}
+	if err := s.watchManager.StartWatching(context.Background(), "prod", podGVR, true); err != nil {
+		t.Fatal(err)
+	}
+	s.store.Add("prod", podGVR, pod("web", "default"))
+	s.store.Add("prod", podGVR, pod("dns", "kube-system"))
+	s.store.SetWatching("prod", podGVR, true)
+
+	// stream sends a stream request and returns a function reading the next event
+	stream := func() (net.Conn, <-chan struct{}, func() StreamEvent) {
+		serverConn, clientConn := net.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.handleConnection(context.Background(), serverConn)
+		}()
+
+		reqData, _ := EncodeRequest(&Request{Type: RequestTypeStream, Context: "prod", Namespace: "default", ResourceType: "pods"})
+		if _, err := clientConn.Write(reqData); err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		_ = clientConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(clientConn)
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if resp, err := DecodeResponse(line); err != nil || !resp.Success {
+			t.Fatalf("response = %+v, %v, want success", resp, err)
+		}
+		return clientConn, done, func() StreamEvent {
+			t.Helper()
+			var event StreamEvent
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %v", err)
+			}
+			if err := json.Unmarshal(line, &event); err != nil {
+				t.Fatalf("failed to decode event %q: %v", line, err)
+			}
+			return event
+		}
+	}
+	wait := func(done <-chan struct{}) {
+		t.Helper()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream was not closed")
+		}
+	}
+
+	conn, done, next := stream()
+	if event := next(); event.Type != "added" || event.Name != "web" || event.Object == nil {
+		t.Errorf("snapshot event = %+v, want added web with its object", event)
+	}
+	// Changes in other namespaces are filtered out
+	s.store.Add("prod", podGVR, pod("coredns", "kube-system"))
+	s.store.Add("prod", podGVR, pod("api", "default"))
+	if event := next(); event.Type != "added" || event.Name != "api" {
+		t.Errorf("live event = %+v, want added api", event)
+	}
+	s.store.Delete("prod", podGVR, "default", "api")
+	if event := next(); event.Type != "deleted" || event.Name != "api" || event.Object != nil {
+		t.Errorf("live event = %+v, want deleted api without object", event)
+	}
+
+	// Disconnecting ends the stream and its subscription
+	_ = conn.Close()
+	wait(done)
+
+	// Stopping the watch ends the stream with an error event
+	conn, done, next = stream()
+	defer func() { _ = conn.Close() }()
+	next()
+	s.watchManager.StopWatching("prod", podGVR)
+	if event := next(); event.Type != "error" || !strings.Contains(event.Error, "watch stopped") {
+		t.Errorf("event = %+v, want a watch stopped error", event)
+	}
+	wait(done)
+}
+
+// TestHandleCompleteTable tests completing from server-rendered tables: pagination,
+// the limit, caching the column definitions, and reusing the output of a watched type
+func TestHandleCompleteTable(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RawQuery)
+		mu.Unlock()
+		if r.URL.Path != "/api/v1/namespaces/default/pods" || !strings.Contains(r.Header.Get("Accept"), "as=Table") {
+			http.Error(w, "unexpected request "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		row := func(name, status string) string {
+			return fmt.Sprintf(`{"cells":[%q,%q],"object":{"kind":"PartialObjectMetadata","apiVersion":"meta.k8s.io/v1","metadata":{"name":%q,"namespace":"default"}}}`, name, status, name)
+		}
+		rows, continueToken := row("web", "Running"), "page2"
+		if r.URL.Query().Get("continue") == "page2" {
+			rows, continueToken = row("api", "Pending"), ""
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"Table","apiVersion":"meta.k8s.io/v1","metadata":{"continue":%q},`+
+			`"columnDefinitions":[{"name":"Name","type":"string","format":"name"},{"name":"Status","type":"string"},{"name":"IP","type":"string","priority":1}],`+
+			`"rows":[%s]}`, continueToken, rows)
+	}))
+	defer api.Close()
+
+	setTestKubeconfig(t, api.URL)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+	s.tableColumns = make(map[tableColumnsKey][]metav1.TableColumnDefinition)
+	s.results = NewResultCache(time.Minute, 10)
+	s.formatter = fzf.NewFormatter(s.config)
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+	complete := func(limit int) string {
+		t.Helper()
+		resp := s.handleCompleteTable(context.Background(), "prod", "default", "pods", &Request{ServerColumns: true, Limit: limit})
+		if !resp.Success {
+			t.Fatalf("handleCompleteTable failed: %s", resp.Error)
+		}
+		return resp.Output
+	}
+	reset := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		got := requests
+		requests = nil
+		return got
+	}
+
+	// Every page is listed, and the rows are sorted by name
+	if got, want := complete(0), "api\tPending\nweb\tRunning"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if got := reset(); len(got) != 2 || !strings.Contains(got[0], "limit=500") || !strings.Contains(got[1], "continue=page2") {
+		t.Errorf("requests = %v, want two pages of 500", got)
+	}
+	if got := s.cachedTableColumns("prod", podGVR); !slices.Equal(got, []string{"Name", "Status"}) {
+		t.Errorf("cachedTableColumns = %v, want Name, Status", got)
+	}
+
+	// A limit is passed on as the page size and stops paging
+	if got, want := complete(1), "web\tRunning"; got != want {
+		t.Errorf("limited output = %q, want %q", got, want)
+	}
+	if got := reset(); len(got) != 1 || !strings.Contains(got[0], "limit=1") {
+		t.Errorf("requests = %v, want one page of 1", got)
+	}
+
+	// A watched type reuses the output until its resources change
+	s.store.SetWatching("prod", podGVR, true)
+	complete(0)
+	complete(0)
+	if got := reset(); len(got) != 2 {
+		t.Errorf("requests = %v, want one list of two pages", got)
+	}
+	s.store.Add("prod", podGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "db", "namespace": "default"},
+	}})
+	complete(0)
+	if got := reset(); len(got) != 2 {
+		t.Errorf("requests = %v, want a new list after a change", got)
+	}
+}
+
+// TestHandleSoftRefresh_KeepsClients tests that a soft refresh re-reads the kubeconfig
+// without dropping the cached clients
+func TestHandleSoftRefresh_KeepsClients(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+
+	client, err := s.clientManager.GetClient("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := s.handleSoftRefresh(context.Background(), ""); !resp.Success {
+		t.Fatalf("handleSoftRefresh failed: %s", resp.Error)
+	}
+	after, err := s.clientManager.GetClient("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != client {
+		t.Error("soft refresh should keep the cached client")
+	}
+}
+
+// TestHandleSoftRefresh_ListFailure tests that a soft refresh whose re-lists fail
+// reports the failure instead of success
+func TestHandleSoftRefresh_ListFailure(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	if err := s.watchManager.StartWatching(context.Background(), "prod", podGVR, true); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := s.handleSoftRefresh(context.Background(), "prod")
+	if resp.Success {
+		t.Fatal("soft refresh succeeded although the re-list failed")
+	}
+	if !strings.Contains(resp.Error, "prod: pods:") {
+		t.Errorf("error = %q, want the failed context and resource", resp.Error)
+	}
+}
+
+// TestHandleCompleteMulti tests completing a type list: type-prefixed lines in the order
+// of the list, repeated types listed once, the namespace column after the prefixed name
+// and the warning for a cluster-scoped type given a namespace
+func TestHandleCompleteMulti(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	defer s.watchManager.StopAll()
+	s.formatter = fzf.NewFormatter(s.config)
+
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	svcGVR := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	nodeGVR := schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	add := func(gvr schema.GroupVersionResource, namespace, name string) {
+		metadata := map[string]interface{}{"name": name}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		s.store.Add("prod", gvr, &unstructured.Unstructured{Object: map[string]interface{}{"metadata": metadata}})
+	}
+	add(podGVR, "default", "web")
+	add(podGVR, "kube-system", "dns")
+	add(svcGVR, "default", "api")
+	add(nodeGVR, "", "node-1")
+	for _, gvr := range []schema.GroupVersionResource{podGVR, svcGVR, nodeGVR} {
+		s.store.SetWatching("prod", gvr, true)
+	}
+
+	complete := func(namespace, resourceType string) *Response {
+		t.Helper()
+		resp := s.handleComplete(context.Background(), &Request{Context: "prod", Namespace: namespace, ResourceType: resourceType, NamesOnly: true})
+		if !resp.Success {
+			t.Fatalf("handleComplete(%s) failed: %s", resourceType, resp.Error)
+		}
+		return resp
+	}
+
+	resp := complete("", "svc,po,pods")
+	if want := "services/api\npods/dns\npods/web"; resp.Output != want {
+		t.Errorf("Output = %q, want %q", resp.Output, want)
+	}
+
+	resp = complete("default", "po,no")
+	if want := "pods/web\nnodes/node-1"; resp.Output != want {
+		t.Errorf("Output = %q, want %q", resp.Output, want)
+	}
+	if !strings.Contains(resp.Warning, "nodes is cluster-scoped") {
+		t.Errorf("Warning = %q, want the cluster-scoped warning for nodes", resp.Warning)
+	}
+
+	// With columns, each row keeps its namespace column after the prefixed name
+	resp = s.handleComplete(context.Background(), &Request{Context: "prod", ResourceType: "po,svc"})
+	if !resp.Success {
+		t.Fatalf("handleComplete failed: %s", resp.Error)
+	}
+	var rows []string
+	for _, line := range strings.Split(resp.Output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			t.Fatalf("row %q has no namespace column", line)
+		}
+		rows = append(rows, strings.TrimSpace(fields[0])+" "+strings.TrimSpace(fields[1]))
+	}
+	if want := []string{"pods/dns kube-system", "pods/web default", "services/api default"}; !slices.Equal(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+// TestWrapTLS tests that TCP listeners stay plain without a certificate and are closed
+// when the configured certificate can't be loaded
+func TestWrapTLS(t *testing.T) {
+	s := &Server{config: config.DefaultConfig(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	got, err := s.wrapTLS(listener)
+	if err != nil || got != listener {
+		t.Errorf("wrapTLS() without a certificate = %v, %v, want the listener unchanged", got, err)
+	}
+
+	dir := t.TempDir()
+	s.config.Server.TLS.CertFile = filepath.Join(dir, "tls.crt")
+	s.config.Server.TLS.KeyFile = filepath.Join(dir, "tls.key")
+	if _, err := s.wrapTLS(listener); err == nil || !strings.Contains(err.Error(), "failed to load TLS certificate") {
+		t.Errorf("wrapTLS() with a missing certificate error = %v", err)
+	}
+	if _, err := listener.Accept(); err == nil {
+		t.Error("listener should be closed after a failed wrap")
+	}
+}
+
+// TestWatchCurrentContext_Switch tests that switching the kubeconfig current context
+// (like kubectx does) initializes watches for the new context
+func TestWatchCurrentContext_Switch(t *testing.T) {
+	setUnreachableKubeconfig(t)
+	s := newUnreachableServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.watchCurrentContext(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	kubeconfig := os.Getenv("KUBECONFIG")
+	data := `apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster: {server: "https://127.0.0.1:1"}
+users:
+- name: user
+contexts:
+- name: prod
+  context: {cluster: local, user: user}
+- name: staging
+  context: {cluster: local, user: user}
+current-context: staging
+`
+	if err := os.WriteFile(kubeconfig, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(kubeconfig, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * contextPollInterval)
+	for time.Now().Before(deadline) {
+		s.initializedContextsMu.Lock()
+		initialized := s.initializedContexts["staging"]
+		s.initializedContextsMu.Unlock()
+		if initialized {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("watches were not initialized for the new current context")
+}
+
+// TestResultCache tests that cached output is only served for the same store version
+func TestResultCache(t *testing.T) {
+	c := NewResultCache(time.Minute, 2)
+	key := resultKey{context: "ctx", gvr: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, resourceType: "pods"}
+
+	if _, ok := c.Get(key, 1); ok {
+		t.Error("empty cache returned a hit")
+	}
+	c.Put(key, 1, "web")
+	if out, ok := c.Get(key, 1); !ok || out != "web" {
+		t.Errorf("Get() = %q, %v, want web, true", out, ok)
+	}
+	if _, ok := c.Get(key, 2); ok {
+		t.Error("changed version should miss")
+	}
+	if _, ok := c.Get(key, 1); ok {
+		t.Error("stale entry should have been dropped")
+	}
+
+	other := key
+	other.namespace = "prod"
+	c.Put(key, 1, "web")
+	c.Put(other, 1, "api")
+	third := key
+	third.limit = 5
+	c.Put(third, 1, "x")
+	if len(c.entries) > 2 {
+		t.Errorf("cache holds %d entries, want at most 2", len(c.entries))
+	}
+
+	expiring := NewResultCache(time.Nanosecond, 2)
+	expiring.Put(key, 1, "web")
+	time.Sleep(time.Millisecond)
+	if _, ok := expiring.Get(key, 1); ok {
+		t.Error("expired entry should miss")
+	}
+}
+
+// benchmarkServer returns a server with n cached pods for formatting benchmarks
+func benchmarkServer(n int) (*Server, []*store.Resource) {
+	cfg := config.DefaultConfig()
+	resources := make([]*store.Resource, n)
+	for i := range resources {
+		name := fmt.Sprintf("web-%d", i)
+		resources[i] = &store.Resource{
+			Name:      name,
+			Namespace: "default",
+			Object: &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name, "namespace": "default"},
+				"status":   map[string]interface{}{"phase": "Running"},
+			}},
+			CreationTimestamp: time.Now().Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return &Server{config: cfg, formatter: fzf.NewFormatter(cfg)}, resources
+}
+
+// BenchmarkFormatCompletion fires many concurrent completion formats, as rapid TAB presses do
+func BenchmarkFormatCompletion(b *testing.B) {
+	s, resources := benchmarkServer(200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.formatCompletion(s.formatterFor("test"), resources, "pods")
+		}
+	})
+}
+
+// BenchmarkFormatCompletionMulti formats a multi-type completion
+func BenchmarkFormatCompletionMulti(b *testing.B) {
+	s, resources := benchmarkServer(200)
+	sets := map[string][]*store.Resource{"pods": resources, "services": resources}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.formatCompletionMulti(s.formatterFor("test"), []string{"pods", "services"}, sets, nil, false)
+		}
+	})
 }
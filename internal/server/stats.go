@@ -0,0 +1,233 @@
+package server
+
+import (
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// memStatsInterval is the minimum time between runtime.ReadMemStats calls, which
+// briefly stop the world, so frequent status polling stays cheap
+const memStatsInterval = 5 * time.Second
+
+// latencyBuckets are the upper bounds of the request latency histogram buckets.
+// Latencies above the last bound fall into an extra overflow bucket.
+var latencyBuckets = [...]time.Duration{
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second,
+}
+
+// requestTypeOther collects the requests of unknown types, so clients sending arbitrary
+// types can't grow the counters without bound
+const requestTypeOther RequestType = "other"
+
+// knownRequestTypes are the request types counted under their own name
+var knownRequestTypes = map[RequestType]bool{
+	RequestTypeComplete:      true,
+	RequestTypeContainers:    true,
+	RequestTypePorts:         true,
+	RequestTypeLabels:        true,
+	RequestTypeFieldValues:   true,
+	RequestTypeStatus:        true,
+	RequestTypeRefresh:       true,
+	RequestTypeWatch:         true,
+	RequestTypeStopWatch:     true,
+	RequestTypeRecordRecent:  true,
+	RequestTypeGetRecent:     true,
+	RequestTypeAPIResources:  true,
+	RequestTypeResourceTypes: true,
+	RequestTypeWatchList:     true,
+	RequestTypeStopContext:   true,
+	RequestTypeDebugDump:     true,
+	RequestTypeSetLogLevel:   true,
+	RequestTypeContexts:      true,
+	RequestTypeResetStats:    true,
+	RequestTypeStream:        true,
+}
+
+// requestStats holds the counters of one request type
+type requestStats struct {
+	count   atomic.Int64
+	latency [len(latencyBuckets) + 1]atomic.Int64
+}
+
+// Stats holds the server's request counters. Counters are atomics so counting a
+// request doesn't take a write lock; the lock guards adding request types and resets.
+type Stats struct {
+	mu       sync.RWMutex
+	requests map[RequestType]*requestStats
+	resetAt  time.Time // zero until the first reset
+
+	// Discovery cache and completion result cache lookups
+	discoveryHits, discoveryMisses atomic.Int64
+	resultHits, resultMisses       atomic.Int64
+
+	// Last heap sample and when it was taken
+	memMu      sync.Mutex
+	memSampled time.Time
+	heapAlloc  uint64
+}
+
+// forType returns the counters of a request type, adding them on first use. Unknown
+// types share the counters of requestTypeOther.
+func (st *Stats) forType(requestType RequestType) *requestStats {
+	if !knownRequestTypes[requestType] {
+		requestType = requestTypeOther
+	}
+	st.mu.RLock()
+	rs, ok := st.requests[requestType]
+	st.mu.RUnlock()
+	if ok {
+		return rs
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.requests == nil {
+		st.requests = make(map[RequestType]*requestStats)
+	}
+	if rs, ok = st.requests[requestType]; !ok {
+		rs = new(requestStats)
+		st.requests[requestType] = rs
+	}
+	return rs
+}
+
+// CountRequest increments the counter of a request type
+func (st *Stats) CountRequest(requestType RequestType) {
+	st.forType(requestType).count.Add(1)
+}
+
+// ObserveLatency records how long a request of a type took to handle
+func (st *Stats) ObserveLatency(requestType RequestType, d time.Duration) {
+	i, _ := slices.BinarySearch(latencyBuckets[:], d)
+	st.forType(requestType).latency[i].Add(1)
+}
+
+// CountDiscovery counts a discovery cache lookup
+func (st *Stats) CountDiscovery(hit bool) {
+	if hit {
+		st.discoveryHits.Add(1)
+	} else {
+		st.discoveryMisses.Add(1)
+	}
+}
+
+// CountResult counts a completion result cache lookup
+func (st *Stats) CountResult(hit bool) {
+	if hit {
+		st.resultHits.Add(1)
+	} else {
+		st.resultMisses.Add(1)
+	}
+}
+
+// Cache returns the cache hit and miss counts
+func (st *Stats) Cache() *CacheInfo {
+	return &CacheInfo{
+		DiscoveryHits:   st.discoveryHits.Load(),
+		DiscoveryMisses: st.discoveryMisses.Load(),
+		ResultHits:      st.resultHits.Load(),
+		ResultMisses:    st.resultMisses.Load(),
+	}
+}
+
+// Requests returns the request counts per request type
+func (st *Stats) Requests() map[string]int64 {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	counts := make(map[string]int64, len(st.requests))
+	for requestType, rs := range st.requests {
+		counts[string(requestType)] = rs.count.Load()
+	}
+	return counts
+}
+
+// Latencies returns the approximate median and 95th percentile latency per request type,
+// as the upper bounds of the histogram buckets they fall into
+func (st *Stats) Latencies() map[string]LatencyInfo {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	latencies := make(map[string]LatencyInfo, len(st.requests))
+	for requestType, rs := range st.requests {
+		var buckets [len(latencyBuckets) + 1]int64
+		var total int64
+		for i := range rs.latency {
+			buckets[i] = rs.latency[i].Load()
+			total += buckets[i]
+		}
+		if total == 0 {
+			continue
+		}
+		latencies[string(requestType)] = LatencyInfo{
+			P50: latencyPercentile(buckets[:], total, 0.50),
+			P95: latencyPercentile(buckets[:], total, 0.95),
+		}
+	}
+	return latencies
+}
+
+// latencyPercentile returns the upper bound of the bucket holding the q-th quantile
+func latencyPercentile(buckets []int64, total int64, q float64) string {
+	rank := int64(math.Ceil(q * float64(total)))
+	var cumulative int64
+	for i, n := range buckets {
+		cumulative += n
+		if cumulative >= rank && i < len(latencyBuckets) {
+			return latencyBuckets[i].String()
+		}
+	}
+	return ">" + latencyBuckets[len(latencyBuckets)-1].String()
+}
+
+// Reset zeroes all counters and returns the time of the reset
+func (st *Stats) Reset() time.Time {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, rs := range st.requests {
+		rs.count.Store(0)
+		for i := range rs.latency {
+			rs.latency[i].Store(0)
+		}
+	}
+	st.discoveryHits.Store(0)
+	st.discoveryMisses.Store(0)
+	st.resultHits.Store(0)
+	st.resultMisses.Store(0)
+	st.resetAt = time.Now()
+	return st.resetAt
+}
+
+// Runtime returns the goroutine count and the heap size, which is sampled at most
+// once per memStatsInterval
+func (st *Stats) Runtime() *RuntimeInfo {
+	st.memMu.Lock()
+	defer st.memMu.Unlock()
+
+	if time.Since(st.memSampled) >= memStatsInterval {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		st.heapAlloc = m.HeapAlloc
+		st.memSampled = time.Now()
+	}
+	return &RuntimeInfo{
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  st.heapAlloc,
+	}
+}
+
+// ResetAt returns when the counters were last reset (zero if never)
+func (st *Stats) ResetAt() time.Time {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.resetAt
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"time"
+
+	"github.com/pslijkhuis/kfzf/internal/k8s"
+	"github.com/pslijkhuis/kfzf/internal/store"
+)
+
+// handleStream serves a stream request: it watches the requested resource type like a
+// completion would, answers with a Response, then writes an added event for every
+// cached resource followed by each change as it happens, until the client disconnects
+// or the server shuts down. This lets richer frontends (e.g. a TUI) use the daemon as
+// a cache without polling. Events are newline-delimited StreamEvents, like requests
+// and responses. A resource may be reported as added twice around the initial list,
+// so clients should treat added and modified alike.
+//
+// A stream holds one of the connection slots for as long as it is open. A client
+// that falls more than streamBuffer events behind is sent an error event and
+// disconnected; it should reconnect to start over from the current resources. So is
+// a client whose watch stops, e.g. when its context is refreshed or cleaned up.
+func (s *Server) handleStream(ctx context.Context, conn net.Conn, reader *bufio.Reader, req *Request) {
+	contextName := req.Context
+	if contextName == "" {
+		contextName = s.clientManager.GetCurrentContext()
+	}
+	resourceType := k8s.NormalizeResourceName(req.ResourceType)
+
+	gvr, namespaced, err := s.prepareCompletion(ctx, contextName, resourceType)
+	if err != nil {
+		s.sendError(conn, err.Error())
+		return
+	}
+	namespace := req.Namespace
+	if !namespaced {
+		namespace = ""
+	}
+
+	// Subscribe before listing, so no change is missed in between
+	events, unsubscribe := s.store.Subscribe(contextName, *gvr, streamBuffer)
+	defer unsubscribe()
+
+	// The client ends the stream by closing the connection; reading notices
+	_ = conn.SetReadDeadline(time.Time{})
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+
+	write := func(v any) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			s.logger.Error("failed to encode stream event", "error", err)
+			return false
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		_, err = conn.Write(append(data, '\n'))
+		return err == nil
+	}
+
+	if !write(&Response{Success: true, Warning: namespaceWarning(resourceType, req.Namespace, namespaced)}) {
+		return
+	}
+	for _, res := range s.listSorted(contextName, *gvr, namespace, namespaced) {
+		if !write(newStreamEvent(store.EventAdded, res)) {
+			return
+		}
+	}
+
+	// An open stream keeps its context from being cleaned up as unused
+	touch := time.NewTicker(streamTouchInterval)
+	defer touch.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				write(&StreamEvent{Type: "error", Error: "stream fell behind, reconnect to start over"})
+				return
+			}
+			if event.Type == store.EventStopped {
+				write(&StreamEvent{Type: "error", Error: "watch stopped, reconnect to start over"})
+				return
+			}
+			if namespace != "" && event.Resource.Namespace != namespace {
+				continue
+			}
+			if !write(newStreamEvent(event.Type, event.Resource)) {
+				return
+			}
+		case <-touch.C:
+			s.touchContext(contextName)
+		case <-disconnected:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newStreamEvent converts a store event to the event sent to stream clients
+func newStreamEvent(eventType store.EventType, res *store.Resource) *StreamEvent {
+	event := &StreamEvent{Type: string(eventType), Namespace: res.Namespace, Name: res.Name}
+	if eventType != store.EventDeleted && res.Object != nil {
+		event.Object = res.Object.Object
+	}
+	return event
+}
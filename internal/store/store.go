@@ -1,6 +1,8 @@
 package store
 
 import (
+	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,6 +17,23 @@ type Resource struct {
 	GVR               schema.GroupVersionResource
 	Object            *unstructured.Unstructured
 	CreationTimestamp time.Time
+	// Deleted is set on resources returned by Get during the tombstone period after deletion
+	Deleted bool
+}
+
+// NewResource wraps an object as a Resource without copying it
+func NewResource(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) *Resource {
+	var creationTime time.Time
+	if ct := obj.GetCreationTimestamp(); !ct.IsZero() {
+		creationTime = ct.Time
+	}
+	return &Resource{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		GVR:               gvr,
+		Object:            obj,
+		CreationTimestamp: creationTime,
+	}
 }
 
 // ResourceKey uniquely identifies a resource
@@ -32,16 +51,189 @@ type Store struct {
 	resources map[string]map[schema.GroupVersionResource]map[string]map[string]*Resource
 	// Track which contexts/resources are being watched
 	watching map[string]map[schema.GroupVersionResource]bool
+	// versions records, per context and GVR, the sequence number of the last mutation
+	versions map[versionKey]uint64
+	seq      uint64
+	// updated records, per context and GVR, the time of the last mutation
+	updated map[versionKey]time.Time
+	// Deleted resources are kept for tombstoneTTL so Get still finds a resource that
+	// was just deleted (e.g. one picked from a completion list). They are never listed.
+	tombstones   map[ResourceKey]tombstone
+	tombstoneTTL time.Duration
+	// lastSweep is when expired tombstones were last removed; sweeps run at most once
+	// per tombstoneTTL so deletes don't walk every tombstone
+	lastSweep time.Time
+	// deletions records the last resourceVersion of resources deleted within
+	// deletionMemory, so a re-list taken before a deletion doesn't bring them back
+	deletions         map[ResourceKey]deletion
+	lastDeletionSweep time.Time
+	// subscribers receive the changes of a context and GVR (see Subscribe)
+	subscribers map[versionKey]map[*subscriber]struct{}
+}
+
+// EventType is the kind of change an Event reports
+type EventType string
+
+const (
+	EventAdded    EventType = "added"
+	EventModified EventType = "modified"
+	EventDeleted  EventType = "deleted"
+	// EventStopped is the last event of a subscription whose resources stopped being
+	// watched or were cleared; the channel is closed after it
+	EventStopped EventType = "stopped"
+)
+
+// Event is a change to a stored resource, delivered to subscribers
+type Event struct {
+	Type     EventType
+	Resource *Resource
+}
+
+// subscriber is a channel subscribed to the changes of a context and GVR
+type subscriber struct {
+	ch chan Event
+}
+
+// tombstone is a deleted resource and when it was deleted
+type tombstone struct {
+	resource  *Resource
+	deletedAt time.Time
+}
+
+// deletion is the last resourceVersion of a deleted resource and when it was deleted
+type deletion struct {
+	resourceVersion string
+	deletedAt       time.Time
+}
+
+// deletionMemory is how long deletions are remembered for re-lists that were in flight
+// when they happened. It only needs to outlast a list request.
+const deletionMemory = 10 * time.Minute
+
+type versionKey struct {
+	context string
+	gvr     schema.GroupVersionResource
 }
 
 // NewStore creates a new resource store
 func NewStore() *Store {
 	return &Store{
-		resources: make(map[string]map[schema.GroupVersionResource]map[string]map[string]*Resource),
-		watching:  make(map[string]map[schema.GroupVersionResource]bool),
+		resources:   make(map[string]map[schema.GroupVersionResource]map[string]map[string]*Resource),
+		watching:    make(map[string]map[schema.GroupVersionResource]bool),
+		versions:    make(map[versionKey]uint64),
+		updated:     make(map[versionKey]time.Time),
+		tombstones:  make(map[ResourceKey]tombstone),
+		deletions:   make(map[ResourceKey]deletion),
+		subscribers: make(map[versionKey]map[*subscriber]struct{}),
 	}
 }
 
+// SetTombstoneTTL sets how long Get keeps returning deleted resources (0 disables tombstones)
+func (s *Store) SetTombstoneTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tombstoneTTL = ttl
+	if ttl == 0 {
+		clear(s.tombstones)
+	}
+}
+
+// bump records a mutation of a context and GVR. Must be called with the write lock held.
+func (s *Store) bump(context string, gvr schema.GroupVersionResource) {
+	s.seq++
+	key := versionKey{context: context, gvr: gvr}
+	s.versions[key] = s.seq
+	s.updated[key] = time.Now()
+}
+
+// Subscribe returns a channel receiving the changes to resources of a context and GVR,
+// with room for buffer pending events, and a function ending the subscription. Events
+// are never blocked on: when a subscriber falls behind and its buffer fills up, its
+// channel is closed, so the consumer should list again and resubscribe. When the resources
+// stop being watched or are cleared, an EventStopped is sent (if there is room) before the
+// channel is closed.
+func (s *Store) Subscribe(context string, gvr schema.GroupVersionResource, buffer int) (<-chan Event, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := versionKey{context: context, gvr: gvr}
+	sub := &subscriber{ch: make(chan Event, buffer)}
+	if s.subscribers[key] == nil {
+		s.subscribers[key] = make(map[*subscriber]struct{})
+	}
+	s.subscribers[key][sub] = struct{}{}
+
+	return sub.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.unsubscribe(key, sub)
+	}
+}
+
+// unsubscribe removes a subscriber and closes its channel, unless it was already
+// removed. Must be called with the write lock held.
+func (s *Store) unsubscribe(key versionKey, sub *subscriber) {
+	if _, ok := s.subscribers[key][sub]; !ok {
+		return
+	}
+	delete(s.subscribers[key], sub)
+	if len(s.subscribers[key]) == 0 {
+		delete(s.subscribers, key)
+	}
+	close(sub.ch)
+}
+
+// publish delivers an event to the subscribers of a context and GVR, dropping those
+// whose buffer is full. Must be called with the write lock held.
+func (s *Store) publish(context string, gvr schema.GroupVersionResource, eventType EventType, res *Resource) {
+	key := versionKey{context: context, gvr: gvr}
+	for sub := range s.subscribers[key] {
+		select {
+		case sub.ch <- Event{Type: eventType, Resource: res}:
+		default:
+			s.unsubscribe(key, sub)
+		}
+	}
+}
+
+// stopSubscribers ends the subscriptions to a context and GVR with an EventStopped.
+// Must be called with the write lock held.
+func (s *Store) stopSubscribers(context string, gvr schema.GroupVersionResource) {
+	key := versionKey{context: context, gvr: gvr}
+	for sub := range s.subscribers[key] {
+		select {
+		case sub.ch <- Event{Type: EventStopped}:
+		default:
+		}
+		s.unsubscribe(key, sub)
+	}
+}
+
+// hasSubscribers reports whether changes to a context and GVR are being published.
+// Must be called with the lock held.
+func (s *Store) hasSubscribers(context string, gvr schema.GroupVersionResource) bool {
+	return len(s.subscribers[versionKey{context: context, gvr: gvr}]) > 0
+}
+
+// Version returns a number that changes whenever resources of a context and GVR change,
+// so callers can cache results derived from them. Versions are never reused.
+func (s *Store) Version(context string, gvr schema.GroupVersionResource) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.versions[versionKey{context: context, gvr: gvr}]
+}
+
+// LastUpdated returns when resources of a context and GVR last changed (added, modified,
+// deleted or re-listed), or the zero time if they never did
+func (s *Store) LastUpdated(context string, gvr schema.GroupVersionResource) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.updated[versionKey{context: context, gvr: gvr}]
+}
+
 // Add adds or updates a resource in the store
 // Note: The object is stored directly without deep copy for memory efficiency.
 // Callers should not modify the object after calling Add.
@@ -65,20 +257,129 @@ func (s *Store) Add(context string, gvr schema.GroupVersionResource, obj *unstru
 		s.resources[context][gvr][namespace] = make(map[string]*Resource)
 	}
 
-	var creationTime time.Time
-	if ct := obj.GetCreationTimestamp(); !ct.IsZero() {
-		creationTime = ct.Time
-	}
-
 	// Store the object directly without deep copy for memory efficiency.
 	// The watch API provides new object instances for each event, so this is safe.
-	s.resources[context][gvr][namespace][obj.GetName()] = &Resource{
-		Name:              obj.GetName(),
-		Namespace:         obj.GetNamespace(),
-		GVR:               gvr,
-		Object:            obj,
-		CreationTimestamp: creationTime,
+	key := ResourceKey{Context: context, GVR: gvr, Namespace: namespace, Name: obj.GetName()}
+	delete(s.tombstones, key)
+	delete(s.deletions, key)
+	eventType := EventAdded
+	if _, ok := s.resources[context][gvr][namespace][obj.GetName()]; ok {
+		eventType = EventModified
+	}
+	res := NewResource(gvr, obj)
+	s.resources[context][gvr][namespace][obj.GetName()] = res
+	s.bump(context, gvr)
+	s.publish(context, gvr, eventType, res)
+}
+
+// AddIfChanged adds a resource unless the stored copy is identical apart from its
+// resourceVersion, and reports whether it was changed. High-churn resources get many
+// no-op modifications; skipping them avoids invalidating results cached against the
+// store version and notifying subscribers. The stored copy still takes the new
+// resourceVersion, so a later re-list recognizes it as current.
+func (s *Store) AddIfChanged(context string, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) bool {
+	existing := s.Get(context, gvr, obj.GetNamespace(), obj.GetName())
+	if existing == nil || existing.Deleted || existing.Object == nil || !sameObject(existing.Object.Object, obj.Object) {
+		s.Add(context, gvr, obj)
+		return true
+	}
+	if existing.Object.GetResourceVersion() == obj.GetResourceVersion() {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = "_cluster"
+	}
+	// Stored objects are shared with readers, so the new object replaces the old one
+	// rather than updating it. A concurrent change wins over this one.
+	if nsResources := s.resources[context][gvr][namespace]; nsResources[obj.GetName()] == existing {
+		nsResources[obj.GetName()] = NewResource(gvr, obj)
+	}
+	return false
+}
+
+// sameObject compares two objects, ignoring metadata.resourceVersion
+func sameObject(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
 	}
+	for key, av := range a {
+		bv, ok := b[key]
+		if !ok {
+			return false
+		}
+		if key == "metadata" {
+			am, aok := av.(map[string]interface{})
+			bm, bok := bv.(map[string]interface{})
+			if aok && bok {
+				if !equalMaps(am, bm, "resourceVersion") {
+					return false
+				}
+				continue
+			}
+		}
+		if !equalValues(av, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalMaps compares two JSON-like maps, skipping the key named ignore
+func equalMaps(a, b map[string]interface{}, ignore string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, av := range a {
+		if ignore != "" && key == ignore {
+			continue
+		}
+		bv, ok := b[key]
+		if !ok || !equalValues(av, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues compares JSON-like values without reflection for the types
+// unstructured objects are made of
+func equalValues(a, b interface{}) bool {
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case int64:
+		bv, ok := b.(int64)
+		return ok && av == bv
+	case float64:
+		bv, ok := b.(float64)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case nil:
+		return b == nil
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		return ok && equalMaps(av, bv, "")
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equalValues(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
 }
 
 // Delete removes a resource from the store
@@ -100,7 +401,65 @@ func (s *Store) Delete(context string, gvr schema.GroupVersionResource, namespac
 		return
 	}
 
+	res, ok := s.resources[context][gvr][namespace][name]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	key := ResourceKey{Context: context, GVR: gvr, Namespace: namespace, Name: name}
+	if s.tombstoneTTL > 0 {
+		s.sweepTombstones(now)
+		deleted := *res
+		deleted.Deleted = true
+		s.tombstones[key] = tombstone{resource: &deleted, deletedAt: now}
+	}
+	if res.Object != nil && res.Object.GetResourceVersion() != "" {
+		s.sweepDeletions(now)
+		s.deletions[key] = deletion{resourceVersion: res.Object.GetResourceVersion(), deletedAt: now}
+	}
+
 	delete(s.resources[context][gvr][namespace], name)
+	s.bump(context, gvr)
+	s.publish(context, gvr, EventDeleted, res)
+}
+
+// sweepTombstones removes expired tombstones, unless that was done less than
+// tombstoneTTL ago. Must be called with the write lock held.
+func (s *Store) sweepTombstones(now time.Time) {
+	if now.Sub(s.lastSweep) < s.tombstoneTTL {
+		return
+	}
+	s.lastSweep = now
+	for key, t := range s.tombstones {
+		if now.Sub(t.deletedAt) >= s.tombstoneTTL {
+			delete(s.tombstones, key)
+		}
+	}
+}
+
+// sweepDeletions forgets deletions older than deletionMemory, at most once per
+// deletionMemory. Must be called with the write lock held.
+func (s *Store) sweepDeletions(now time.Time) {
+	if now.Sub(s.lastDeletionSweep) < deletionMemory {
+		return
+	}
+	s.lastDeletionSweep = now
+	for key, d := range s.deletions {
+		if now.Sub(d.deletedAt) >= deletionMemory {
+			delete(s.deletions, key)
+		}
+	}
+}
+
+// newerVersion reports whether resourceVersion a is newer than b. resourceVersions are
+// opaque, but in practice numeric; anything else never counts as newer.
+func newerVersion(a, b string) bool {
+	av, err := strconv.ParseUint(a, 10, 64)
+	if err != nil {
+		return false
+	}
+	bv, err := strconv.ParseUint(b, 10, 64)
+	return err == nil && av > bv
 }
 
 // List returns all resources matching the criteria
@@ -201,13 +560,167 @@ func (s *Store) Get(context string, gvr schema.GroupVersionResource, namespace,
 		nsKey = "_cluster"
 	}
 
-	if s.resources[context] == nil ||
-		s.resources[context][gvr] == nil ||
-		s.resources[context][gvr][nsKey] == nil {
-		return nil
+	if res := s.resources[context][gvr][nsKey][name]; res != nil {
+		return res
+	}
+
+	key := ResourceKey{Context: context, GVR: gvr, Namespace: nsKey, Name: name}
+	if t, ok := s.tombstones[key]; ok && time.Since(t.deletedAt) < s.tombstoneTTL {
+		return t.resource
+	}
+	return nil
+}
+
+// Replace atomically replaces all resources for a context and GVR with objs,
+// so readers never observe an empty list while a re-list is applied.
+// prune (optional) is applied to each new object; objects already stored at the same
+// resourceVersion reuse the stored, already-pruned copy instead, so a re-list after a
+// watch reconnect doesn't re-walk every unchanged object. The version is only bumped,
+// and subscribers only notified, for objects that were added, removed or changed in
+// content; a re-list that only moved resourceVersions keeps cached results valid.
+// resourceVersion is the list's resourceVersion when a watch of the resources keeps
+// running alongside it: changes stored after the list then win, so objects the watch
+// added, modified or deleted since aren't reverted. An empty resourceVersion replaces
+// unconditionally.
+func (s *Store) Replace(context string, gvr schema.GroupVersionResource, objs []unstructured.Unstructured, resourceVersion string, prune func(*unstructured.Unstructured)) {
+	// Look up the stored objects first, so the read lock isn't held while pruning
+	previous := make([]*Resource, len(objs))
+	s.mu.RLock()
+	if current := s.resources[context][gvr]; len(current) > 0 {
+		for i := range objs {
+			obj := &objs[i]
+			namespace := obj.GetNamespace()
+			if namespace == "" {
+				namespace = "_cluster"
+			}
+			if old := current[namespace][obj.GetName()]; old != nil && old.Object != nil {
+				previous[i] = old
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	byNamespace := make(map[string]map[string]*Resource)
+	// unchanged maps the resources that are the same as a stored one to it
+	unchanged := make(map[*Resource]*Resource)
+	for i := range objs {
+		obj := &objs[i]
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = "_cluster"
+		}
+		if byNamespace[namespace] == nil {
+			byNamespace[namespace] = make(map[string]*Resource)
+		}
+
+		old := previous[i]
+		if old != nil {
+			if rv := obj.GetResourceVersion(); rv != "" && rv == old.Object.GetResourceVersion() {
+				byNamespace[namespace][obj.GetName()] = old
+				unchanged[old] = old
+				continue
+			}
+		}
+		if prune != nil {
+			prune(obj)
+		}
+
+		res := NewResource(gvr, obj)
+		byNamespace[namespace][obj.GetName()] = res
+		if old != nil && sameObject(old.Object.Object, obj.Object) {
+			unchanged[res] = old
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.resources[context] == nil {
+		s.resources[context] = make(map[schema.GroupVersionResource]map[string]map[string]*Resource)
+	}
+	old := s.resources[context][gvr]
+	if resourceVersion != "" {
+		s.keepNewer(context, gvr, old, byNamespace, unchanged, resourceVersion)
+	}
+	s.resources[context][gvr] = byNamespace
+
+	// Compare against what is stored now rather than what was looked up, so a change
+	// made in between still counts. Events are only collected for subscribers.
+	type change struct {
+		eventType EventType
+		res       *Resource
+	}
+	var changes []change
+	changed := false
+	subscribed := s.hasSubscribers(context, gvr)
+	record := func(eventType EventType, res *Resource) {
+		changed = true
+		if subscribed {
+			changes = append(changes, change{eventType, res})
+		}
+	}
+	for namespace, nsResources := range old {
+		for name, res := range nsResources {
+			if _, ok := byNamespace[namespace][name]; !ok {
+				record(EventDeleted, res)
+			}
+		}
+	}
+	for namespace, nsResources := range byNamespace {
+		for name, res := range nsResources {
+			switch stored, ok := old[namespace][name]; {
+			case !ok:
+				record(EventAdded, res)
+			case unchanged[res] != stored:
+				record(EventModified, res)
+			}
+		}
 	}
 
-	return s.resources[context][gvr][nsKey][name]
+	if !changed {
+		// Nothing changed, but the resources were re-listed just now
+		s.updated[versionKey{context: context, gvr: gvr}] = time.Now()
+		return
+	}
+	s.bump(context, gvr)
+	for _, c := range changes {
+		s.publish(context, gvr, c.eventType, c.res)
+	}
+}
+
+// keepNewer applies the changes stored after a list at resourceVersion to the listed
+// byNamespace: objects deleted after the list are dropped, and objects added or
+// modified after it keep their stored copy. Must be called with the write lock held.
+func (s *Store) keepNewer(context string, gvr schema.GroupVersionResource, old, byNamespace map[string]map[string]*Resource, unchanged map[*Resource]*Resource, resourceVersion string) {
+	for namespace, nsResources := range byNamespace {
+		for name, res := range nsResources {
+			listed := res.Object.GetResourceVersion()
+			// A recreated object has a newer resourceVersion than the deleted one
+			key := ResourceKey{Context: context, GVR: gvr, Namespace: namespace, Name: name}
+			if d, ok := s.deletions[key]; ok && !newerVersion(listed, d.resourceVersion) {
+				delete(nsResources, name)
+				continue
+			}
+			if stored := old[namespace][name]; stored != nil && stored.Object != nil && newerVersion(stored.Object.GetResourceVersion(), listed) {
+				nsResources[name] = stored
+				unchanged[stored] = stored
+			}
+		}
+	}
+	for namespace, nsResources := range old {
+		for name, stored := range nsResources {
+			if _, ok := byNamespace[namespace][name]; ok || stored.Object == nil {
+				continue
+			}
+			if newerVersion(stored.Object.GetResourceVersion(), resourceVersion) {
+				if byNamespace[namespace] == nil {
+					byNamespace[namespace] = make(map[string]*Resource)
+				}
+				byNamespace[namespace][name] = stored
+				unchanged[stored] = stored
+			}
+		}
+	}
 }
 
 // Clear removes all resources for a context and GVR
@@ -215,9 +728,21 @@ func (s *Store) Clear(context string, gvr schema.GroupVersionResource) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.stopSubscribers(context, gvr)
 	if s.resources[context] != nil {
 		delete(s.resources[context], gvr)
 	}
+	for key := range s.tombstones {
+		if key.Context == context && key.GVR == gvr {
+			delete(s.tombstones, key)
+		}
+	}
+	for key := range s.deletions {
+		if key.Context == context && key.GVR == gvr {
+			delete(s.deletions, key)
+		}
+	}
+	s.bump(context, gvr)
 }
 
 // ClearContext removes all resources for a context
@@ -225,6 +750,29 @@ func (s *Store) ClearContext(context string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Dropping the versions resets them to 0, which only matches results cached
+	// before anything was stored, and those are still empty
+	for key := range s.versions {
+		if key.context == context {
+			delete(s.versions, key)
+			delete(s.updated, key)
+		}
+	}
+	for key := range s.tombstones {
+		if key.Context == context {
+			delete(s.tombstones, key)
+		}
+	}
+	for key := range s.deletions {
+		if key.Context == context {
+			delete(s.deletions, key)
+		}
+	}
+	for key := range s.subscribers {
+		if key.context == context {
+			s.stopSubscribers(context, key.gvr)
+		}
+	}
 	delete(s.resources, context)
 	delete(s.watching, context)
 }
@@ -238,6 +786,9 @@ func (s *Store) SetWatching(context string, gvr schema.GroupVersionResource, wat
 		s.watching[context] = make(map[schema.GroupVersionResource]bool)
 	}
 	s.watching[context][gvr] = watching
+	if !watching {
+		s.stopSubscribers(context, gvr)
+	}
 }
 
 // IsWatching returns whether a resource type is being watched
@@ -287,3 +838,15 @@ func (s *Store) Count() int {
 	}
 	return count
 }
+
+// CountOf returns the number of resources of a context and GVR, without listing them
+func (s *Store) CountOf(context string, gvr schema.GroupVersionResource) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, resources := range s.resources[context][gvr] {
+		count += len(resources)
+	}
+	return count
+}
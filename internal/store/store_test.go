@@ -143,3 +143,620 @@ func TestStore_WatchingStatus(t *testing.T) {
 		t.Error("expected not watching after SetWatching(false)")
 	}
 }
+
+func TestStore_Replace(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	newPod := func(name string) unstructured.Unstructured {
+		return unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+				},
+			},
+		}
+	}
+
+	stale := newPod("stale")
+	kept := newPod("kept")
+	s.Add(context, gvr, &stale)
+	s.Add(context, gvr, &kept)
+
+	s.Replace(context, gvr, []unstructured.Unstructured{newPod("kept"), newPod("added")}, "", nil)
+
+	if s.Get(context, gvr, "default", "stale") != nil {
+		t.Error("stale resource should be removed by Replace")
+	}
+	if s.Get(context, gvr, "default", "kept") == nil {
+		t.Error("kept resource should still exist")
+	}
+	if s.Get(context, gvr, "default", "added") == nil {
+		t.Error("added resource should exist")
+	}
+	if got := len(s.List(context, gvr, "")); got != 2 {
+		t.Errorf("List returned %d resources, want 2", got)
+	}
+}
+
+func TestStore_Version(t *testing.T) {
+	s := NewStore()
+	pods := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	services := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}
+	context := "test-context"
+
+	if v := s.Version(context, pods); v != 0 {
+		t.Errorf("Version of untouched GVR = %d, want 0", v)
+	}
+
+	pod := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+		},
+	}
+
+	seen := map[uint64]bool{0: true}
+	mutations := []func(){
+		func() { s.Add(context, pods, pod) },
+		func() { s.Delete(context, pods, "default", "web") },
+		func() { s.Replace(context, pods, []unstructured.Unstructured{*pod}, "", nil) },
+		func() { s.Clear(context, pods) },
+	}
+	for i, mutate := range mutations {
+		servicesBefore := s.Version(context, services)
+		mutate()
+		v := s.Version(context, pods)
+		if seen[v] {
+			t.Errorf("mutation %d reused version %d", i, v)
+		}
+		seen[v] = true
+		if s.Version(context, services) != servicesBefore {
+			t.Errorf("mutation %d changed the version of another GVR", i)
+		}
+	}
+
+	s.Add(context, pods, pod)
+	before := s.Version(context, pods)
+	s.ClearContext(context)
+	if s.Version(context, pods) == before {
+		t.Error("ClearContext should change the version")
+	}
+}
+
+func TestStore_AddIfChanged(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	newPod := func(resourceVersion, phase string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":            "web",
+					"namespace":       "default",
+					"resourceVersion": resourceVersion,
+				},
+				"status": map[string]interface{}{"phase": phase},
+			},
+		}
+	}
+
+	if !s.AddIfChanged(context, gvr, newPod("1", "Pending")) {
+		t.Error("new object should be written")
+	}
+	version := s.Version(context, gvr)
+
+	if s.AddIfChanged(context, gvr, newPod("2", "Pending")) {
+		t.Error("object differing only in resourceVersion should be skipped")
+	}
+	if s.Version(context, gvr) != version {
+		t.Error("skipped write should not change the store version")
+	}
+	if got := s.Get(context, gvr, "default", "web").Object.GetResourceVersion(); got != "2" {
+		t.Errorf("skipped write kept resourceVersion %q, want 2", got)
+	}
+
+	if !s.AddIfChanged(context, gvr, newPod("3", "Running")) {
+		t.Error("changed status should be written")
+	}
+	if got := s.Get(context, gvr, "default", "web"); got.Object.Object["status"].(map[string]interface{})["phase"] != "Running" {
+		t.Error("store should hold the updated object")
+	}
+
+	labeled := newPod("4", "Running")
+	labeled.Object["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"app": "web"}
+	if !s.AddIfChanged(context, gvr, labeled) {
+		t.Error("added metadata should be written")
+	}
+}
+
+// BenchmarkStore_Modify feeds synthetic modify events that only bump the resourceVersion,
+// as status heartbeats on busy clusters do. AddIfChanged stores them without a version
+// bump or notifying subscribers.
+func BenchmarkStore_Modify(b *testing.B) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	events := make([]*unstructured.Unstructured, 1000)
+	for i := range events {
+		events[i] = &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":            fmt.Sprintf("pod-%d", i%100),
+					"namespace":       "default",
+					"resourceVersion": fmt.Sprintf("%d", i),
+				},
+				"spec":   map[string]interface{}{"nodeName": "worker-1"},
+				"status": map[string]interface{}{"phase": "Running", "podIP": "10.0.0.1"},
+			},
+		}
+	}
+
+	b.Run("Add", func(b *testing.B) {
+		s := NewStore()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.Add("ctx", gvr, events[i%len(events)])
+		}
+	})
+
+	b.Run("AddIfChanged", func(b *testing.B) {
+		s := NewStore()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.AddIfChanged("ctx", gvr, events[i%len(events)])
+		}
+	})
+}
+
+func TestStore_ReplaceReusesUnchanged(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	newPod := func(name, resourceVersion string) unstructured.Unstructured {
+		return unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":            name,
+					"namespace":       "default",
+					"resourceVersion": resourceVersion,
+				},
+				"data": "large",
+			},
+		}
+	}
+
+	pruned := 0
+	prune := func(obj *unstructured.Unstructured) {
+		pruned++
+		delete(obj.Object, "data")
+	}
+
+	s.Replace(context, gvr, []unstructured.Unstructured{newPod("a", "1"), newPod("b", "1")}, "", prune)
+	if pruned != 2 {
+		t.Fatalf("initial list pruned %d objects, want 2", pruned)
+	}
+	storedA := s.Get(context, gvr, "default", "a")
+
+	pruned = 0
+	s.Replace(context, gvr, []unstructured.Unstructured{newPod("a", "1"), newPod("b", "2"), newPod("c", "1")}, "", prune)
+	if pruned != 2 {
+		t.Errorf("re-list pruned %d objects, want 2 (changed b and new c)", pruned)
+	}
+	if s.Get(context, gvr, "default", "a") != storedA {
+		t.Error("unchanged object should keep its stored copy")
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		res := s.Get(context, gvr, "default", name)
+		if res == nil {
+			t.Fatalf("%s missing after re-list", name)
+		}
+		if _, ok := res.Object.Object["data"]; ok {
+			t.Errorf("%s was stored unpruned", name)
+		}
+	}
+}
+
+// BenchmarkStore_Relist re-lists 5000 pods after a watch reconnect. Unchanged objects
+// reuse their already-pruned copy; changed ones are pruned again.
+func BenchmarkStore_Relist(b *testing.B) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	newList := func(resourceVersion string) []unstructured.Unstructured {
+		items := make([]unstructured.Unstructured, 5000)
+		for i := range items {
+			items[i] = unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":            fmt.Sprintf("pod-%d", i),
+						"namespace":       "default",
+						"resourceVersion": resourceVersion,
+						"managedFields":   []interface{}{},
+					},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": "nginx", "env": []interface{}{}, "resources": map[string]interface{}{}},
+						},
+						"volumes": []interface{}{},
+					},
+				},
+			}
+		}
+		return items
+	}
+	prune := func(obj *unstructured.Unstructured) {
+		delete(obj.Object["metadata"].(map[string]interface{}), "managedFields")
+		spec := obj.Object["spec"].(map[string]interface{})
+		delete(spec, "volumes")
+		for _, c := range spec["containers"].([]interface{}) {
+			container := c.(map[string]interface{})
+			delete(container, "env")
+			delete(container, "resources")
+		}
+	}
+
+	for _, changed := range []bool{false, true} {
+		name := "Unchanged"
+		if changed {
+			name = "Changed"
+		}
+		b.Run(name, func(b *testing.B) {
+			lists := make([][]unstructured.Unstructured, b.N)
+			for i := range lists {
+				rv := "1"
+				if changed {
+					rv = fmt.Sprintf("%d", i+2)
+				}
+				lists[i] = newList(rv)
+			}
+			s := NewStore()
+			s.Replace("ctx", gvr, newList("1"), "", prune)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Replace("ctx", gvr, lists[i], "", prune)
+			}
+		})
+	}
+}
+
+func TestStore_Tombstones(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	pod := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "web",
+				"namespace": "default",
+			},
+		},
+	}
+
+	// Disabled by default
+	s.Add(context, gvr, pod)
+	s.Delete(context, gvr, "default", "web")
+	if s.Get(context, gvr, "default", "web") != nil {
+		t.Error("deleted resource should be gone without tombstones")
+	}
+
+	s.SetTombstoneTTL(time.Hour)
+	s.Add(context, gvr, pod)
+	s.Delete(context, gvr, "default", "web")
+	if len(s.List(context, gvr, "")) != 0 {
+		t.Error("tombstoned resource should not be listed")
+	}
+	res := s.Get(context, gvr, "default", "web")
+	if res == nil || !res.Deleted {
+		t.Fatalf("Get() = %+v, want tombstoned resource", res)
+	}
+
+	// The object reappearing replaces the tombstone, even if unchanged
+	if !s.AddIfChanged(context, gvr, pod) {
+		t.Error("re-added object should be written")
+	}
+	if res := s.Get(context, gvr, "default", "web"); res == nil || res.Deleted {
+		t.Errorf("Get() = %+v, want live resource", res)
+	}
+
+	s.Delete(context, gvr, "default", "web")
+	s.ClearContext(context)
+	if s.Get(context, gvr, "default", "web") != nil {
+		t.Error("ClearContext should drop tombstones")
+	}
+}
+
+func TestStore_LastUpdated(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	if !s.LastUpdated(context, gvr).IsZero() {
+		t.Error("LastUpdated should be zero before any change")
+	}
+
+	pod := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+		},
+	}
+	before := time.Now()
+	s.Add(context, gvr, pod)
+	added := s.LastUpdated(context, gvr)
+	if added.Before(before) {
+		t.Errorf("LastUpdated = %v, want at or after %v", added, before)
+	}
+
+	s.Delete(context, gvr, "default", "web")
+	if s.LastUpdated(context, gvr).Before(added) {
+		t.Error("Delete should advance LastUpdated")
+	}
+
+	s.ClearContext(context)
+	if !s.LastUpdated(context, gvr).IsZero() {
+		t.Error("ClearContext should reset LastUpdated")
+	}
+}
+
+func TestStore_Subscribe(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	newPod := func(name, rv string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name, "namespace": "default", "resourceVersion": rv},
+			},
+		}
+	}
+
+	events, unsubscribe := s.Subscribe(context, gvr, 10)
+	s.Add(context, gvr, newPod("web", "1"))
+	s.Add(context, gvr, newPod("web", "2"))
+	s.Add(context, gvr, newPod("db", "1"))
+	s.Delete(context, gvr, "default", "db")
+	s.Delete(context, gvr, "default", "missing")
+	s.Add("other-context", gvr, newPod("web", "1"))
+	s.Replace(context, gvr, []unstructured.Unstructured{*newPod("web", "2"), *newPod("api", "1")}, "", nil)
+
+	// Replace reports only differences: web keeps its resourceVersion, so it is unchanged
+	want := []string{"added web", "modified web", "added db", "deleted db", "added api"}
+	for _, w := range want {
+		select {
+		case event := <-events:
+			if got := string(event.Type) + " " + event.Resource.Name; got != w {
+				t.Errorf("event = %q, want %q", got, w)
+			}
+		default:
+			t.Fatalf("missing event %q", w)
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %s %s", event.Type, event.Resource.Name)
+	default:
+	}
+
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("channel should be closed after unsubscribing")
+	}
+	unsubscribe() // must not close twice
+
+	// A subscriber that falls behind is dropped rather than blocking the store
+	events, unsubscribe = s.Subscribe(context, gvr, 1)
+	defer unsubscribe()
+	s.Add(context, gvr, newPod("a", "1"))
+	s.Add(context, gvr, newPod("b", "1"))
+	<-events
+	if _, ok := <-events; ok {
+		t.Error("channel should be closed after its buffer overflowed")
+	}
+
+	// Subscriptions end with a stopped event when the watch stops or its data is cleared
+	stops := map[string]func(){
+		"SetWatching":  func() { s.SetWatching(context, gvr, false) },
+		"Clear":        func() { s.Clear(context, gvr) },
+		"ClearContext": func() { s.ClearContext(context) },
+	}
+	for name, stop := range stops {
+		events, unsubscribe := s.Subscribe(context, gvr, 10)
+		stop()
+		if event, ok := <-events; !ok || event.Type != EventStopped {
+			t.Errorf("%s: event = %v (open %v), want %s", name, event.Type, ok, EventStopped)
+		}
+		if _, ok := <-events; ok {
+			t.Errorf("%s: channel should be closed after the stopped event", name)
+		}
+		unsubscribe()
+	}
+}
+
+func TestStore_ReplaceOnlyBumpsOnChange(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	newPod := func(name, resourceVersion, phase string) unstructured.Unstructured {
+		return unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":            name,
+					"namespace":       "default",
+					"resourceVersion": resourceVersion,
+				},
+				"status": map[string]interface{}{"phase": phase},
+			},
+		}
+	}
+
+	s.Replace(context, gvr, []unstructured.Unstructured{newPod("a", "1", "Running"), newPod("b", "1", "Running")}, "", nil)
+	version := s.Version(context, gvr)
+	events, unsubscribe := s.Subscribe(context, gvr, 10)
+	defer unsubscribe()
+
+	// Only the resourceVersion moved: the new copy is stored, but nothing is reported
+	s.Replace(context, gvr, []unstructured.Unstructured{newPod("a", "2", "Running"), newPod("b", "1", "Running")}, "", nil)
+	if s.Version(context, gvr) != version {
+		t.Error("re-list without content changes should not change the store version")
+	}
+	if got := s.Get(context, gvr, "default", "a").Object.GetResourceVersion(); got != "2" {
+		t.Errorf("a has resourceVersion %q, want 2", got)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected %s event for %s", event.Type, event.Resource.Name)
+	default:
+	}
+
+	// A changed and a removed object are reported
+	s.Replace(context, gvr, []unstructured.Unstructured{newPod("a", "3", "Failed")}, "", nil)
+	if s.Version(context, gvr) == version {
+		t.Error("re-list with changes should change the store version")
+	}
+	got := map[string]EventType{}
+	for len(got) < 2 {
+		select {
+		case event := <-events:
+			got[event.Resource.Name] = event.Type
+		case <-time.After(time.Second):
+			t.Fatalf("events = %v, want a modified and b deleted", got)
+		}
+	}
+	if got["a"] != EventModified || got["b"] != EventDeleted {
+		t.Errorf("events = %v, want a modified and b deleted", got)
+	}
+}
+
+func TestStore_DeleteMissing(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	s.Add(context, gvr, &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+	}})
+	version := s.Version(context, gvr)
+	s.Delete(context, gvr, "default", "api")
+	if s.Version(context, gvr) != version {
+		t.Error("deleting a missing resource should not change the store version")
+	}
+}
+
+func TestStore_SweepTombstones(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+	s.SetTombstoneTTL(time.Minute)
+
+	now := time.Now()
+	expired := ResourceKey{Context: context, GVR: gvr, Namespace: "default", Name: "old"}
+	s.tombstones[expired] = tombstone{resource: &Resource{Name: "old"}, deletedAt: now.Add(-2 * time.Minute)}
+
+	// A sweep less than a TTL after the previous one is skipped
+	s.lastSweep = now.Add(-30 * time.Second)
+	s.sweepTombstones(now)
+	if _, ok := s.tombstones[expired]; !ok {
+		t.Error("tombstones should not be swept again within the TTL")
+	}
+
+	s.lastSweep = now.Add(-time.Minute)
+	s.sweepTombstones(now)
+	if _, ok := s.tombstones[expired]; ok {
+		t.Error("expired tombstone should be swept")
+	}
+}
+
+func TestStore_ReplaceKeepsNewerChanges(t *testing.T) {
+	s := NewStore()
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	context := "test-context"
+
+	newPod := func(name, resourceVersion, phase string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":            name,
+					"namespace":       "default",
+					"resourceVersion": resourceVersion,
+				},
+				"status": map[string]interface{}{"phase": phase},
+			},
+		}
+	}
+	phase := func(name string) string {
+		res := s.Get(context, gvr, "default", name)
+		if res == nil {
+			return ""
+		}
+		return res.Object.Object["status"].(map[string]interface{})["phase"].(string)
+	}
+
+	for _, name := range []string{"deleted", "modified", "recreated", "gone"} {
+		s.Add(context, gvr, newPod(name, "10", "Running"))
+	}
+	// The watch delivers changes made after a resync listed at resourceVersion 20
+	s.Delete(context, gvr, "default", "deleted")
+	s.Delete(context, gvr, "default", "recreated")
+	s.Add(context, gvr, newPod("modified", "25", "Failed"))
+	s.Add(context, gvr, newPod("added", "30", "Pending"))
+
+	s.Replace(context, gvr, []unstructured.Unstructured{
+		*newPod("deleted", "10", "Running"),
+		*newPod("modified", "10", "Running"),
+		*newPod("recreated", "15", "Pending"),
+	}, "20", nil)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"deleted", ""},          // deleted after the list
+		{"modified", "Failed"},   // modified after the list
+		{"added", "Pending"},     // added after the list
+		{"recreated", "Pending"}, // listed with a newer resourceVersion than the deleted one
+		{"gone", ""},             // not listed and not changed since
+	}
+	for _, tt := range tests {
+		if got := phase(tt.name); got != tt.want {
+			t.Errorf("%s: phase = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Without a resourceVersion the list wins
+	s.Replace(context, gvr, []unstructured.Unstructured{*newPod("deleted", "10", "Running")}, "", nil)
+	if got := phase("deleted"); got != "Running" {
+		t.Errorf("unconditional replace: phase = %q, want Running", got)
+	}
+	if got := phase("added"); got != "" {
+		t.Errorf("unconditional replace kept an unlisted object")
+	}
+}
+
+func TestStore_CountOf(t *testing.T) {
+	s := NewStore()
+	pods := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	nodes := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}
+
+	for _, ns := range []string{"default", "default", "kube-system"} {
+		s.Add("test-context", pods, &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": fmt.Sprintf("pod-%d", s.CountOf("test-context", pods)), "namespace": ns},
+		}})
+	}
+	s.Add("test-context", nodes, &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "node-1"},
+	}})
+
+	if got := s.CountOf("test-context", pods); got != 3 {
+		t.Errorf("CountOf(pods) = %d, want 3", got)
+	}
+	if got := s.CountOf("test-context", nodes); got != 1 {
+		t.Errorf("CountOf(nodes) = %d, want 1", got)
+	}
+	if got := s.CountOf("other-context", pods); got != 0 {
+		t.Errorf("CountOf(other context) = %d, want 0", got)
+	}
+}